@@ -41,6 +41,31 @@ const (
 	ManualSubnetTaggingPolicy SubnetTaggingPolicy = "Manual"
 )
 
+// +kubebuilder:validation:Enum=Enabled;Disabled
+type MonitoringPolicy string
+
+const (
+	// MonitoringEnabled enables the monitoring resource.
+	MonitoringEnabled MonitoringPolicy = "Enabled"
+
+	// MonitoringDisabled disables the monitoring resource, the operator deletes it.
+	MonitoringDisabled MonitoringPolicy = "Disabled"
+)
+
+// +kubebuilder:validation:Enum=ServiceCA;CertManager;Self
+type WebhookCertificatesProvider string
+
+const (
+	// ServiceCAWebhookCertificatesProvider issues the webhook certificates with the OpenShift service CA.
+	ServiceCAWebhookCertificatesProvider WebhookCertificatesProvider = "ServiceCA"
+
+	// CertManagerWebhookCertificatesProvider issues the webhook certificates with cert-manager.
+	CertManagerWebhookCertificatesProvider WebhookCertificatesProvider = "CertManager"
+
+	// SelfWebhookCertificatesProvider issues the webhook certificates with a CA generated by the operator.
+	SelfWebhookCertificatesProvider WebhookCertificatesProvider = "Self"
+)
+
 // AWSLoadBalancerControllerSpec defines the desired state of AWSLoadBalancerController
 type AWSLoadBalancerControllerSpec struct {
 
@@ -88,16 +113,104 @@ type AWSLoadBalancerControllerSpec struct {
 	// +optional
 	EnabledAddons []AWSAddon `json:"enabledAddons,omitempty"` // indicates which AWS addons should be disabled.
 
+	// VPCID is the ID of the VPC where the load balancers are provisioned.
+	// It overrides the VPC discovered with the cluster tag, which is needed
+	// when more than one VPC has the tag of the cluster.
+	// Cannot be set together with VPCSelector.
+	//
+	// +kubebuilder:validation:Pattern=`^vpc-[0-9a-f]+$`
+	// +kubebuilder:validation:Optional
+	// +optional
+	VPCID string `json:"vpcID,omitempty"`
+
+	// VPCSelector selects the VPC where the load balancers are provisioned
+	// by its tags. It overrides the VPC discovered with the cluster tag.
+	// Exactly one VPC must match the selector.
+	// Cannot be set together with VPCID.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	VPCSelector *VPCSelector `json:"vpcSelector,omitempty"`
+
+	// Monitoring configures the monitoring of the controller by the
+	// cluster monitoring stack. The ServiceMonitor and the alerting
+	// rules are enabled by default.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	Monitoring *AWSLoadBalancerMonitoringConfig `json:"monitoring,omitempty"`
+
+	// WebhookCertificates configures the serving certificates of the
+	// webhooks of the controller.
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	WebhookCertificates *AWSLoadBalancerWebhookCertificatesConfig `json:"webhookCertificates,omitempty"`
+}
+
+// VPCSelector selects a VPC by its tags.
+type VPCSelector struct {
+	// MatchTags are the tags which the VPC must have.
+	// A tag with an empty value matches any value of the tag.
+	//
+	// +kubebuilder:validation:MinProperties=1
+	// +kubebuilder:validation:Required
+	// +required
+	MatchTags map[string]string `json:"matchTags"`
 }
 
 type AWSLoadBalancerDeploymentConfig struct {
 
-	// +kubebuilder:default:=2
+	// Replicas is the number of replicas of the controller.
+	// The default is given by the infrastructure topology of the cluster:
+	// 1 on single replica topologies, e.g. single node clusters, 2 otherwise.
+	//
+	// +kubebuilder:validation:Minimum=0
 	// +kubebuilder:validation:Optional
 	// +optional
 	Replicas int32 `json:"replicas,omitempty"`
 }
 
+// AWSLoadBalancerMonitoringConfig configures the monitoring resources of the controller.
+type AWSLoadBalancerMonitoringConfig struct {
+
+	// ServiceMonitor specifies whether the ServiceMonitor which scrapes
+	// the metrics of the controller is created. The metrics are served
+	// over TLS and require an authorized bearer token.
+	//
+	// +kubebuilder:default:=Enabled
+	// +kubebuilder:validation:Optional
+	// +optional
+	ServiceMonitor MonitoringPolicy `json:"serviceMonitor,omitempty"`
+
+	// AlertingRules specifies whether the PrometheusRule with the alerts
+	// on the reconcile errors, the AWS API errors, the webhook latency
+	// and the availability of the controller is created.
+	//
+	// +kubebuilder:default:=Enabled
+	// +kubebuilder:validation:Optional
+	// +optional
+	AlertingRules MonitoringPolicy `json:"alertingRules,omitempty"`
+}
+
+// AWSLoadBalancerWebhookCertificatesConfig configures the serving certificates of the webhooks.
+type AWSLoadBalancerWebhookCertificatesConfig struct {
+
+	// Provider specifies how the serving certificates of the webhooks are
+	// issued and how their CA bundle is injected into the webhook configurations.
+	//
+	// When "ServiceCA", the certificates are issued by the OpenShift service CA.
+	// When "CertManager", the certificates are issued by cert-manager, which
+	// must be installed on the cluster. When "Self", the operator generates a
+	// CA and the certificates, rotates them before they expire and sets the
+	// CA bundle of the webhook configurations.
+	//
+	// +kubebuilder:default:=ServiceCA
+	// +kubebuilder:validation:Optional
+	// +optional
+	Provider WebhookCertificatesProvider `json:"provider,omitempty"`
+}
+
 // AWSLoadBalancerControllerStatus defines the observed state of AWSLoadBalancerController.
 type AWSLoadBalancerControllerStatus struct {
 
@@ -108,7 +221,9 @@ type AWSLoadBalancerControllerStatus struct {
 	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
 
-	// ObservedGeneration is the most recent generation observed.
+	// ObservedGeneration is the most recent generation observed. It's the oldest
+	// generation observed by the conditions: all the controllers of the operator
+	// have processed at least this generation.
 	//
 	// +kubebuilder:validation:Optional
 	// +optional
@@ -125,6 +240,47 @@ type AWSLoadBalancerControllerStatus struct {
 	// +kubebuilder:validation:Optional
 	// +optional
 	IngressClass string `json:"ingressClass,omitempty"`
+
+	// Deployment contains the effective configuration of the controller deployment
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	Deployment *AWSLoadBalancerControllerStatusDeployment `json:"deployment,omitempty"`
+}
+
+// AWSLoadBalancerControllerStatusDeployment contains the effective configuration of the controller
+// deployment. The values which are not set in the spec are defaulted from the topology of the cluster.
+type AWSLoadBalancerControllerStatusDeployment struct {
+	// Replicas is the number of replicas of the controller
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	Replicas int32 `json:"replicas,omitempty"`
+
+	// LeaderElection is true if the leader election is enabled on the controller
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	LeaderElection bool `json:"leaderElection,omitempty"`
+
+	// HighAvailability is true if the replicas are spread across the zones and the nodes,
+	// rolled out and evicted one at a time
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	HighAvailability bool `json:"highAvailability,omitempty"`
+
+	// ControlPlaneTopology is the control plane topology of the cluster
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	ControlPlaneTopology string `json:"controlPlaneTopology,omitempty"`
+
+	// InfrastructureTopology is the infrastructure topology of the cluster
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	InfrastructureTopology string `json:"infrastructureTopology,omitempty"`
 }
 
 type AWSLoadBalancerControllerStatusSubnets struct {
@@ -133,6 +289,12 @@ type AWSLoadBalancerControllerStatusSubnets struct {
 	// +optional
 	SubnetTagging SubnetTaggingPolicy `json:"subnetTagging,omitempty"`
 
+	// VPCID is the ID of the VPC where the subnets were discovered
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	VPCID string `json:"vpcID,omitempty"`
+
 	// Internal is the list of subnet ids which have the tag `kubernetes.io/role/internal-elb`
 	//
 	// +kubebuilder:validation:Optional
@@ -156,6 +318,19 @@ type AWSLoadBalancerControllerStatusSubnets struct {
 	// +kubebuilder:validation:Optional
 	// +optional
 	Untagged []string `json:"untagged,omitempty"`
+
+	// ResourceTagKeys is the list of the keys of the resource tags added to the tagged subnets
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	ResourceTagKeys []string `json:"resourceTagKeys,omitempty"`
+
+	// ResourceTagsHash is the hash of the resource tags added to the tagged subnets,
+	// the tagged subnets are tagged again when the resource tags change
+	//
+	// +kubebuilder:validation:Optional
+	// +optional
+	ResourceTagsHash string `json:"resourceTagsHash,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -0,0 +1,174 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+)
+
+// MaxResourceTags is the number of tags which can be applied by the controller. AWS allows 50 tags per resource out of
+// which 3 are reserved for the tags added by the controller to the load balancers and target groups
+// (elbv2.k8s.aws/cluster, ingress.k8s.aws/stack and ingress.k8s.aws/resource).
+const MaxResourceTags = 47
+
+const (
+	// awsLoadBalancerControllerName is the only name of the AWSLoadBalancerController resource which is reconciled
+	awsLoadBalancerControllerName = "cluster"
+	// awsReservedTagPrefix is the prefix of the tag keys reserved for AWS use
+	awsReservedTagPrefix = "aws:"
+	// maxTagKeyLength is the maximum length of an AWS tag key
+	maxTagKeyLength = 128
+	// maxTagValueLength is the maximum length of an AWS tag value
+	maxTagValueLength = 256
+	// tagSeparators are the characters which separate the tags and the keys from the values in the --default-tags
+	// argument of the controller
+	tagSeparators = ",="
+)
+
+// SetupWebhookWithManager registers the validating webhook of the AWSLoadBalancerController with the manager.
+func (r *AWSLoadBalancerController) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(r).
+		Complete()
+}
+
+//+kubebuilder:webhook:path=/validate-networking-olm-openshift-io-v1alpha1-awsloadbalancercontroller,mutating=false,failurePolicy=fail,sideEffects=None,groups=networking.olm.openshift.io,resources=awsloadbalancercontrollers,verbs=create;update,versions=v1alpha1,name=vawsloadbalancercontroller.kb.io,admissionReviewVersions=v1
+
+var _ webhook.Validator = &AWSLoadBalancerController{}
+
+// ValidateCreate implements webhook.Validator so a webhook will be registered for the type
+func (r *AWSLoadBalancerController) ValidateCreate() error {
+	var errs field.ErrorList
+	if r.Name != awsLoadBalancerControllerName {
+		errs = append(errs, field.Invalid(field.NewPath("metadata", "name"), r.Name, fmt.Sprintf("only a resource named %q is reconciled by the operator", awsLoadBalancerControllerName)))
+	}
+	errs = append(errs, r.validateSpec()...)
+	return r.toInvalidError(errs)
+}
+
+// ValidateUpdate implements webhook.Validator so a webhook will be registered for the type. The spec is only
+// validated when it changes so that the resources admitted before the webhook was installed can still be updated,
+// e.g. to remove their finalizers. The name can't change on update.
+func (r *AWSLoadBalancerController) ValidateUpdate(old runtime.Object) error {
+	if oldController, ok := old.(*AWSLoadBalancerController); ok && equality.Semantic.DeepEqual(oldController.Spec, r.Spec) {
+		return nil
+	}
+	return r.toInvalidError(r.validateSpec())
+}
+
+// ValidateDelete implements webhook.Validator so a webhook will be registered for the type
+func (r *AWSLoadBalancerController) ValidateDelete() error {
+	return nil
+}
+
+// validateSpec returns the errors of the spec.
+func (r *AWSLoadBalancerController) validateSpec() field.ErrorList {
+	var errs field.ErrorList
+	specPath := field.NewPath("spec")
+	errs = append(errs, validateResourceTags(r.Spec.AdditionalResourceTags, specPath.Child("additionalResourceTags"))...)
+	errs = append(errs, validateIngressClass(r.Spec.IngressClass, specPath.Child("ingressClass"))...)
+	errs = append(errs, validateAddons(r.Spec.EnabledAddons, specPath.Child("enabledAddons"))...)
+	errs = append(errs, validateVPC(r.Spec.VPCID, r.Spec.VPCSelector, specPath)...)
+	return errs
+}
+
+// toInvalidError returns the Invalid error of the resource with the given errors, nil if there are none.
+func (r *AWSLoadBalancerController) toInvalidError(errs field.ErrorList) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(GroupVersion.WithKind("AWSLoadBalancerController").GroupKind(), r.Name, errs)
+}
+
+// validateResourceTags checks that the tags are accepted by AWS and can be passed to the controller
+// in the --default-tags argument.
+func validateResourceTags(tags map[string]string, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if len(tags) > MaxResourceTags {
+		errs = append(errs, field.TooMany(path, len(tags), MaxResourceTags))
+	}
+	// sort the keys so that the errors are reported in a stable order
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := tags[k]
+		keyPath := path.Key(k)
+		switch {
+		case k == "":
+			errs = append(errs, field.Invalid(keyPath, k, "tag key must not be empty"))
+		case len(k) > maxTagKeyLength:
+			errs = append(errs, field.TooLong(keyPath, k, maxTagKeyLength))
+		case strings.HasPrefix(strings.ToLower(k), awsReservedTagPrefix):
+			errs = append(errs, field.Invalid(keyPath, k, fmt.Sprintf("tag keys with the prefix %q are reserved for AWS use", awsReservedTagPrefix)))
+		case strings.ContainsAny(k, tagSeparators):
+			errs = append(errs, field.Invalid(keyPath, k, "tag key must not contain ',' or '='"))
+		}
+		switch {
+		case len(v) > maxTagValueLength:
+			errs = append(errs, field.TooLong(keyPath, v, maxTagValueLength))
+		case strings.ContainsAny(v, tagSeparators):
+			errs = append(errs, field.Invalid(keyPath, v, "tag value must not contain ',' or '='"))
+		}
+	}
+	return errs
+}
+
+// validateIngressClass checks that the IngressClass name is a valid object name.
+func validateIngressClass(ingressClass string, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	// the empty value is defaulted to "alb"
+	if ingressClass == "" {
+		return errs
+	}
+	for _, msg := range validation.IsDNS1123Subdomain(ingressClass) {
+		errs = append(errs, field.Invalid(path, ingressClass, msg))
+	}
+	return errs
+}
+
+// validateAddons checks that every addon is enabled only once. WAFv1 (WAF Classic) and WAFv2 can be enabled
+// together like in the controller, which enables both by default.
+func validateAddons(addons []AWSAddon, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	enabled := make(map[AWSAddon]struct{}, len(addons))
+	for i, a := range addons {
+		if _, ok := enabled[a]; ok {
+			errs = append(errs, field.Duplicate(path.Index(i), a))
+		}
+		enabled[a] = struct{}{}
+	}
+	return errs
+}
+
+// validateVPC checks that the VPC is selected either by ID or by tags and that the selector has valid tags.
+func validateVPC(vpcID string, selector *VPCSelector, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if selector == nil {
+		return errs
+	}
+	selectorPath := path.Child("vpcSelector")
+	if vpcID != "" {
+		errs = append(errs, field.Forbidden(selectorPath, "vpcSelector cannot be set together with vpcID"))
+	}
+	if len(selector.MatchTags) == 0 {
+		errs = append(errs, field.Required(selectorPath.Child("matchTags"), "at least one tag must be matched"))
+	}
+	for k := range selector.MatchTags {
+		if k == "" {
+			errs = append(errs, field.Invalid(selectorPath.Child("matchTags").Key(k), k, "tag key must not be empty"))
+		}
+	}
+	return errs
+}
@@ -0,0 +1,209 @@
+package v1alpha1
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestValidate(t *testing.T) {
+	tooManyTags := make(map[string]string)
+	for i := 0; i <= MaxResourceTags; i++ {
+		tooManyTags[fmt.Sprintf("key-%d", i)] = "value"
+	}
+	for _, tc := range []struct {
+		name          string
+		resourceName  string
+		spec          AWSLoadBalancerControllerSpec
+		expectedError string
+	}{
+		{
+			name:         "valid resource",
+			resourceName: "cluster",
+			spec: AWSLoadBalancerControllerSpec{
+				AdditionalResourceTags: map[string]string{"cost-center": "1234", "owner": "team a"},
+				IngressClass:           "alb",
+				EnabledAddons:          []AWSAddon{AWSAddonShield, AWSAddonWAFv2},
+			},
+		},
+		{
+			name:         "defaulted fields",
+			resourceName: "cluster",
+		},
+		{
+			name:          "name other than cluster",
+			resourceName:  "my-controller",
+			expectedError: `metadata.name: Invalid value: "my-controller": only a resource named "cluster" is reconciled by the operator`,
+		},
+		{
+			name:         "tag value with comma",
+			resourceName: "cluster",
+			spec: AWSLoadBalancerControllerSpec{
+				AdditionalResourceTags: map[string]string{"owner": "team-a,team-b"},
+			},
+			expectedError: `spec.additionalResourceTags[owner]: Invalid value: "team-a,team-b": tag value must not contain ',' or '='`,
+		},
+		{
+			name:         "tag value with equals",
+			resourceName: "cluster",
+			spec: AWSLoadBalancerControllerSpec{
+				AdditionalResourceTags: map[string]string{"owner": "team=a"},
+			},
+			expectedError: `spec.additionalResourceTags[owner]: Invalid value: "team=a": tag value must not contain ',' or '='`,
+		},
+		{
+			name:         "tag key with equals",
+			resourceName: "cluster",
+			spec: AWSLoadBalancerControllerSpec{
+				AdditionalResourceTags: map[string]string{"owner=": "team-a"},
+			},
+			expectedError: `spec.additionalResourceTags[owner=]: Invalid value: "owner=": tag key must not contain ',' or '='`,
+		},
+		{
+			name:         "reserved tag prefix",
+			resourceName: "cluster",
+			spec: AWSLoadBalancerControllerSpec{
+				AdditionalResourceTags: map[string]string{"AWS:owner": "team-a"},
+			},
+			expectedError: `spec.additionalResourceTags[AWS:owner]: Invalid value: "AWS:owner": tag keys with the prefix "aws:" are reserved for AWS use`,
+		},
+		{
+			name:         "tag key too long",
+			resourceName: "cluster",
+			spec: AWSLoadBalancerControllerSpec{
+				AdditionalResourceTags: map[string]string{strings.Repeat("k", maxTagKeyLength+1): "value"},
+			},
+			expectedError: "Too long: must have at most 128 bytes",
+		},
+		{
+			name:         "too many tags",
+			resourceName: "cluster",
+			spec: AWSLoadBalancerControllerSpec{
+				AdditionalResourceTags: tooManyTags,
+			},
+			expectedError: "spec.additionalResourceTags: Too many: 48: must have at most 47 items",
+		},
+		{
+			name:         "invalid ingress class",
+			resourceName: "cluster",
+			spec: AWSLoadBalancerControllerSpec{
+				IngressClass: "My_Class",
+			},
+			expectedError: `spec.ingressClass: Invalid value: "My_Class": a lowercase RFC 1123 subdomain`,
+		},
+		{
+			name:         "WAFv1 and WAFv2 enabled",
+			resourceName: "cluster",
+			spec: AWSLoadBalancerControllerSpec{
+				EnabledAddons: []AWSAddon{AWSAddonWAFv1, AWSAddonWAFv2},
+			},
+		},
+		{
+			name:         "duplicate addon",
+			resourceName: "cluster",
+			spec: AWSLoadBalancerControllerSpec{
+				EnabledAddons: []AWSAddon{AWSAddonShield, AWSAddonShield},
+			},
+			expectedError: `spec.enabledAddons[1]: Duplicate value: "AWSShield"`,
+		},
+		{
+			name:         "vpc selected by tags",
+			resourceName: "cluster",
+			spec: AWSLoadBalancerControllerSpec{
+				VPCSelector: &VPCSelector{MatchTags: map[string]string{"network": "shared", "owner": ""}},
+			},
+		},
+		{
+			name:         "vpc id and selector",
+			resourceName: "cluster",
+			spec: AWSLoadBalancerControllerSpec{
+				VPCID:       "vpc-0123456789abcdef0",
+				VPCSelector: &VPCSelector{MatchTags: map[string]string{"network": "shared"}},
+			},
+			expectedError: "spec.vpcSelector: Forbidden: vpcSelector cannot be set together with vpcID",
+		},
+		{
+			name:         "empty vpc selector",
+			resourceName: "cluster",
+			spec: AWSLoadBalancerControllerSpec{
+				VPCSelector: &VPCSelector{},
+			},
+			expectedError: "spec.vpcSelector.matchTags: Required value: at least one tag must be matched",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			controller := &AWSLoadBalancerController{
+				ObjectMeta: metav1.ObjectMeta{Name: tc.resourceName},
+				Spec:       tc.spec,
+			}
+			// the update from the defaulted spec validates the spec
+			old := &AWSLoadBalancerController{ObjectMeta: metav1.ObjectMeta{Name: tc.resourceName}}
+			for op, err := range map[string]error{
+				"create": controller.ValidateCreate(),
+				"update": controller.ValidateUpdate(old),
+			} {
+				if op == "update" && strings.HasPrefix(tc.expectedError, "metadata.name") {
+					// the name is only validated on creation
+					if err != nil {
+						t.Errorf("unexpected error on update: %v", err)
+					}
+					continue
+				}
+				if tc.expectedError == "" {
+					if err != nil {
+						t.Errorf("unexpected error on %s: %v", op, err)
+					}
+					continue
+				}
+				if err == nil {
+					t.Errorf("expected error on %s containing %q, got nil", op, tc.expectedError)
+					continue
+				}
+				if !strings.Contains(err.Error(), tc.expectedError) {
+					t.Errorf("expected error on %s containing %q, got %q", op, tc.expectedError, err.Error())
+				}
+			}
+		})
+	}
+}
+
+func TestValidateUpdate(t *testing.T) {
+	invalidSpec := AWSLoadBalancerControllerSpec{IngressClass: "My_Class"}
+	for _, tc := range []struct {
+		name          string
+		old           AWSLoadBalancerControllerSpec
+		new           AWSLoadBalancerControllerSpec
+		expectedError bool
+	}{
+		{
+			// e.g. the removal of a finalizer from a resource admitted before the webhook was installed
+			name: "unchanged invalid spec",
+			old:  invalidSpec,
+			new:  invalidSpec,
+		},
+		{
+			name:          "spec changed to an invalid spec",
+			new:           invalidSpec,
+			expectedError: true,
+		},
+		{
+			name: "invalid spec fixed",
+			old:  invalidSpec,
+			new:  AWSLoadBalancerControllerSpec{IngressClass: "alb"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			old := &AWSLoadBalancerController{ObjectMeta: metav1.ObjectMeta{Name: "other"}, Spec: tc.old}
+			updated := &AWSLoadBalancerController{ObjectMeta: metav1.ObjectMeta{Name: "other", Finalizers: []string{"test"}}, Spec: tc.new}
+			err := updated.ValidateUpdate(old)
+			if tc.expectedError && err == nil {
+				t.Errorf("expected error, got nil")
+			}
+			if !tc.expectedError && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
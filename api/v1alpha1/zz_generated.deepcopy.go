@@ -105,6 +105,21 @@ func (in *AWSLoadBalancerControllerSpec) DeepCopyInto(out *AWSLoadBalancerContro
 		*out = make([]AWSAddon, len(*in))
 		copy(*out, *in)
 	}
+	if in.VPCSelector != nil {
+		in, out := &in.VPCSelector, &out.VPCSelector
+		*out = new(VPCSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Monitoring != nil {
+		in, out := &in.Monitoring, &out.Monitoring
+		*out = new(AWSLoadBalancerMonitoringConfig)
+		**out = **in
+	}
+	if in.WebhookCertificates != nil {
+		in, out := &in.WebhookCertificates, &out.WebhookCertificates
+		*out = new(AWSLoadBalancerWebhookCertificatesConfig)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSLoadBalancerControllerSpec.
@@ -132,6 +147,11 @@ func (in *AWSLoadBalancerControllerStatus) DeepCopyInto(out *AWSLoadBalancerCont
 		*out = new(AWSLoadBalancerControllerStatusSubnets)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Deployment != nil {
+		in, out := &in.Deployment, &out.Deployment
+		*out = new(AWSLoadBalancerControllerStatusDeployment)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSLoadBalancerControllerStatus.
@@ -144,6 +164,21 @@ func (in *AWSLoadBalancerControllerStatus) DeepCopy() *AWSLoadBalancerController
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AWSLoadBalancerControllerStatusDeployment) DeepCopyInto(out *AWSLoadBalancerControllerStatusDeployment) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSLoadBalancerControllerStatusDeployment.
+func (in *AWSLoadBalancerControllerStatusDeployment) DeepCopy() *AWSLoadBalancerControllerStatusDeployment {
+	if in == nil {
+		return nil
+	}
+	out := new(AWSLoadBalancerControllerStatusDeployment)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *AWSLoadBalancerControllerStatusSubnets) DeepCopyInto(out *AWSLoadBalancerControllerStatusSubnets) {
 	*out = *in
@@ -167,6 +202,11 @@ func (in *AWSLoadBalancerControllerStatusSubnets) DeepCopyInto(out *AWSLoadBalan
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ResourceTagKeys != nil {
+		in, out := &in.ResourceTagKeys, &out.ResourceTagKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSLoadBalancerControllerStatusSubnets.
@@ -193,3 +233,55 @@ func (in *AWSLoadBalancerDeploymentConfig) DeepCopy() *AWSLoadBalancerDeployment
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AWSLoadBalancerMonitoringConfig) DeepCopyInto(out *AWSLoadBalancerMonitoringConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSLoadBalancerMonitoringConfig.
+func (in *AWSLoadBalancerMonitoringConfig) DeepCopy() *AWSLoadBalancerMonitoringConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(AWSLoadBalancerMonitoringConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AWSLoadBalancerWebhookCertificatesConfig) DeepCopyInto(out *AWSLoadBalancerWebhookCertificatesConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AWSLoadBalancerWebhookCertificatesConfig.
+func (in *AWSLoadBalancerWebhookCertificatesConfig) DeepCopy() *AWSLoadBalancerWebhookCertificatesConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(AWSLoadBalancerWebhookCertificatesConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VPCSelector) DeepCopyInto(out *VPCSelector) {
+	*out = *in
+	if in.MatchTags != nil {
+		in, out := &in.MatchTags, &out.MatchTags
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VPCSelector.
+func (in *VPCSelector) DeepCopy() *VPCSelector {
+	if in == nil {
+		return nil
+	}
+	out := new(VPCSelector)
+	in.DeepCopyInto(out)
+	return out
+}
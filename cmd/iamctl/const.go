@@ -11,21 +11,37 @@ type IAMPolicy struct {
 	Statement []cco.StatementEntry
 }
 
+// GetIAMPolicy returns the IAM policy of the CredentialsRequest of the controller.
 func GetIAMPolicy() IAMPolicy {
 	return IAMPolicy{
+		Version: {{.Policy.Version|printf "%q"}},
 		Statement: []cco.StatementEntry{
+			{{- range .Policy.Statement}}
 			{
-				Effect: {{.Statement.Effect|printf "%q"}},
-				Resource: {{range .Statement.Resource}}{{printf "%q" .}}{{end}},
+				Effect: {{.Effect|printf "%q"}},
+				Resource: {{index .Resource 0|printf "%q"}},
+				{{- if .Condition}}
+				PolicyCondition: cco.IAMPolicyCondition{
+					{{- range $operator, $keyValues := .Condition}}
+					{{$operator|printf "%q"}}: cco.IAMPolicyConditionKeyValue{
+						{{- range $key, $value := $keyValues}}
+						{{$key|printf "%q"}}: {{goValue $value}},
+						{{- end}}
+					},
+					{{- end}}
+				},
+				{{- else}}
 				PolicyCondition: cco.IAMPolicyCondition{},
+				{{- end}}
 				Action: []string{
-					{{range $index, $element := .Statement.Action -}}
-					{{.|printf "%q"}},{{printf "\n"}}
+					{{- range .Action}}
+					{{.|printf "%q"}},
 					{{- end}}
 				},
 			},
+			{{- end}}
 		},
 	}
-}	
+}
 `
 )
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// diffCmd prints the permission changes between two policies.
+var diffCmd = &cobra.Command{
+	Use:   "diff <old-policy.json> <new-policy.json>",
+	Short: "Prints the actions and resource scopes added and removed between two policy JSON files.",
+	Long: `Compares two IAM policy JSON files, e.g. the current policy and the one synced from upstream,
+	and prints the actions and the resource scopes which were added or removed.`,
+	Args:         cobra.ExactArgs(2),
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return diffIAMPolicies(cmd.OutOrStdout(), args[0], args[1])
+	},
+}
+
+// policyDiff holds the permission changes between two policies.
+type policyDiff struct {
+	addedActions     []string
+	removedActions   []string
+	addedResources   []string
+	removedResources []string
+}
+
+// empty returns true if the policies grant the same actions on the same resources.
+func (d policyDiff) empty() bool {
+	return len(d.addedActions) == 0 && len(d.removedActions) == 0 && len(d.addedResources) == 0 && len(d.removedResources) == 0
+}
+
+// diffIAMPolicies writes the permission changes between the old and the new policy files.
+func diffIAMPolicies(w io.Writer, oldFile, newFile string) error {
+	oldPolicy, err := readPolicy(oldFile)
+	if err != nil {
+		return err
+	}
+	newPolicy, err := readPolicy(newFile)
+	if err != nil {
+		return err
+	}
+
+	diff := diffPolicies(oldPolicy, newPolicy)
+	if diff.empty() {
+		_, err = fmt.Fprintln(w, "No changes in actions or resource scopes.")
+		return err
+	}
+
+	var out strings.Builder
+	for _, section := range []struct {
+		title string
+		sign  string
+		items []string
+	}{
+		{title: "Added actions", sign: "+", items: diff.addedActions},
+		{title: "Removed actions", sign: "-", items: diff.removedActions},
+		{title: "Added resource scopes", sign: "+", items: diff.addedResources},
+		{title: "Removed resource scopes", sign: "-", items: diff.removedResources},
+	} {
+		if len(section.items) == 0 {
+			continue
+		}
+		fmt.Fprintf(&out, "%s:\n", section.title)
+		for _, item := range section.items {
+			fmt.Fprintf(&out, "%s %s\n", section.sign, item)
+		}
+	}
+	_, err = io.WriteString(w, out.String())
+	return err
+}
+
+// diffPolicies compares the actions and the resource scopes of the policies. A resource scope is an action
+// granted on a resource, so that a change of the resources or the conditions of an action is reported even
+// if the action is present in both policies. The conditions are included in the scope.
+func diffPolicies(oldPolicy, newPolicy iamPolicy) policyDiff {
+	oldActions, oldResources := policyPermissions(oldPolicy)
+	newActions, newResources := policyPermissions(newPolicy)
+	return policyDiff{
+		addedActions:     sortedKeys(difference(newActions, oldActions)),
+		removedActions:   sortedKeys(difference(oldActions, newActions)),
+		addedResources:   sortedKeys(difference(newResources, oldResources)),
+		removedResources: sortedKeys(difference(oldResources, newResources)),
+	}
+}
+
+// policyPermissions returns the actions and the resource scopes allowed or denied by the policy.
+func policyPermissions(policy iamPolicy) (map[string]bool, map[string]bool) {
+	actions := make(map[string]bool)
+	resources := make(map[string]bool)
+	for _, statement := range preserve(policy).Statement {
+		var condition string
+		if len(statement.Condition) > 0 {
+			// the JSON of a map is sorted by its keys
+			js, _ := json.Marshal(statement.Condition)
+			condition = " when " + string(js)
+		}
+		for _, action := range statement.Action {
+			actions[action] = true
+			resources[fmt.Sprintf("%s %s on %s%s", statement.Effect, action, statement.Resource[0], condition)] = true
+		}
+	}
+	return actions, resources
+}
+
+func difference(a, b map[string]bool) map[string]bool {
+	diff := make(map[string]bool)
+	for k := range a {
+		if !b[k] {
+			diff[k] = true
+		}
+	}
+	return diff
+}
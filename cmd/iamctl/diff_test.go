@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiffIAMPolicies(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		oldFile  string
+		newFile  string
+		expected string
+	}{
+		{
+			name:     "same policy",
+			oldFile:  "iam-policy.json",
+			newFile:  "iam-policy.json",
+			expected: "No changes in actions or resource scopes.\n",
+		},
+		{
+			name:    "updated policy",
+			oldFile: "iam-policy.json",
+			newFile: "iam-policy-updated.json",
+			expected: `Added actions:
++ elasticloadbalancing:DescribeTags
+Removed actions:
+- ec2:DescribeVpcs
+Added resource scopes:
++ Allow elasticloadbalancing:AddTags on arn:aws:elasticloadbalancing:*:*:loadbalancer/net/*/* when {"StringEquals":{"elasticloadbalancing:CreateAction":["CreateTargetGroup","CreateLoadBalancer"]}}
++ Allow elasticloadbalancing:DescribeTags on *
++ Allow elasticloadbalancing:RemoveTags on arn:aws:elasticloadbalancing:*:*:loadbalancer/net/*/* when {"StringEquals":{"elasticloadbalancing:CreateAction":["CreateTargetGroup","CreateLoadBalancer"]}}
+Removed resource scopes:
+- Allow ec2:DescribeVpcs on *
+- Allow elasticloadbalancing:AddTags on arn:aws:elasticloadbalancing:*:*:loadbalancer/app/*/* when {"StringEquals":{"elasticloadbalancing:CreateAction":["CreateTargetGroup","CreateLoadBalancer"]}}
+- Allow elasticloadbalancing:RemoveTags on arn:aws:elasticloadbalancing:*:*:loadbalancer/app/*/* when {"StringEquals":{"elasticloadbalancing:CreateAction":["CreateTargetGroup","CreateLoadBalancer"]}}
+`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			if err := diffIAMPolicies(&out, filepath.Join("testdata", tc.oldFile), filepath.Join("testdata", tc.newFile)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, out.String()); diff != "" {
+				t.Errorf("unexpected diff output:\n%s", diff)
+			}
+		})
+	}
+}
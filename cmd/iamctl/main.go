@@ -1,9 +1,11 @@
 package main
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var (
@@ -15,6 +17,18 @@ var (
 
 	// pkg specifies the package with which the code is generated.
 	pkg string
+
+	// preserveStatements specifies whether the resources and conditions of the policy statements are kept.
+	preserveStatements bool
+
+	// maxPolicySize specifies the maximum size of a policy document after which the policy is split.
+	maxPolicySize int
+
+	// outputFormat specifies the format of the generated output.
+	outputFormat string
+
+	// credentialsRequest specifies the names used in the generated CredentialsRequests.
+	credentialsRequest credentialsRequestOptions
 )
 
 // rootCmd represents the base command when called without any subcommands
@@ -24,8 +38,9 @@ var rootCmd = &cobra.Command{
 	Long: `A CLI used to convert aws iam policy JSON to Go code. This
 	CLI produces a '.go' file that is consumed by the aws load balancer operator.
 	`,
-	Run: func(cmd *cobra.Command, args []string) {
-		generateIAMPolicy(inputFile, outputFile, pkg)
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return generateIAMPolicy(inputFile, outputFile, currentGenerateOptions())
 	},
 }
 
@@ -36,17 +51,55 @@ func main() {
 	}
 }
 
+// currentGenerateOptions returns the generate options set with the flags.
+func currentGenerateOptions() generateOptions {
+	return generateOptions{
+		pkg:                pkg,
+		outputFormat:       outputFormat,
+		preserveStatements: preserveStatements,
+		maxPolicySize:      maxPolicySize,
+		credentialsRequest: credentialsRequest,
+	}
+}
+
+// generateFlags returns the flags which specify the input, the output and the options of the generated output.
+// They are shared by the root command and the verify subcommand.
+func generateFlags() *pflag.FlagSet {
+	flags := pflag.NewFlagSet("generate", pflag.ExitOnError)
+
+	flags.StringVarP(&inputFile, "input-file", "i", "", "Used to specify input JSON file path.")
+
+	flags.StringVarP(&outputFile, "output-file", "o", "", "Used to specify output file path.")
+
+	flags.StringVarP(&pkg, "package", "p", "main", "Used to specify output Go file path.")
+
+	flags.BoolVarP(&preserveStatements, "preserve-statements", "s", false, "Used to keep the resources and conditions of the policy statements instead of minifying the policy.")
+
+	flags.IntVarP(&maxPolicySize, "max-policy-size", "m", rolePolicySizeLimit, fmt.Sprintf("Used to specify the maximum size in bytes of a policy document, larger policies are split. A value of 0 disables the split. The default is the limit of an IAM role, the limit of an IAM user is %d.", userPolicySizeLimit))
+
+	flags.StringVarP(&outputFormat, "output-format", "f", goOutputFormat, fmt.Sprintf("Used to specify the output format, one of %v.", outputFormats))
+
+	flags.StringVar(&credentialsRequest.name, "credentials-request-name", "aws-load-balancer-controller-cluster", "Used to specify the name of the generated CredentialsRequest.")
+	flags.StringVar(&credentialsRequest.namespace, "credentials-request-namespace", "openshift-cloud-credential-operator", "Used to specify the namespace of the generated CredentialsRequest.")
+	flags.StringVar(&credentialsRequest.secretName, "secret-name", "aws-load-balancer-controller-credentialsrequest-cluster", "Used to specify the name of the secret referenced by the generated CredentialsRequest.")
+	flags.StringVar(&credentialsRequest.secretNamespace, "secret-namespace", "aws-load-balancer-operator", "Used to specify the namespace of the secret referenced by the generated CredentialsRequest.")
+	flags.StringVar(&credentialsRequest.serviceAccountName, "service-account-name", "aws-load-balancer-controller-cluster", "Used to specify the service account name in the generated CredentialsRequest.")
+	return flags
+}
+
 func init() {
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 
-	rootCmd.PersistentFlags().StringVarP(&inputFile, "input-file", "i", "", "Used to specify input JSON file path.")
-	_ = rootCmd.MarkPersistentFlagRequired("input-file")
-
-	rootCmd.PersistentFlags().StringVarP(&outputFile, "output-file", "o", "", "Used to specify output Go file path.")
-	_ = rootCmd.MarkPersistentFlagRequired("output-file")
+	flags := generateFlags()
+	for _, cmd := range []*cobra.Command{rootCmd, verifyCmd} {
+		cmd.Flags().AddFlagSet(flags)
+		_ = cmd.MarkFlagRequired("input-file")
+		_ = cmd.MarkFlagRequired("output-file")
+	}
 
-	rootCmd.PersistentFlags().StringVarP(&pkg, "package", "p", "main", "Used to specify output Go file path.")
-	_ = rootCmd.MarkPersistentFlagRequired("package")
+	rootCmd.AddCommand(verifyCmd)
+	rootCmd.AddCommand(diffCmd)
+	rootCmd.AddCommand(operatorCmd)
 }
@@ -0,0 +1,356 @@
+package main
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	// awsServicePackagePrefix is the import path prefix of the AWS SDK service packages.
+	awsServicePackagePrefix = "github.com/aws/aws-sdk-go-v2/service/"
+	// resourceMarker is the marker which scopes the IAM action of an interface method to a resource.
+	// The actions of the methods without the marker are allowed on all the resources.
+	resourceMarker = "+iamctl:resource="
+	// operandMarker is the marker of the interfaces whose methods are called with the credentials of the operand.
+	// Their actions are not allowed to the operator.
+	operandMarker = "+iamctl:operand"
+	// allResources is the resource of the actions which are not scoped.
+	allResources = "*"
+	// csvCredentialsRequestStart is the line of the ClusterServiceVersion description which starts the heredoc
+	// creating the operator CredentialsRequest.
+	csvCredentialsRequestStart = "cat << EOF| oc create -f -"
+	// csvCredentialsRequestEnd is the line which ends the heredoc.
+	csvCredentialsRequestEnd = "EOF"
+)
+
+// awsServiceIAMPrefixes maps the AWS SDK service packages to the service prefixes of their IAM actions.
+var awsServiceIAMPrefixes = map[string]string{
+	"ec2":                    "ec2",
+	"elasticloadbalancingv2": "elasticloadbalancing",
+	"iam":                    "iam",
+	"sts":                    "sts",
+}
+
+var (
+	// awsPackageDir specifies the directory of the package with the AWS client interfaces of the operator.
+	awsPackageDir string
+
+	// verifyOperatorCredentialsRequest specifies whether the output file is verified instead of being written.
+	verifyOperatorCredentialsRequest bool
+
+	// operatorCSVFiles specifies the ClusterServiceVersion files whose description embeds the operator CredentialsRequest.
+	operatorCSVFiles []string
+
+	// operatorCredentialsRequest specifies the names used in the operator CredentialsRequest.
+	operatorCredentialsRequest credentialsRequestOptions
+)
+
+// operatorCmd generates the CredentialsRequest of the operator from the AWS client interfaces.
+var operatorCmd = &cobra.Command{
+	Use:   "operator",
+	Short: "Generates the operator CredentialsRequest from the AWS client interfaces of the operator.",
+	Long: `Generates the CredentialsRequest of the operator with the IAM actions of the methods of the
+	AWS client interfaces, e.g. ec2:DescribeSubnets for the DescribeSubnets method of an interface taking EC2 options.
+	The action of a method is scoped to a resource with a '// +iamctl:resource=<arn>' marker comment.
+	The interfaces called with the credentials of the operand are skipped with a '// +iamctl:operand' marker comment.
+	The CredentialsRequest embedded in the description of the ClusterServiceVersion files is replaced as well.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if verifyOperatorCredentialsRequest {
+			return verifyOperatorPolicy(awsPackageDir, outputFile, operatorCSVFiles, operatorCredentialsRequest)
+		}
+		return generateOperatorPolicy(awsPackageDir, outputFile, operatorCSVFiles, operatorCredentialsRequest)
+	},
+}
+
+// generateOperatorPolicy writes the CredentialsRequest of the operator derived from the package directory into the output file
+// and into the description of the ClusterServiceVersion files.
+func generateOperatorPolicy(dir, output string, csvFiles []string, opts credentialsRequestOptions) error {
+	out, err := renderOperatorPolicy(dir, opts)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(output, out, 0644); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	for _, csvFile := range csvFiles {
+		csv, err := renderCSVCredentialsRequest(csvFile, out)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(csvFile, csv, 0644); err != nil {
+			return fmt.Errorf("failed to write ClusterServiceVersion file: %w", err)
+		}
+	}
+	return nil
+}
+
+// verifyOperatorPolicy returns an error if the output file or the description of the ClusterServiceVersion files differ
+// from the CredentialsRequest derived from the package directory.
+func verifyOperatorPolicy(dir, output string, csvFiles []string, opts credentialsRequestOptions) error {
+	expected, err := renderOperatorPolicy(dir, opts)
+	if err != nil {
+		return err
+	}
+	if err := verifyOutput(output, expected, dir); err != nil {
+		return err
+	}
+	for _, csvFile := range csvFiles {
+		csv, err := renderCSVCredentialsRequest(csvFile, expected)
+		if err != nil {
+			return err
+		}
+		if err := verifyOutput(csvFile, csv, dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderCSVCredentialsRequest returns the ClusterServiceVersion file with the CredentialsRequest embedded in its
+// description replaced by the given manifest. The manifest is the body of the heredoc which creates the
+// CredentialsRequest in the installation steps of the description, the rest of the file is kept as is.
+func renderCSVCredentialsRequest(csvFile string, manifest []byte) ([]byte, error) {
+	csv, err := os.ReadFile(csvFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ClusterServiceVersion file: %w", err)
+	}
+
+	lines := strings.Split(string(csv), "\n")
+	start, end := -1, -1
+	for i, line := range lines {
+		switch {
+		case start < 0 && strings.TrimSpace(line) == csvCredentialsRequestStart:
+			start = i
+		case start >= 0 && strings.TrimSpace(line) == csvCredentialsRequestEnd:
+			end = i
+		}
+		if end >= 0 {
+			break
+		}
+	}
+	if start < 0 || end < 0 {
+		return nil, fmt.Errorf("no %q heredoc found in the description of %s", csvCredentialsRequestStart, csvFile)
+	}
+
+	// the heredoc is indented like its first line in the description
+	indent := lines[start][:len(lines[start])-len(strings.TrimLeft(lines[start], " "))]
+	embedded := append([]string{}, lines[:start+1]...)
+	for _, line := range strings.Split(strings.TrimSuffix(string(manifest), "\n"), "\n") {
+		if line == "---" {
+			continue
+		}
+		embedded = append(embedded, indent+line)
+	}
+	embedded = append(embedded, lines[end:]...)
+	return []byte(strings.Join(embedded, "\n")), nil
+}
+
+// renderOperatorPolicy returns the CredentialsRequest with the IAM actions of the AWS client interfaces of the package directory.
+func renderOperatorPolicy(dir string, opts credentialsRequestOptions) ([]byte, error) {
+	actions, err := awsClientActions(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(actions) == 0 {
+		return nil, fmt.Errorf("no AWS client interface methods found in %s", dir)
+	}
+	return generateCredentialsRequests([]iamPolicy{actionsPolicy(actions)}, opts)
+}
+
+// actionsPolicy returns the policy allowing the actions on their resources, with a statement per resource.
+// The statement allowing the actions on all the resources comes first.
+func actionsPolicy(actions map[string]string) iamPolicy {
+	byResource := make(map[string][]string)
+	for action, resource := range actions {
+		byResource[resource] = append(byResource[resource], action)
+	}
+	resources := make([]string, 0, len(byResource))
+	for resource := range byResource {
+		resources = append(resources, resource)
+	}
+	sort.Slice(resources, func(i, j int) bool {
+		if resources[i] == allResources || resources[j] == allResources {
+			return resources[i] == allResources
+		}
+		return resources[i] < resources[j]
+	})
+
+	policy := iamPolicy{Version: "2012-10-17"}
+	for _, resource := range resources {
+		statementActions := byResource[resource]
+		sort.Strings(statementActions)
+		policy.Statement = append(policy.Statement, policyStatement{
+			Effect:   "Allow",
+			Action:   AWSValue(statementActions),
+			Resource: AWSValue{resource},
+		})
+	}
+	return policy
+}
+
+// awsClientActions returns the IAM actions of the interface methods of the package directory mapped to their resources.
+// An interface method is an AWS call if its last parameter is the variadic options of an AWS SDK service client.
+func awsClientActions(dir string) (map[string]string, error) {
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, dir, func(fi os.FileInfo) bool {
+		return !strings.HasSuffix(fi.Name(), "_test.go")
+	}, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse package directory %s: %w", dir, err)
+	}
+
+	actions := make(map[string]string)
+	for _, pkg := range pkgs {
+		for _, file := range pkg.Files {
+			imports, err := fileImports(file)
+			if err != nil {
+				return nil, err
+			}
+			var inspectErr error
+			ast.Inspect(file, func(n ast.Node) bool {
+				if inspectErr != nil {
+					return false
+				}
+				// the doc of a type declared on its own is the doc of its declaration
+				if decl, ok := n.(*ast.GenDecl); ok {
+					return !hasMarker(decl.Doc, operandMarker)
+				}
+				spec, ok := n.(*ast.TypeSpec)
+				if !ok {
+					return true
+				}
+				iface, ok := spec.Type.(*ast.InterfaceType)
+				if !ok || hasMarker(spec.Doc, operandMarker) {
+					return false
+				}
+				for _, method := range iface.Methods.List {
+					// embedded interfaces are parsed on their own
+					if len(method.Names) == 0 {
+						continue
+					}
+					action, resource, err := methodAction(method, imports)
+					if err != nil {
+						inspectErr = fmt.Errorf("%s.%s: %w", spec.Name.Name, method.Names[0].Name, err)
+						return false
+					}
+					if action == "" {
+						continue
+					}
+					if current, ok := actions[action]; ok && current != resource {
+						inspectErr = fmt.Errorf("%s.%s: action %s is scoped to both %q and %q", spec.Name.Name, method.Names[0].Name, action, current, resource)
+						return false
+					}
+					actions[action] = resource
+				}
+				return false
+			})
+			if inspectErr != nil {
+				return nil, inspectErr
+			}
+		}
+	}
+	return actions, nil
+}
+
+// methodAction returns the IAM action of the interface method and the resource it's scoped to.
+// An empty action is returned if the method is not an AWS call.
+func methodAction(method *ast.Field, imports map[string]string) (string, string, error) {
+	fn, ok := method.Type.(*ast.FuncType)
+	if !ok || fn.Params == nil || len(fn.Params.List) == 0 {
+		return "", "", nil
+	}
+	// the last parameter of the SDK client methods is optFns ...func(*<service>.Options)
+	ellipsis, ok := fn.Params.List[len(fn.Params.List)-1].Type.(*ast.Ellipsis)
+	if !ok {
+		return "", "", nil
+	}
+	optFn, ok := ellipsis.Elt.(*ast.FuncType)
+	if !ok || optFn.Params == nil || len(optFn.Params.List) != 1 {
+		return "", "", nil
+	}
+	star, ok := optFn.Params.List[0].Type.(*ast.StarExpr)
+	if !ok {
+		return "", "", nil
+	}
+	sel, ok := star.X.(*ast.SelectorExpr)
+	if !ok || sel.Sel.Name != "Options" {
+		return "", "", nil
+	}
+	pkgIdent, ok := sel.X.(*ast.Ident)
+	if !ok {
+		return "", "", nil
+	}
+	importPath, ok := imports[pkgIdent.Name]
+	if !ok || !strings.HasPrefix(importPath, awsServicePackagePrefix) {
+		return "", "", nil
+	}
+
+	service := strings.TrimPrefix(importPath, awsServicePackagePrefix)
+	prefix, ok := awsServiceIAMPrefixes[service]
+	if !ok {
+		return "", "", fmt.Errorf("unknown IAM prefix of AWS service %q", service)
+	}
+
+	resource := allResources
+	if method.Doc != nil {
+		for _, comment := range method.Doc.List {
+			text := strings.TrimSpace(strings.TrimPrefix(comment.Text, "//"))
+			if strings.HasPrefix(text, resourceMarker) {
+				resource = strings.TrimPrefix(text, resourceMarker)
+			}
+		}
+	}
+	return prefix + ":" + method.Names[0].Name, resource, nil
+}
+
+// hasMarker returns true if one of the comments is the marker.
+func hasMarker(doc *ast.CommentGroup, marker string) bool {
+	if doc == nil {
+		return false
+	}
+	for _, comment := range doc.List {
+		if strings.TrimSpace(strings.TrimPrefix(comment.Text, "//")) == marker {
+			return true
+		}
+	}
+	return false
+}
+
+// fileImports maps the names under which the packages are imported in the file to their import paths.
+func fileImports(file *ast.File) (map[string]string, error) {
+	imports := make(map[string]string, len(file.Imports))
+	for _, spec := range file.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unquote import path %s: %w", spec.Path.Value, err)
+		}
+		name := path[strings.LastIndex(path, "/")+1:]
+		if spec.Name != nil {
+			name = spec.Name.Name
+		}
+		imports[name] = path
+	}
+	return imports, nil
+}
+
+func init() {
+	operatorCmd.Flags().StringVarP(&awsPackageDir, "aws-package-dir", "d", "./pkg/aws", "Used to specify the directory of the package with the AWS client interfaces.")
+	operatorCmd.Flags().StringVarP(&outputFile, "output-file", "o", "", "Used to specify output file path.")
+	operatorCmd.Flags().BoolVar(&verifyOperatorCredentialsRequest, "verify", false, "Used to verify that the output file is up to date instead of writing it.")
+	operatorCmd.Flags().StringSliceVar(&operatorCSVFiles, "csv-file", nil, "Used to specify the ClusterServiceVersion files whose description embeds the operator CredentialsRequest.")
+	operatorCmd.Flags().StringVar(&operatorCredentialsRequest.name, "credentials-request-name", "aws-load-balancer-operator", "Used to specify the name of the generated CredentialsRequest.")
+	operatorCmd.Flags().StringVar(&operatorCredentialsRequest.namespace, "credentials-request-namespace", "openshift-cloud-credential-operator", "Used to specify the namespace of the generated CredentialsRequest.")
+	operatorCmd.Flags().StringVar(&operatorCredentialsRequest.secretName, "secret-name", "aws-load-balancer-operator", "Used to specify the name of the secret referenced by the generated CredentialsRequest.")
+	operatorCmd.Flags().StringVar(&operatorCredentialsRequest.secretNamespace, "secret-namespace", "aws-load-balancer-operator", "Used to specify the namespace of the secret referenced by the generated CredentialsRequest.")
+	operatorCmd.Flags().StringVar(&operatorCredentialsRequest.serviceAccountName, "service-account-name", "aws-load-balancer-operator-controller-manager", "Used to specify the service account name in the generated CredentialsRequest.")
+	_ = operatorCmd.MarkFlagRequired("output-file")
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestAWSClientActions(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		dir         string
+		expected    map[string]string
+		expectError bool
+	}{
+		{
+			name: "ec2 and elbv2 clients without the operand clients",
+			dir:  "awsclients",
+			expected: map[string]string{
+				"ec2:DescribeVpcs":                           "*",
+				"ec2:DescribeSubnets":                        "*",
+				"ec2:CreateTags":                             "arn:aws:ec2:*:*:subnet/*",
+				"elasticloadbalancing:DescribeLoadBalancers": "*",
+			},
+		},
+		{
+			name:        "unknown service",
+			dir:         "unknownservice",
+			expectError: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			actions, err := awsClientActions(filepath.Join("testdata", tc.dir))
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, actions); diff != "" {
+				t.Errorf("unexpected actions:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestActionsPolicy(t *testing.T) {
+	actions := map[string]string{
+		"ec2:DescribeVpcs":    "*",
+		"ec2:DescribeSubnets": "*",
+		"ec2:DeleteTags":      "arn:aws:ec2:*:*:subnet/*",
+		"ec2:CreateTags":      "arn:aws:ec2:*:*:subnet/*",
+		"ec2:ModifyVpc":       "arn:aws:ec2:*:*:vpc/*",
+	}
+	expected := iamPolicy{
+		Version: "2012-10-17",
+		Statement: []policyStatement{
+			{Effect: "Allow", Action: AWSValue{"ec2:DescribeSubnets", "ec2:DescribeVpcs"}, Resource: AWSValue{"*"}},
+			{Effect: "Allow", Action: AWSValue{"ec2:CreateTags", "ec2:DeleteTags"}, Resource: AWSValue{"arn:aws:ec2:*:*:subnet/*"}},
+			{Effect: "Allow", Action: AWSValue{"ec2:ModifyVpc"}, Resource: AWSValue{"arn:aws:ec2:*:*:vpc/*"}},
+		},
+	}
+	if diff := cmp.Diff(expected, actionsPolicy(actions)); diff != "" {
+		t.Errorf("unexpected policy:\n%s", diff)
+	}
+}
+
+// TestOperatorCredentialsRequest checks that the operator CredentialsRequest and its copies in the description of the
+// ClusterServiceVersions allow all the AWS calls of the operator.
+func TestOperatorCredentialsRequest(t *testing.T) {
+	opts := credentialsRequestOptions{
+		name:               "aws-load-balancer-operator",
+		namespace:          "openshift-cloud-credential-operator",
+		secretName:         "aws-load-balancer-operator",
+		secretNamespace:    "aws-load-balancer-operator",
+		serviceAccountName: "aws-load-balancer-operator-controller-manager",
+	}
+	csvFiles := []string{
+		filepath.Join("..", "..", "config", "manifests", "bases", "aws-load-balancer-operator.clusterserviceversion.yaml"),
+		filepath.Join("..", "..", "bundle", "manifests", "aws-load-balancer-operator.clusterserviceversion.yaml"),
+	}
+	if err := verifyOperatorPolicy(filepath.Join("..", "..", "pkg", "aws"), filepath.Join("..", "..", "hack", "operator-credentials-request.yaml"), csvFiles, opts); err != nil {
+		t.Fatalf("operator CredentialsRequest is not up to date, run make generate: %v", err)
+	}
+}
+
+// TestOperatorCredentialsRequestPartitions checks that the resources of the operator CredentialsRequest match the
+// resources of all the AWS partitions, e.g. GovCloud and China, as it's not rewritten for the partition of the cluster.
+func TestOperatorCredentialsRequestPartitions(t *testing.T) {
+	actions, err := awsClientActions(filepath.Join("..", "..", "pkg", "aws"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for action, resource := range actions {
+		if resource != allResources && !strings.HasPrefix(resource, "arn:*:") {
+			t.Errorf("action %s is scoped to resource %q of a single partition, use arn:*: as prefix", action, resource)
+		}
+	}
+}
+
+func TestRenderCSVCredentialsRequest(t *testing.T) {
+	manifest := "---\napiVersion: cloudcredential.openshift.io/v1\nkind: CredentialsRequest\nspec:\n  providerSpec:\n    statementEntries:\n    - action:\n      - ec2:DescribeVpcs\n"
+	for _, tc := range []struct {
+		name        string
+		csv         string
+		expected    string
+		expectError bool
+	}{
+		{
+			name: "outdated heredoc",
+			csv: `spec:
+  description: |-
+    Create the credentials:
+    ` + "```" + `
+    cat << EOF| oc create -f -
+    apiVersion: cloudcredential.openshift.io/v1
+    kind: CredentialsRequest
+    EOF
+    ` + "```" + `
+  displayName: operator
+`,
+			expected: `spec:
+  description: |-
+    Create the credentials:
+    ` + "```" + `
+    cat << EOF| oc create -f -
+    apiVersion: cloudcredential.openshift.io/v1
+    kind: CredentialsRequest
+    spec:
+      providerSpec:
+        statementEntries:
+        - action:
+          - ec2:DescribeVpcs
+    EOF
+    ` + "```" + `
+  displayName: operator
+`,
+		},
+		{
+			name:        "no heredoc",
+			csv:         "spec:\n  description: operator\n",
+			expectError: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			csvFile := filepath.Join(t.TempDir(), "csv.yaml")
+			if err := os.WriteFile(csvFile, []byte(tc.csv), 0644); err != nil {
+				t.Fatalf("failed to write CSV file: %v", err)
+			}
+			csv, err := renderCSVCredentialsRequest(csvFile, []byte(manifest))
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, string(csv)); diff != "" {
+				t.Errorf("unexpected CSV (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"text/template"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+
+	cco "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+)
+
+const (
+	// goOutputFormat generates the Go code consumed by the operator.
+	goOutputFormat = "go"
+	// credentialsRequestOutputFormat generates the CredentialsRequest manifests of the controller.
+	credentialsRequestOutputFormat = "credentialsrequest"
+	// policyOutputFormat generates the IAM policy JSON.
+	policyOutputFormat = "policy"
+)
+
+var outputFormats = []string{goOutputFormat, credentialsRequestOutputFormat, policyOutputFormat}
+
+// generateOptions holds the options of the generated output.
+type generateOptions struct {
+	pkg                string
+	outputFormat       string
+	preserveStatements bool
+	maxPolicySize      int
+	credentialsRequest credentialsRequestOptions
+}
+
+// credentialsRequestOptions holds the names used in the generated CredentialsRequests.
+// The defaults match the CredentialsRequest created by the operator for the controller.
+type credentialsRequestOptions struct {
+	name               string
+	namespace          string
+	secretName         string
+	secretNamespace    string
+	serviceAccountName string
+}
+
+// generateGo returns the Go code with the statements of the policy. The operator creates a single CredentialsRequest
+// for the controller, so the policy can't be split into multiple documents.
+func generateGo(policies []iamPolicy, pkg string) ([]byte, error) {
+	if len(policies) != 1 {
+		return nil, fmt.Errorf("the policy is split into %d documents but the operator creates a single CredentialsRequest, minify the policy or increase the maximum policy size", len(policies))
+	}
+
+	tmpl, err := template.New("").Funcs(template.FuncMap{"goValue": goValue}).Parse(filetemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template: %w", err)
+	}
+
+	var in bytes.Buffer
+	err = tmpl.Execute(&in, struct {
+		Package string
+		Policy  iamPolicy
+	}{Package: pkg, Policy: policies[0]})
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute template: %w", err)
+	}
+
+	src, err := format.Source(in.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to format generated code: %w", err)
+	}
+	return src, nil
+}
+
+// generateCredentialsRequests returns the YAML manifests of the CredentialsRequests, one per policy. When the policy
+// was split the names of the CredentialsRequests and their secrets are suffixed with the index of the policy.
+func generateCredentialsRequests(policies []iamPolicy, opts credentialsRequestOptions) ([]byte, error) {
+	codec, err := cco.NewCodec()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create provider codec: %w", err)
+	}
+
+	var out bytes.Buffer
+	for i, policy := range policies {
+		name, secretName := opts.name, opts.secretName
+		if len(policies) > 1 {
+			name = fmt.Sprintf("%s-%d", name, i)
+			secretName = fmt.Sprintf("%s-%d", secretName, i)
+		}
+
+		providerSpec, err := codec.EncodeProviderSpec(&cco.AWSProviderSpec{
+			StatementEntries: statementEntries(policy),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode provider spec: %w", err)
+		}
+
+		credentialsRequest := &cco.CredentialsRequest{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: cco.SchemeGroupVersion.String(),
+				Kind:       "CredentialsRequest",
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: opts.namespace,
+			},
+			Spec: cco.CredentialsRequestSpec{
+				ServiceAccountNames: []string{opts.serviceAccountName},
+				SecretRef: corev1.ObjectReference{
+					Name:      secretName,
+					Namespace: opts.secretNamespace,
+				},
+				ProviderSpec: providerSpec,
+			},
+		}
+
+		obj, err := runtime.DefaultUnstructuredConverter.ToUnstructured(credentialsRequest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert credentials request %q: %w", name, err)
+		}
+		// the status and the creation timestamp are set by the cluster
+		unstructured.RemoveNestedField(obj, "status")
+		unstructured.RemoveNestedField(obj, "metadata", "creationTimestamp")
+
+		manifest, err := yaml.Marshal(obj)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal credentials request %q: %w", name, err)
+		}
+		out.WriteString("---\n")
+		out.Write(manifest)
+	}
+	return out.Bytes(), nil
+}
+
+// generatePolicyJSON returns the JSON of the IAM policy. If the policy was split a JSON array of the policies is returned.
+func generatePolicyJSON(policies []iamPolicy) ([]byte, error) {
+	var (
+		out []byte
+		err error
+	)
+	if len(policies) == 1 {
+		out, err = json.MarshalIndent(policies[0], "", "  ")
+	} else {
+		out, err = json.MarshalIndent(policies, "", "  ")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal policy: %w", err)
+	}
+	return append(out, '\n'), nil
+}
+
+// statementEntries converts the statements of the policy into the CredentialsRequest statement entries.
+func statementEntries(policy iamPolicy) []cco.StatementEntry {
+	var entries []cco.StatementEntry
+	for _, statement := range policy.Statement {
+		for _, resource := range statement.Resource {
+			entry := cco.StatementEntry{
+				Effect:   statement.Effect,
+				Action:   statement.Action,
+				Resource: resource,
+			}
+			if len(statement.Condition) > 0 {
+				entry.PolicyCondition = make(cco.IAMPolicyCondition, len(statement.Condition))
+				for operator, keyValues := range statement.Condition {
+					entry.PolicyCondition[operator] = make(cco.IAMPolicyConditionKeyValue, len(keyValues))
+					for key, value := range keyValues {
+						if len(value) == 1 {
+							entry.PolicyCondition[operator][key] = value[0]
+						} else {
+							entry.PolicyCondition[operator][key] = []string(value)
+						}
+					}
+				}
+			}
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+var testCredentialsRequestOptions = credentialsRequestOptions{
+	name:               "aws-load-balancer-controller-cluster",
+	namespace:          "openshift-cloud-credential-operator",
+	secretName:         "aws-load-balancer-controller-credentialsrequest-cluster",
+	secretNamespace:    "aws-load-balancer-operator",
+	serviceAccountName: "aws-load-balancer-controller-cluster",
+}
+
+func TestGenerateIAMPolicy(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		opts   generateOptions
+		golden string
+	}{
+		{
+			name:   "go, minified",
+			opts:   generateOptions{pkg: "test", outputFormat: goOutputFormat, maxPolicySize: 2048},
+			golden: "minified.go.golden",
+		},
+		{
+			name:   "go, preserved statements",
+			opts:   generateOptions{pkg: "test", outputFormat: goOutputFormat, preserveStatements: true, maxPolicySize: 2048},
+			golden: "preserved.go.golden",
+		},
+		{
+			name:   "credentials request, minified",
+			opts:   generateOptions{outputFormat: credentialsRequestOutputFormat, maxPolicySize: 2048, credentialsRequest: testCredentialsRequestOptions},
+			golden: "minified-credentialsrequest.yaml.golden",
+		},
+		{
+			name:   "credentials request, preserved statements",
+			opts:   generateOptions{outputFormat: credentialsRequestOutputFormat, preserveStatements: true, maxPolicySize: 2048, credentialsRequest: testCredentialsRequestOptions},
+			golden: "preserved-credentialsrequest.yaml.golden",
+		},
+		{
+			name:   "credentials request, split policy",
+			opts:   generateOptions{outputFormat: credentialsRequestOutputFormat, preserveStatements: true, maxPolicySize: 512, credentialsRequest: testCredentialsRequestOptions},
+			golden: "split-credentialsrequest.yaml.golden",
+		},
+		{
+			name:   "policy, minified",
+			opts:   generateOptions{outputFormat: policyOutputFormat, maxPolicySize: 2048},
+			golden: "minified-policy.json.golden",
+		},
+		{
+			name:   "policy, preserved statements",
+			opts:   generateOptions{outputFormat: policyOutputFormat, preserveStatements: true, maxPolicySize: 2048},
+			golden: "preserved-policy.json.golden",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			output := filepath.Join(t.TempDir(), "output")
+			if err := generateIAMPolicy(filepath.Join("testdata", "iam-policy.json"), output, tc.opts); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			actual, err := os.ReadFile(output)
+			if err != nil {
+				t.Fatalf("failed to read output: %v", err)
+			}
+			golden := filepath.Join("testdata", tc.golden)
+			if *update {
+				if err := os.WriteFile(golden, actual, 0644); err != nil {
+					t.Fatalf("failed to update golden file: %v", err)
+				}
+			}
+			expected, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read golden file: %v", err)
+			}
+			if diff := cmp.Diff(string(expected), string(actual)); diff != "" {
+				t.Errorf("output differs from golden file %s, run the tests with -update to update it:\n%s", golden, diff)
+			}
+		})
+	}
+}
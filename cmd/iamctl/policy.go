@@ -1,13 +1,20 @@
 package main
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
 	"sort"
 	"strings"
-	"text/template"
+)
+
+const (
+	// rolePolicySizeLimit is the maximum size of the inline policies of an IAM role. The IAM roles are created for
+	// the CredentialsRequests on the clusters using AWS STS, it's the default maximum size of a policy document.
+	rolePolicySizeLimit = 10240
+	// userPolicySizeLimit is the maximum size of the inline policies of an IAM user. The IAM users are minted for the
+	// CredentialsRequests on the other clusters, only the minified policy fits into it.
+	userPolicySizeLimit = 2048
 )
 
 type iamPolicy struct {
@@ -15,10 +22,10 @@ type iamPolicy struct {
 	Statement []policyStatement `json:"Statement,omitempty"`
 }
 type policyStatement struct {
-	Effect    string              `json:"Effect,omitempty"`
-	Action    AWSValue            `json:"Action,omitempty"`
-	Resource  AWSValue            `json:"Resource,omitempty"`
-	Condition *iamPolicyCondition `json:"Condition,omitempty"`
+	Effect    string             `json:"Effect,omitempty"`
+	Action    AWSValue           `json:"Action,omitempty"`
+	Resource  AWSValue           `json:"Resource,omitempty"`
+	Condition iamPolicyCondition `json:"Condition,omitempty"`
 }
 
 type AWSValue []string
@@ -37,15 +44,23 @@ func (v *AWSValue) UnmarshalJSON(input []byte) error {
 			elements[i] = fmt.Sprintf("%s", it)
 		}
 	default:
-		return fmt.Errorf("unsupported type %t in list", item)
+		return fmt.Errorf("unsupported type %T in list", item)
 	}
 	*v = elements
 	return nil
 }
 
+// MarshalJSON writes a single value as a string like in the AWS policy JSON.
+func (v AWSValue) MarshalJSON() ([]byte, error) {
+	if len(v) == 1 {
+		return json.Marshal(v[0])
+	}
+	return json.Marshal([]string(v))
+}
+
 type iamPolicyCondition map[string]iamPolicyConditionKeyValue
 
-type iamPolicyConditionKeyValue map[string]string
+type iamPolicyConditionKeyValue map[string]AWSValue
 
 // compressionPrefixes defines a list of action prefixes in the policy
 // that are going to be compressed using wildcards.
@@ -54,46 +69,68 @@ var compressionPrefixes = map[string]string{
 	"elasticloadbalancing:Describe": "elasticloadbalancing:Describe*",
 }
 
-func generateIAMPolicy(inputFile, output, pkg string) {
-	tmpl, err := template.New("").Parse(filetemplate)
+// generateIAMPolicy writes the policy from the input file into the output file in the format specified by the options.
+func generateIAMPolicy(inputFile, output string, opts generateOptions) error {
+	out, err := renderIAMPolicy(inputFile, opts)
 	if err != nil {
-		panic(err)
+		return err
 	}
 
-	jsFs, err := os.ReadFile(inputFile)
+	err = os.WriteFile(output, out, 0644)
 	if err != nil {
-		panic(fmt.Errorf("failed to read input file %v", err))
+		return fmt.Errorf("failed to write output file %v", err)
 	}
+	return nil
+}
 
-	policy := iamPolicy{}
-
-	err = json.Unmarshal([]byte(jsFs), &policy)
+// renderIAMPolicy returns the policy from the input file in the format specified by the options.
+func renderIAMPolicy(inputFile string, opts generateOptions) ([]byte, error) {
+	policy, err := readPolicy(inputFile)
 	if err != nil {
-		panic(fmt.Errorf("failed to parse policy JSON %v", err))
+		return nil, err
 	}
 
-	// Minifying here as a workaround for current limitations
-	// in credential requests length (2048 max bytes).
-	miniPoliicy := minify(policy)
+	if opts.preserveStatements {
+		// Keeping the resources and conditions of every statement,
+		// the policy is split if it exceeds the size limit of an IAM role.
+		policy = preserve(policy)
+	} else {
+		// Minifying here as a workaround for the size limit of the
+		// policies of the IAM users minted for the credential requests.
+		policy = minify(policy)
+	}
 
-	opFs, err := os.Create(output)
+	policies, err := splitPolicy(policy, opts.maxPolicySize)
 	if err != nil {
-		panic(err)
+		return nil, fmt.Errorf("failed to split policy: %w", err)
+	}
+
+	switch opts.outputFormat {
+	case goOutputFormat:
+		return generateGo(policies, opts.pkg)
+	case credentialsRequestOutputFormat:
+		return generateCredentialsRequests(policies, opts.credentialsRequest)
+	case policyOutputFormat:
+		return generatePolicyJSON(policies)
+	default:
+		return nil, fmt.Errorf("unsupported output format %q, supported formats are %v", opts.outputFormat, outputFormats)
 	}
+}
+
+// readPolicy reads the IAM policy from the JSON file.
+func readPolicy(file string) (iamPolicy, error) {
+	policy := iamPolicy{}
 
-	var in bytes.Buffer
-	err = tmpl.Execute(&in, struct {
-		Package   string
-		Statement policyStatement
-	}{Package: pkg, Statement: miniPoliicy.Statement[0]})
+	jsFs, err := os.ReadFile(file)
 	if err != nil {
-		panic(err)
+		return policy, fmt.Errorf("failed to read input file %v", err)
 	}
 
-	_, err = in.WriteTo(opFs)
+	err = json.Unmarshal(jsFs, &policy)
 	if err != nil {
-		panic(err)
+		return policy, fmt.Errorf("failed to parse policy JSON %v", err)
 	}
+	return policy, nil
 }
 
 // Minify replaces strict actions allowed across all Amazon Resource Names (ARNs)
@@ -139,3 +176,126 @@ func minify(policy iamPolicy) iamPolicy {
 	}
 	return miniPolicy
 }
+
+// preserve keeps the resources and the conditions of the statements in the policy. The statements
+// with multiple resources are split into a statement per resource, as the statement entry of a
+// CredentialsRequest has a single resource. The statements with the same effect, resource and
+// condition are merged and their actions are deduplicated and sorted.
+func preserve(policy iamPolicy) iamPolicy {
+	var (
+		preserved = iamPolicy{Version: policy.Version}
+		indexes   = make(map[string]int)
+		actions   []map[string]bool
+	)
+	for _, statement := range policy.Statement {
+		for _, resource := range statement.Resource {
+			// the JSON of a map is sorted by its keys so it can be used to compare the conditions
+			condition, _ := json.Marshal(statement.Condition)
+			key := strings.Join([]string{statement.Effect, resource, string(condition)}, "|")
+			i, ok := indexes[key]
+			if !ok {
+				i = len(preserved.Statement)
+				indexes[key] = i
+				preserved.Statement = append(preserved.Statement, policyStatement{
+					Effect:    statement.Effect,
+					Resource:  AWSValue{resource},
+					Condition: statement.Condition,
+				})
+				actions = append(actions, make(map[string]bool))
+			}
+			for _, action := range statement.Action {
+				actions[i][action] = true
+			}
+		}
+	}
+	for i := range preserved.Statement {
+		preserved.Statement[i].Action = sortedKeys(actions[i])
+	}
+	return preserved
+}
+
+// splitPolicy splits the policy into multiple policies so that the JSON of each
+// policy doesn't exceed maxSize bytes. The statements whose actions don't fit into
+// a single policy are split into multiple statements. The policy is not split when
+// maxSize is not positive.
+func splitPolicy(policy iamPolicy, maxSize int) ([]iamPolicy, error) {
+	if maxSize <= 0 {
+		return []iamPolicy{policy}, nil
+	}
+
+	var statements []policyStatement
+	for _, statement := range policy.Statement {
+		split, err := splitStatement(policy.Version, statement, maxSize)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, split...)
+	}
+
+	var policies []iamPolicy
+	current := iamPolicy{Version: policy.Version}
+	for _, statement := range statements {
+		candidate := iamPolicy{Version: policy.Version, Statement: append(append([]policyStatement{}, current.Statement...), statement)}
+		if policySize(candidate) > maxSize && len(current.Statement) > 0 {
+			policies = append(policies, current)
+			candidate = iamPolicy{Version: policy.Version, Statement: []policyStatement{statement}}
+		}
+		current = candidate
+	}
+	if len(current.Statement) > 0 {
+		policies = append(policies, current)
+	}
+	return policies, nil
+}
+
+// splitStatement splits the actions of the statement in halves until each statement
+// fits into a policy of maxSize bytes.
+func splitStatement(version string, statement policyStatement, maxSize int) ([]policyStatement, error) {
+	if policySize(iamPolicy{Version: version, Statement: []policyStatement{statement}}) <= maxSize {
+		return []policyStatement{statement}, nil
+	}
+	if len(statement.Action) <= 1 {
+		return nil, fmt.Errorf("statement with actions %v on resource %v exceeds the maximum policy size of %d bytes", statement.Action, statement.Resource, maxSize)
+	}
+	half := len(statement.Action) / 2
+	first, second := statement, statement
+	first.Action = statement.Action[:half]
+	second.Action = statement.Action[half:]
+
+	var statements []policyStatement
+	for _, s := range []policyStatement{first, second} {
+		split, err := splitStatement(version, s, maxSize)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, split...)
+	}
+	return statements, nil
+}
+
+// policySize returns the size of the policy JSON without whitespaces, which is how AWS counts the policy size.
+func policySize(policy iamPolicy) int {
+	js, _ := json.Marshal(policy)
+	return len(js)
+}
+
+// goValue returns the Go literal of a condition value.
+func goValue(v AWSValue) string {
+	if len(v) == 1 {
+		return fmt.Sprintf("%q", v[0])
+	}
+	quoted := make([]string, len(v))
+	for i, e := range v {
+		quoted[i] = fmt.Sprintf("%q", e)
+	}
+	return fmt.Sprintf("[]string{%s}", strings.Join(quoted, ", "))
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
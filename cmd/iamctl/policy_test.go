@@ -0,0 +1,197 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestPreserve(t *testing.T) {
+	policy := iamPolicy{
+		Version: "2012-10-17",
+		Statement: []policyStatement{
+			{
+				Effect:   "Allow",
+				Action:   AWSValue{"ec2:DescribeVpcs", "ec2:DescribeSubnets"},
+				Resource: AWSValue{"*"},
+			},
+			{
+				Effect:   "Allow",
+				Action:   AWSValue{"ec2:CreateTags"},
+				Resource: AWSValue{"arn:aws:ec2:*:*:security-group/*"},
+				Condition: iamPolicyCondition{
+					"Null": iamPolicyConditionKeyValue{"aws:RequestTag/elbv2.k8s.aws/cluster": AWSValue{"false"}},
+				},
+			},
+			{
+				Effect:   "Allow",
+				Action:   AWSValue{"elasticloadbalancing:AddTags", "elasticloadbalancing:RemoveTags"},
+				Resource: AWSValue{"arn:aws:elasticloadbalancing:*:*:targetgroup/*/*", "arn:aws:elasticloadbalancing:*:*:loadbalancer/app/*/*"},
+			},
+			{
+				Effect:   "Allow",
+				Action:   AWSValue{"ec2:DescribeSubnets", "acm:ListCertificates"},
+				Resource: AWSValue{"*"},
+			},
+		},
+	}
+	expected := iamPolicy{
+		Version: "2012-10-17",
+		Statement: []policyStatement{
+			{
+				Effect:   "Allow",
+				Action:   AWSValue{"acm:ListCertificates", "ec2:DescribeSubnets", "ec2:DescribeVpcs"},
+				Resource: AWSValue{"*"},
+			},
+			{
+				Effect:   "Allow",
+				Action:   AWSValue{"ec2:CreateTags"},
+				Resource: AWSValue{"arn:aws:ec2:*:*:security-group/*"},
+				Condition: iamPolicyCondition{
+					"Null": iamPolicyConditionKeyValue{"aws:RequestTag/elbv2.k8s.aws/cluster": AWSValue{"false"}},
+				},
+			},
+			{
+				Effect:   "Allow",
+				Action:   AWSValue{"elasticloadbalancing:AddTags", "elasticloadbalancing:RemoveTags"},
+				Resource: AWSValue{"arn:aws:elasticloadbalancing:*:*:targetgroup/*/*"},
+			},
+			{
+				Effect:   "Allow",
+				Action:   AWSValue{"elasticloadbalancing:AddTags", "elasticloadbalancing:RemoveTags"},
+				Resource: AWSValue{"arn:aws:elasticloadbalancing:*:*:loadbalancer/app/*/*"},
+			},
+		},
+	}
+	if diff := cmp.Diff(expected, preserve(policy)); diff != "" {
+		t.Errorf("unexpected preserved policy:\n%s", diff)
+	}
+}
+
+func TestSplitPolicy(t *testing.T) {
+	policy := iamPolicy{
+		Version: "2012-10-17",
+		Statement: []policyStatement{
+			{
+				Effect:   "Allow",
+				Action:   AWSValue{"ec2:DescribeSubnets", "ec2:DescribeVpcs", "ec2:DescribeTags", "ec2:DescribeInstances"},
+				Resource: AWSValue{"*"},
+			},
+			{
+				Effect:   "Allow",
+				Action:   AWSValue{"ec2:CreateTags"},
+				Resource: AWSValue{"arn:aws:ec2:*:*:security-group/*"},
+			},
+		},
+	}
+	for _, tc := range []struct {
+		name               string
+		maxSize            int
+		expectedPolicies   int
+		expectedStatements int
+		expectError        bool
+	}{
+		{
+			name:               "split disabled",
+			maxSize:            0,
+			expectedPolicies:   1,
+			expectedStatements: 2,
+		},
+		{
+			name:               "policy within the limit",
+			maxSize:            2048,
+			expectedPolicies:   1,
+			expectedStatements: 2,
+		},
+		{
+			name:               "statements split into policies",
+			maxSize:            policySize(iamPolicy{Version: policy.Version, Statement: policy.Statement[:1]}),
+			expectedPolicies:   2,
+			expectedStatements: 2,
+		},
+		{
+			name:               "actions split into statements",
+			maxSize:            150,
+			expectedPolicies:   3,
+			expectedStatements: 3,
+		},
+		{
+			name:        "single action exceeds the limit",
+			maxSize:     50,
+			expectError: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			policies, err := splitPolicy(policy, tc.maxSize)
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(policies) != tc.expectedPolicies {
+				t.Errorf("expected %d policies, got %d", tc.expectedPolicies, len(policies))
+			}
+			var statements int
+			actions := make(map[string]bool)
+			for _, p := range policies {
+				if tc.maxSize > 0 && policySize(p) > tc.maxSize {
+					t.Errorf("policy size %d exceeds the limit %d", policySize(p), tc.maxSize)
+				}
+				statements += len(p.Statement)
+				for _, s := range p.Statement {
+					for _, a := range s.Action {
+						actions[a] = true
+					}
+				}
+			}
+			if statements != tc.expectedStatements {
+				t.Errorf("expected %d statements, got %d", tc.expectedStatements, statements)
+			}
+			if len(actions) != 5 {
+				t.Errorf("expected all 5 actions to be kept, got %d", len(actions))
+			}
+		})
+	}
+}
+
+// TestShippedPolicy checks that the policy of the controller shipped in the assets fits into a single policy document
+// with the default size limit when its statements are preserved, and into the policy of an IAM user when minified.
+func TestShippedPolicy(t *testing.T) {
+	policy, err := readPolicy(filepath.Join("..", "..", "assets", "iam-policy.json"))
+	if err != nil {
+		t.Fatalf("failed to read policy: %v", err)
+	}
+
+	preserved, err := splitPolicy(preserve(policy), rolePolicySizeLimit)
+	if err != nil {
+		t.Fatalf("failed to split preserved policy: %v", err)
+	}
+	if len(preserved) != 1 {
+		t.Fatalf("expected the preserved policy to fit into a single document, got %d", len(preserved))
+	}
+	conditions := 0
+	for _, statement := range preserved[0].Statement {
+		if len(statement.Condition) > 0 {
+			conditions++
+		}
+	}
+	if conditions == 0 {
+		t.Errorf("expected the preserved policy to keep the conditions of the statements")
+	}
+	if _, err := renderIAMPolicy(filepath.Join("..", "..", "assets", "iam-policy.json"), generateOptions{pkg: "test", outputFormat: goOutputFormat, preserveStatements: true, maxPolicySize: rolePolicySizeLimit}); err != nil {
+		t.Errorf("unexpected error generating the go output of the preserved policy: %v", err)
+	}
+
+	minified, err := splitPolicy(minify(policy), userPolicySizeLimit)
+	if err != nil {
+		t.Fatalf("failed to split minified policy: %v", err)
+	}
+	if len(minified) != 1 {
+		t.Errorf("expected the minified policy to fit into the policy of an IAM user, got %d documents", len(minified))
+	}
+}
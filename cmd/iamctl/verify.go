@@ -0,0 +1,43 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+// verifyCmd fails if the output file is not up to date with the input policy.
+var verifyCmd = &cobra.Command{
+	Use:   "verify",
+	Short: "Verifies that the generated output file matches the input policy JSON.",
+	Long: `Verifies that the output file is the one which would be generated from the input policy JSON
+	with the same flags. It fails if the output file is stale and has to be regenerated.`,
+	SilenceUsage: true,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return verifyIAMPolicy(inputFile, outputFile, currentGenerateOptions())
+	},
+}
+
+// verifyIAMPolicy returns an error if the output file differs from the output generated from the input file.
+func verifyIAMPolicy(inputFile, output string, opts generateOptions) error {
+	expected, err := renderIAMPolicy(inputFile, opts)
+	if err != nil {
+		return err
+	}
+	return verifyOutput(output, expected, inputFile)
+}
+
+// verifyOutput returns an error if the output file differs from the expected output generated from the source.
+func verifyOutput(output string, expected []byte, source string) error {
+	current, err := os.ReadFile(output)
+	if err != nil {
+		return fmt.Errorf("failed to read output file %v", err)
+	}
+
+	if !bytes.Equal(expected, current) {
+		return fmt.Errorf("%s is not up to date with %s, regenerate it with iamctl or make generate", output, source)
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestVerifyIAMPolicy(t *testing.T) {
+	opts := generateOptions{pkg: "test", outputFormat: goOutputFormat, maxPolicySize: 2048}
+	for _, tc := range []struct {
+		name        string
+		output      string
+		expectError bool
+	}{
+		{
+			name:   "output up to date",
+			output: filepath.Join("testdata", "minified.go.golden"),
+		},
+		{
+			name:        "output stale",
+			output:      filepath.Join("testdata", "preserved.go.golden"),
+			expectError: true,
+		},
+		{
+			name:        "output missing",
+			output:      filepath.Join(t.TempDir(), "missing.go"),
+			expectError: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := verifyIAMPolicy(filepath.Join("testdata", "iam-policy.json"), tc.output, opts)
+			if tc.expectError && err == nil {
+				t.Fatalf("expected error, got nil")
+			}
+			if !tc.expectError && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestGenerateIAMPolicyErrors(t *testing.T) {
+	invalid := filepath.Join(t.TempDir(), "invalid.json")
+	if err := os.WriteFile(invalid, []byte("{"), 0644); err != nil {
+		t.Fatalf("failed to write invalid policy: %v", err)
+	}
+	for _, tc := range []struct {
+		name      string
+		inputFile string
+		opts      generateOptions
+	}{
+		{
+			name:      "missing input file",
+			inputFile: filepath.Join(t.TempDir(), "missing.json"),
+			opts:      generateOptions{outputFormat: goOutputFormat},
+		},
+		{
+			name:      "invalid input file",
+			inputFile: invalid,
+			opts:      generateOptions{outputFormat: goOutputFormat},
+		},
+		{
+			name:      "unsupported output format",
+			inputFile: filepath.Join("testdata", "iam-policy.json"),
+			opts:      generateOptions{outputFormat: "xml"},
+		},
+		{
+			name:      "statement exceeds the policy size",
+			inputFile: filepath.Join("testdata", "iam-policy.json"),
+			opts:      generateOptions{outputFormat: goOutputFormat, maxPolicySize: 10},
+		},
+		{
+			name:      "go output of a split policy",
+			inputFile: filepath.Join("testdata", "iam-policy.json"),
+			opts:      generateOptions{outputFormat: goOutputFormat, preserveStatements: true, maxPolicySize: 512},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := generateIAMPolicy(tc.inputFile, filepath.Join(t.TempDir(), "output"), tc.opts); err == nil {
+				t.Fatalf("expected error, got nil")
+			}
+		})
+	}
+}
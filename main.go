@@ -17,9 +17,7 @@ limitations under the License.
 package main
 
 import (
-	"context"
 	"flag"
-	"fmt"
 	"os"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
@@ -29,30 +27,22 @@ import (
 	arv1 "k8s.io/api/admissionregistration/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/runtime"
-	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
-	"k8s.io/client-go/rest"
 
 	configv1 "github.com/openshift/api/config/v1"
 	cco "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/cache"
-	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 
 	networkingolmv1alpha1 "github.com/openshift/aws-load-balancer-operator/api/v1alpha1"
-	"github.com/openshift/aws-load-balancer-operator/pkg/aws"
 	"github.com/openshift/aws-load-balancer-operator/pkg/controllers/awsloadbalancercontroller"
 	//+kubebuilder:scaffold:imports
 )
 
-const (
-	clusterInfrastructureName = "cluster"
-)
-
 var (
 	scheme   = runtime.NewScheme()
 	setupLog = ctrl.Log.WithName("setup")
@@ -71,6 +61,7 @@ func init() {
 	utilruntime.Must(cco.Install(scheme))
 	utilruntime.Must(networkingv1.AddToScheme(scheme))
 	utilruntime.Must(arv1.AddToScheme(scheme))
+	utilruntime.Must(monitoringv1.AddToScheme(scheme))
 }
 
 func main() {
@@ -79,6 +70,9 @@ func main() {
 	var probeAddr string
 	var namespace string
 	var image string
+	var kubeRBACProxyImage string
+	var trustedCAConfigMap string
+	var webhookDryRun bool
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
@@ -86,6 +80,9 @@ func main() {
 			"Enabling this will ensure there is only one active controller manager.")
 	flag.StringVar(&namespace, "namespace", "aws-load-balancer-operator", "The namespace where operands should be installed")
 	flag.StringVar(&image, "image", "quay.io/aws-load-balancer-operator/aws-load-balancer-controller:latest", "The image to be used for the operand")
+	flag.StringVar(&kubeRBACProxyImage, "kube-rbac-proxy-image", "registry.redhat.io/openshift4/ose-kube-rbac-proxy:v4.10", "The image of the sidecar which serves the metrics of the operand over TLS")
+	flag.BoolVar(&webhookDryRun, "webhook-dry-run", false, "Dry-run the admission of an Ingress to check that the webhooks of the operand are reachable")
+	flag.StringVar(&trustedCAConfigMap, "trusted-ca-configmap", "", "The name of the ConfigMap in the operator namespace with the trusted CA bundle used for the AWS API calls")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -101,57 +98,44 @@ func main() {
 		HealthProbeBindAddress: probeAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "7de51cf3.openshift.io",
-		// The default cached client does not always return an updated value after write operations. So we use a non-cache client
-		// https://pkg.go.dev/sigs.k8s.io/controller-runtime#hdr-Clients_and_Caches
-		NewClient: func(_ cache.Cache, config *rest.Config, options client.Options, _ ...client.Object) (client.Client, error) {
-			return client.New(config, options)
-		},
-		Namespace: namespace,
+		// the client reads from the cache which only holds the resources of the operator namespace and of the operand,
+		// the resources which may be stale after a write are read with the API reader
+		NewCache:              awsloadbalancercontroller.NewCache(namespace),
+		ClientDisableCacheFor: awsloadbalancercontroller.UncachedObjects(),
 	})
 	if err != nil {
 		setupLog.Error(err, "unable to start manager")
 		os.Exit(1)
 	}
 
-	// get the cluster details
-	clusterName, awsRegion, err := clusterInfo(context.TODO(), mgr.GetClient())
-	if err != nil {
-		setupLog.Error(err, "failed to get cluster details")
-		os.Exit(1)
-	}
-
-	// make and aws.EC2Client
-	ec2Client, err := aws.NewClient(context.TODO(), awsRegion)
-	if err != nil {
-		setupLog.Error(err, "failed to make aws client")
-		os.Exit(1)
-	}
-
-	// get the VPC ID where the cluster is running
-	vpcID, err := aws.GetVPCId(context.TODO(), ec2Client, clusterName)
-	if err != nil {
-		setupLog.Error(err, "failed to get VPC ID")
-		os.Exit(1)
-	}
-
-	if err != nil {
-		setupLog.Error(err, "unable to make EC2 Client")
-		os.Exit(1)
+	// the cluster details are discovered and the AWS clients are made by the reconciler, which retries with backoff
+	// and reports the failures in the status, so that the operator doesn't crash loop on platform errors
+	reconciler := &awsloadbalancercontroller.AWSLoadBalancerControllerReconciler{
+		Client:    mgr.GetClient(),
+		Scheme:    mgr.GetScheme(),
+		APIReader: mgr.GetAPIReader(),
+		Namespace: namespace,
+		Image:     image,
+		// the metrics of the operand are scraped through the sidecar which authorizes the scrapes
+		KubeRBACProxyImage: kubeRBACProxyImage,
+		WebhookDryRun:      webhookDryRun,
+		DiscoverPlatform: awsloadbalancercontroller.NewPlatformDiscovery(mgr.GetClient(), awsloadbalancercontroller.PlatformDiscoveryOptions{
+			Namespace:          namespace,
+			TrustedCAConfigMap: trustedCAConfigMap,
+			CredentialsFile:    os.Getenv("AWS_SHARED_CREDENTIALS_FILE"),
+		}),
 	}
-
-	if err = (&awsloadbalancercontroller.AWSLoadBalancerControllerReconciler{
-		Client:      mgr.GetClient(),
-		Scheme:      mgr.GetScheme(),
-		EC2Client:   ec2Client,
-		Namespace:   namespace,
-		Image:       image,
-		VPCID:       vpcID,
-		ClusterName: clusterName,
-		AWSRegion:   awsRegion,
-	}).SetupWithManager(mgr); err != nil {
+	if err = reconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "AWSLoadBalancerController")
 		os.Exit(1)
 	}
+	// the webhook can be disabled when running the operator locally without the serving certificates
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err = (&networkingolmv1alpha1.AWSLoadBalancerController{}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "AWSLoadBalancerController")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
@@ -162,6 +146,10 @@ func main() {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
+	if err := mgr.AddReadyzCheck("discovery", reconciler.DiscoveryReadyCheck); err != nil {
+		setupLog.Error(err, "unable to set up discovery ready check")
+		os.Exit(1)
+	}
 
 	setupLog.Info("starting manager")
 	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
@@ -169,28 +157,3 @@ func main() {
 		os.Exit(1)
 	}
 }
-
-func clusterInfo(ctx context.Context, client client.Client) (clusterName, awsRegion string, err error) {
-	var infra configv1.Infrastructure
-	infraKey := types.NamespacedName{
-		Name: clusterInfrastructureName,
-	}
-	err = client.Get(ctx, infraKey, &infra)
-	if err != nil {
-		err = fmt.Errorf("failed to get Infrastructure %q: %w", clusterInfrastructureName, err)
-		return
-	}
-
-	if infra.Status.InfrastructureName == "" {
-		err = fmt.Errorf("could not get AWS region from Infrastructure %q status", clusterInfrastructureName)
-		return
-	}
-	clusterName = infra.Status.InfrastructureName
-
-	if infra.Status.PlatformStatus == nil || infra.Status.PlatformStatus.AWS == nil || infra.Status.PlatformStatus.AWS.Region == "" {
-		err = fmt.Errorf("could not get AWS region from Infrastructure %q status", clusterInfrastructureName)
-		return
-	}
-	awsRegion = infra.Status.PlatformStatus.AWS.Region
-	return
-}
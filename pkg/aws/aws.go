@@ -3,16 +3,29 @@ package aws
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/smithy-go/middleware"
 )
 
 const (
 	clusterTagKey    = "kubernetes.io/cluster/%s"
 	tagKeyFilterName = "tag-key"
+	tagFilterName    = "tag:%s"
+	vpcIDFilterName  = "vpc-id"
+	// DefaultPartition is the partition of the commercial AWS regions
+	DefaultPartition = "aws"
+	// EC2ServiceEndpointName is the name of the EC2 service in the custom service endpoints
+	EC2ServiceEndpointName = "ec2"
+	// IAMServiceEndpointName is the name of the IAM service in the custom service endpoints
+	IAMServiceEndpointName = "iam"
+	// STSServiceEndpointName is the name of the STS service in the custom service endpoints
+	STSServiceEndpointName = "sts"
 )
 
 // VPCClient can be used to query VPCs
@@ -23,43 +36,137 @@ type VPCClient interface {
 // SubnetClient can be used to query subnets and perform tagging operations
 type SubnetClient interface {
 	DescribeSubnets(context.Context, *ec2.DescribeSubnetsInput, ...func(*ec2.Options)) (*ec2.DescribeSubnetsOutput, error)
+	// the operator only tags subnets, the ARN matches the subnets of all the partitions as the operator's own
+	// CredentialsRequest is not rewritten for the partition of the cluster
+	//+iamctl:resource=arn:*:ec2:*:*:subnet/*
 	CreateTags(context.Context, *ec2.CreateTagsInput, ...func(*ec2.Options)) (*ec2.CreateTagsOutput, error)
+	//+iamctl:resource=arn:*:ec2:*:*:subnet/*
 	DeleteTags(context.Context, *ec2.DeleteTagsInput, ...func(*ec2.Options)) (*ec2.DeleteTagsOutput, error)
 }
 
-// EC2Client has a VPCClient and SubnetClient
+// EC2Client has a VPCClient and SubnetClient.
+// The IAM actions of the methods of the client interfaces in this package are allowed by
+// hack/operator-credentials-request.yaml which is generated by 'make generate'.
+// The action of a method is scoped to a resource with an iamctl:resource marker.
 type EC2Client interface {
 	VPCClient
 	SubnetClient
 }
 
-func NewClient(ctx context.Context, awsRegion string) (EC2Client, error) {
-	awsConfig, err := config.LoadDefaultConfig(ctx, config.WithRegion(awsRegion))
+// NewClient returns an EC2Client for the given region. The AWS API calls are made with the given HTTP client
+// when it's not nil, otherwise the SDK's default client is used. The service endpoints map the AWS service
+// names to the URLs which override the default endpoints of the region.
+func NewClient(ctx context.Context, awsRegion string, httpClient aws.HTTPClient, serviceEndpoints map[string]string) (EC2Client, error) {
+	awsConfig, err := loadConfig(ctx, awsRegion, httpClient)
 	if err != nil {
-		return nil, fmt.Errorf("unable to load AWS config: %w", err)
+		return nil, err
 	}
-	return ec2.NewFromConfig(awsConfig), nil
+
+	var ec2Opts []func(*ec2.Options)
+	if endpointURL, ok := serviceEndpoints[EC2ServiceEndpointName]; ok {
+		ec2Opts = append(ec2Opts, func(o *ec2.Options) {
+			o.EndpointResolver = ec2.EndpointResolverFromURL(endpointURL)
+		})
+	}
+	return ec2.NewFromConfig(awsConfig, ec2Opts...), nil
+}
+
+// loadConfig returns the AWS config for the given region with the credentials of the environment. The access keys of
+// the shared credentials file are reloaded when the file changes. The calls are retried with an adaptive backoff,
+// are limited by the call budget of their context and are recorded in the metrics. The AWS API calls are made with
+// the given HTTP client when it's not nil. The additional options are applied after the defaults.
+func loadConfig(ctx context.Context, awsRegion string, httpClient aws.HTTPClient, optFns ...func(*config.LoadOptions) error) (aws.Config, error) {
+	opts := []func(*config.LoadOptions) error{
+		config.WithRegion(awsRegion),
+		config.WithRetryer(newRetryer),
+		config.WithAPIOptions([]func(*middleware.Stack) error{addCallMiddlewares}),
+	}
+	if httpClient != nil {
+		opts = append(opts, config.WithHTTPClient(httpClient))
+	}
+	if provider, ok := sharedCredentialsFileProvider(); ok {
+		opts = append(opts, config.WithCredentialsProvider(provider))
+	}
+	awsConfig, err := config.LoadDefaultConfig(ctx, append(opts, optFns...)...)
+	if err != nil {
+		return aws.Config{}, fmt.Errorf("unable to load AWS config: %w", err)
+	}
+	return awsConfig, nil
+}
+
+// GetPartition returns the AWS partition of the given region. E.g. "aws-us-gov" for GovCloud regions or
+// "aws-cn" for China regions. The default partition is returned for regions which are unknown to the SDK.
+func GetPartition(awsRegion string) string {
+	endpoint, err := ec2.NewDefaultEndpointResolver().ResolveEndpoint(awsRegion, ec2.EndpointResolverOptions{})
+	if err != nil || endpoint.PartitionID == "" {
+		return DefaultPartition
+	}
+	return endpoint.PartitionID
 }
 
 // GetVPCId return the VPC ID of the cluster
-func GetVPCId(ctx context.Context, ec2Client EC2Client, clusterName string) (string, error) {
+func GetVPCId(ctx context.Context, ec2Client VPCClient, clusterName string) (string, error) {
 	infraTagKey := fmt.Sprintf(clusterTagKey, clusterName)
-	vpcs, err := ec2Client.DescribeVpcs(ctx, &ec2.DescribeVpcsInput{
-		Filters: []ec2types.Filter{
-			{
-				Name:   aws.String(tagKeyFilterName),
-				Values: []string{infraTagKey},
-			},
+	return findVPCId(ctx, ec2Client, []ec2types.Filter{
+		{
+			Name:   aws.String(tagKeyFilterName),
+			Values: []string{infraTagKey},
 		},
+	}, fmt.Sprintf("tag %q", infraTagKey))
+}
+
+// GetVPCIdByTags returns the ID of the only VPC which has all the given tags. A tag with an empty value matches
+// any value of the tag.
+func GetVPCIdByTags(ctx context.Context, ec2Client VPCClient, tags map[string]string) (string, error) {
+	if len(tags) == 0 {
+		return "", fmt.Errorf("no tags to select the VPC")
+	}
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var (
+		filters     []ec2types.Filter
+		description []string
+	)
+	for _, k := range keys {
+		if v := tags[k]; v != "" {
+			filters = append(filters, ec2types.Filter{Name: aws.String(fmt.Sprintf(tagFilterName, k)), Values: []string{v}})
+			description = append(description, fmt.Sprintf("%s=%s", k, v))
+		} else {
+			filters = append(filters, ec2types.Filter{Name: aws.String(tagKeyFilterName), Values: []string{k}})
+			description = append(description, k)
+		}
+	}
+	return findVPCId(ctx, ec2Client, filters, fmt.Sprintf("tags %q", strings.Join(description, ",")))
+}
+
+// ValidateVPCId returns an error if the VPC with the given ID doesn't exist.
+func ValidateVPCId(ctx context.Context, ec2Client VPCClient, vpcID string) error {
+	_, err := findVPCId(ctx, ec2Client, []ec2types.Filter{
+		{
+			Name:   aws.String(vpcIDFilterName),
+			Values: []string{vpcID},
+		},
+	}, fmt.Sprintf("ID %q", vpcID))
+	return err
+}
+
+// findVPCId returns the ID of the only VPC matching the filters. The description of the filters is used in the errors.
+func findVPCId(ctx context.Context, ec2Client VPCClient, filters []ec2types.Filter, description string) (string, error) {
+	vpcs, err := ec2Client.DescribeVpcs(ctx, &ec2.DescribeVpcsInput{
+		Filters: filters,
 	})
 	if err != nil {
-		return "", fmt.Errorf("failed to list VPC with tag %q: %w", infraTagKey, err)
+		return "", fmt.Errorf("failed to list VPC with %s: %w", description, err)
 	}
 	if len(vpcs.Vpcs) == 0 {
-		return "", fmt.Errorf("no VPC with tag %q found", infraTagKey)
+		return "", fmt.Errorf("no VPC with %s found", description)
 	}
 	if len(vpcs.Vpcs) > 1 {
-		return "", fmt.Errorf("multiple VPCs with tag %q found", infraTagKey)
+		return "", fmt.Errorf("multiple VPCs with %s found", description)
 	}
 	return aws.ToString(vpcs.Vpcs[0].VpcId), nil
 }
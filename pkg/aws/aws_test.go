@@ -8,6 +8,8 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
 )
 
 type testEC2Client struct {
@@ -98,3 +100,101 @@ func TestGetVPCId(t *testing.T) {
 		})
 	}
 }
+
+func TestGetPartition(t *testing.T) {
+	for _, tc := range []struct {
+		region            string
+		expectedPartition string
+	}{
+		{region: "us-east-1", expectedPartition: "aws"},
+		{region: "eu-west-3", expectedPartition: "aws"},
+		{region: "us-gov-west-1", expectedPartition: "aws-us-gov"},
+		{region: "cn-north-1", expectedPartition: "aws-cn"},
+		{region: "us-iso-east-1", expectedPartition: "aws-iso"},
+		{region: "us-isob-east-1", expectedPartition: "aws-iso-b"},
+		{region: "", expectedPartition: "aws"},
+	} {
+		t.Run(tc.region, func(t *testing.T) {
+			if partition := GetPartition(tc.region); partition != tc.expectedPartition {
+				t.Errorf("expected partition %q, got %q", tc.expectedPartition, partition)
+			}
+		})
+	}
+}
+
+// testFilterVPCClient returns the VPCs and records the filters of the query.
+type testFilterVPCClient struct {
+	vpcIDs  []string
+	filters []ec2types.Filter
+}
+
+func (c *testFilterVPCClient) DescribeVpcs(_ context.Context, input *ec2.DescribeVpcsInput, _ ...func(*ec2.Options)) (*ec2.DescribeVpcsOutput, error) {
+	c.filters = input.Filters
+	output := &ec2.DescribeVpcsOutput{}
+	for _, id := range c.vpcIDs {
+		output.Vpcs = append(output.Vpcs, ec2types.Vpc{VpcId: aws.String(id)})
+	}
+	return output, nil
+}
+
+func TestGetVPCIdByTags(t *testing.T) {
+	for _, tc := range []struct {
+		name            string
+		tags            map[string]string
+		vpcIDs          []string
+		expectedFilters []ec2types.Filter
+		expectedVPCID   string
+		expectedErr     string
+	}{
+		{
+			name:   "tag keys and values",
+			tags:   map[string]string{"network": "shared", "owner": ""},
+			vpcIDs: []string{"test-vpc"},
+			expectedFilters: []ec2types.Filter{
+				{Name: aws.String("tag:network"), Values: []string{"shared"}},
+				{Name: aws.String("tag-key"), Values: []string{"owner"}},
+			},
+			expectedVPCID: "test-vpc",
+		},
+		{
+			name:        "multiple matching vpc",
+			tags:        map[string]string{"network": "shared"},
+			vpcIDs:      []string{"test-vpc-1", "test-vpc-2"},
+			expectedErr: `multiple VPCs with tags "network=shared" found`,
+		},
+		{
+			name:        "no tags",
+			expectedErr: "no tags to select the VPC",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &testFilterVPCClient{vpcIDs: tc.vpcIDs}
+			vpcID, err := GetVPCIdByTags(context.Background(), client, tc.tags)
+			if tc.expectedErr != "" {
+				if err == nil || err.Error() != tc.expectedErr {
+					t.Fatalf("expected error %q, got %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error %v", err)
+			}
+			if vpcID != tc.expectedVPCID {
+				t.Errorf("expected VPC Id %q, got %q", tc.expectedVPCID, vpcID)
+			}
+			if diff := cmp.Diff(tc.expectedFilters, client.filters, cmpopts.IgnoreUnexported(ec2types.Filter{})); diff != "" {
+				t.Errorf("unexpected filters:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidateVPCId(t *testing.T) {
+	if err := ValidateVPCId(context.Background(), &testFilterVPCClient{vpcIDs: []string{"vpc-1"}}, "vpc-1"); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	expectedErr := `no VPC with ID "vpc-2" found`
+	if err := ValidateVPCId(context.Background(), &testFilterVPCClient{}, "vpc-2"); err == nil || err.Error() != expectedErr {
+		t.Errorf("expected error %q, got %v", expectedErr, err)
+	}
+}
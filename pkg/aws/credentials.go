@@ -0,0 +1,178 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
+)
+
+const (
+	// dryRunOperationErrorCode is returned by the EC2 DryRun calls which would have succeeded
+	dryRunOperationErrorCode = "DryRunOperation"
+	// unauthorizedOperationErrorCode is returned by the EC2 calls which are not allowed
+	unauthorizedOperationErrorCode = "UnauthorizedOperation"
+)
+
+// STSClient can be used to get the identity of the credentials.
+// It's called with the credentials of the operand, GetCallerIdentity doesn't need to be allowed by an IAM policy.
+// +iamctl:operand
+type STSClient interface {
+	GetCallerIdentity(context.Context, *sts.GetCallerIdentityInput, ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error)
+}
+
+// IAMClient can be used to simulate the IAM policies of a principal
+type IAMClient interface {
+	SimulatePrincipalPolicy(context.Context, *iam.SimulatePrincipalPolicyInput, ...func(*iam.Options)) (*iam.SimulatePrincipalPolicyOutput, error)
+}
+
+// NewIAMClient returns an IAMClient with the credentials of the environment. The HTTP client and the service endpoints
+// are used in the same way as in NewClient.
+func NewIAMClient(ctx context.Context, awsRegion string, httpClient aws.HTTPClient, serviceEndpoints map[string]string) (IAMClient, error) {
+	awsConfig, err := loadConfig(ctx, awsRegion, httpClient)
+	if err != nil {
+		return nil, err
+	}
+
+	var iamOpts []func(*iam.Options)
+	if endpointURL, ok := serviceEndpoints[IAMServiceEndpointName]; ok {
+		iamOpts = append(iamOpts, func(o *iam.Options) {
+			o.EndpointResolver = iam.EndpointResolverFromURL(endpointURL)
+		})
+	}
+	return iam.NewFromConfig(awsConfig, iamOpts...), nil
+}
+
+// NewSTSClient returns an STSClient with the given static credentials, e.g. the ones minted for the operand.
+// The HTTP client and the service endpoints are used in the same way as in NewClient.
+func NewSTSClient(ctx context.Context, awsRegion string, httpClient aws.HTTPClient, serviceEndpoints map[string]string, accessKeyID, secretAccessKey string) (STSClient, error) {
+	awsConfig, err := loadConfig(ctx, awsRegion, httpClient, config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(accessKeyID, secretAccessKey, "")))
+	if err != nil {
+		return nil, err
+	}
+
+	var stsOpts []func(*sts.Options)
+	if endpointURL, ok := serviceEndpoints[STSServiceEndpointName]; ok {
+		stsOpts = append(stsOpts, func(o *sts.Options) {
+			o.EndpointResolver = sts.EndpointResolverFromURL(endpointURL)
+		})
+	}
+	return sts.NewFromConfig(awsConfig, stsOpts...), nil
+}
+
+// MissingTaggingPermissions returns the tagging actions which the credentials of the client are not allowed to
+// perform on the given subnet. The permissions are checked with DryRun calls, so the subnet tags are not changed.
+func MissingTaggingPermissions(ctx context.Context, client SubnetClient, subnetID, tagKey string) ([]string, error) {
+	var missing []string
+
+	_, err := client.CreateTags(ctx, &ec2.CreateTagsInput{
+		DryRun:    aws.Bool(true),
+		Resources: []string{subnetID},
+		Tags:      []ec2types.Tag{{Key: aws.String(tagKey), Value: aws.String("")}},
+	})
+	allowed, err := dryRunAllowed(err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check the permission to tag subnet %s: %w", subnetID, err)
+	}
+	if !allowed {
+		missing = append(missing, "ec2:CreateTags")
+	}
+
+	_, err = client.DeleteTags(ctx, &ec2.DeleteTagsInput{
+		DryRun:    aws.Bool(true),
+		Resources: []string{subnetID},
+		Tags:      []ec2types.Tag{{Key: aws.String(tagKey)}},
+	})
+	allowed, err = dryRunAllowed(err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check the permission to untag subnet %s: %w", subnetID, err)
+	}
+	if !allowed {
+		missing = append(missing, "ec2:DeleteTags")
+	}
+	return missing, nil
+}
+
+// dryRunAllowed returns true if the error of an EC2 DryRun call reports that the call would have succeeded,
+// and false if the call is not allowed. Any other error is returned.
+func dryRunAllowed(err error) (bool, error) {
+	if err == nil {
+		// a DryRun call always returns an error with the result of the check
+		return false, fmt.Errorf("DryRun call succeeded without reporting the result of the check")
+	}
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case dryRunOperationErrorCode:
+			return true, nil
+		case unauthorizedOperationErrorCode:
+			return false, nil
+		}
+	}
+	return false, err
+}
+
+// GetCallerPrincipalARN returns the ARN of the IAM principal of the client credentials. The ARN of an assumed
+// role session is converted into the ARN of the role so that it can be used to simulate the policies of the role.
+func GetCallerPrincipalARN(ctx context.Context, client STSClient) (string, error) {
+	identity, err := client.GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get caller identity: %w", err)
+	}
+	return principalARN(aws.ToString(identity.Arn)), nil
+}
+
+// principalARN converts arn:<partition>:sts::<account>:assumed-role/<role>/<session> into
+// arn:<partition>:iam::<account>:role/<role>. Other ARNs are returned as is.
+func principalARN(arn string) string {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) != 6 || parts[2] != "sts" || !strings.HasPrefix(parts[5], "assumed-role/") {
+		return arn
+	}
+	role := strings.Split(strings.TrimPrefix(parts[5], "assumed-role/"), "/")[0]
+	return fmt.Sprintf("arn:%s:iam::%s:role/%s", parts[1], parts[4], role)
+}
+
+// MissingActions returns the actions which the IAM policies of the principal don't allow on all resources.
+// The actions with wildcards can't be simulated and are ignored.
+func MissingActions(ctx context.Context, client IAMClient, principalARN string, actions []string) ([]string, error) {
+	var actionNames []string
+	for _, action := range actions {
+		if !strings.Contains(action, "*") {
+			actionNames = append(actionNames, action)
+		}
+	}
+	if len(actionNames) == 0 {
+		return nil, nil
+	}
+
+	paginator := iam.NewSimulatePrincipalPolicyPaginator(client, &iam.SimulatePrincipalPolicyInput{
+		PolicySourceArn: aws.String(principalARN),
+		ActionNames:     actionNames,
+	})
+	var missing []string
+	for paginator.HasMorePages() {
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to simulate the policies of %s: %w", principalARN, err)
+		}
+		for _, result := range page.EvaluationResults {
+			if result.EvalDecision != iamtypes.PolicyEvaluationDecisionTypeAllowed {
+				missing = append(missing, aws.ToString(result.EvalActionName))
+			}
+		}
+	}
+	sort.Strings(missing)
+	return missing, nil
+}
@@ -0,0 +1,177 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
+	"github.com/google/go-cmp/cmp"
+)
+
+type testDryRunClient struct {
+	SubnetClient
+	createTagsErr error
+	deleteTagsErr error
+}
+
+func (c *testDryRunClient) CreateTags(_ context.Context, input *ec2.CreateTagsInput, _ ...func(*ec2.Options)) (*ec2.CreateTagsOutput, error) {
+	if !aws.ToBool(input.DryRun) {
+		return nil, errors.New("CreateTags called without DryRun")
+	}
+	return nil, c.createTagsErr
+}
+
+func (c *testDryRunClient) DeleteTags(_ context.Context, input *ec2.DeleteTagsInput, _ ...func(*ec2.Options)) (*ec2.DeleteTagsOutput, error) {
+	if !aws.ToBool(input.DryRun) {
+		return nil, errors.New("DeleteTags called without DryRun")
+	}
+	return nil, c.deleteTagsErr
+}
+
+var (
+	dryRunOperationErr       = &smithy.GenericAPIError{Code: dryRunOperationErrorCode}
+	unauthorizedOperationErr = &smithy.GenericAPIError{Code: unauthorizedOperationErrorCode}
+)
+
+func TestMissingTaggingPermissions(t *testing.T) {
+	for _, tc := range []struct {
+		name            string
+		client          *testDryRunClient
+		expectedMissing []string
+		expectError     bool
+	}{
+		{
+			name:   "all allowed",
+			client: &testDryRunClient{createTagsErr: dryRunOperationErr, deleteTagsErr: dryRunOperationErr},
+		},
+		{
+			name:            "delete not allowed",
+			client:          &testDryRunClient{createTagsErr: dryRunOperationErr, deleteTagsErr: unauthorizedOperationErr},
+			expectedMissing: []string{"ec2:DeleteTags"},
+		},
+		{
+			name:            "none allowed",
+			client:          &testDryRunClient{createTagsErr: unauthorizedOperationErr, deleteTagsErr: unauthorizedOperationErr},
+			expectedMissing: []string{"ec2:CreateTags", "ec2:DeleteTags"},
+		},
+		{
+			name:        "unexpected error",
+			client:      &testDryRunClient{createTagsErr: errors.New("connection refused")},
+			expectError: true,
+		},
+		{
+			name:        "no dry run result",
+			client:      &testDryRunClient{},
+			expectError: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			missing, err := MissingTaggingPermissions(context.Background(), tc.client, "subnet-1", "test-tag")
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expectedMissing, missing); diff != "" {
+				t.Errorf("unexpected missing permissions:\n%s", diff)
+			}
+		})
+	}
+}
+
+type testSTSClient struct {
+	arn string
+}
+
+func (c *testSTSClient) GetCallerIdentity(_ context.Context, _ *sts.GetCallerIdentityInput, _ ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error) {
+	return &sts.GetCallerIdentityOutput{Arn: aws.String(c.arn)}, nil
+}
+
+func TestGetCallerPrincipalARN(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		arn      string
+		expected string
+	}{
+		{
+			name:     "user",
+			arn:      "arn:aws:iam::123456789012:user/test-user",
+			expected: "arn:aws:iam::123456789012:user/test-user",
+		},
+		{
+			name:     "assumed role",
+			arn:      "arn:aws-us-gov:sts::123456789012:assumed-role/test-role/test-session",
+			expected: "arn:aws-us-gov:iam::123456789012:role/test-role",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			arn, err := GetCallerPrincipalARN(context.Background(), &testSTSClient{arn: tc.arn})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if arn != tc.expected {
+				t.Errorf("expected principal %q, got %q", tc.expected, arn)
+			}
+		})
+	}
+}
+
+type testIAMClient struct {
+	t       *testing.T
+	allowed map[string]bool
+}
+
+// SimulatePrincipalPolicy returns one evaluation result per page to test the pagination.
+func (c *testIAMClient) SimulatePrincipalPolicy(_ context.Context, input *iam.SimulatePrincipalPolicyInput, _ ...func(*iam.Options)) (*iam.SimulatePrincipalPolicyOutput, error) {
+	c.t.Helper()
+	if aws.ToString(input.PolicySourceArn) != "arn:aws:iam::123456789012:user/test-user" {
+		c.t.Errorf("unexpected policy source %q", aws.ToString(input.PolicySourceArn))
+	}
+	var index int
+	if input.Marker != nil {
+		for i, action := range input.ActionNames {
+			if action == aws.ToString(input.Marker) {
+				index = i
+			}
+		}
+	}
+	action := input.ActionNames[index]
+	decision := iamtypes.PolicyEvaluationDecisionTypeImplicitDeny
+	if c.allowed[action] {
+		decision = iamtypes.PolicyEvaluationDecisionTypeAllowed
+	}
+	output := &iam.SimulatePrincipalPolicyOutput{
+		EvaluationResults: []iamtypes.EvaluationResult{{EvalActionName: aws.String(action), EvalDecision: decision}},
+	}
+	if index+1 < len(input.ActionNames) {
+		output.IsTruncated = true
+		output.Marker = aws.String(input.ActionNames[index+1])
+	}
+	return output, nil
+}
+
+func TestMissingActions(t *testing.T) {
+	client := &testIAMClient{
+		t:       t,
+		allowed: map[string]bool{"ec2:DescribeSubnets": true, "ec2:CreateTags": true},
+	}
+	actions := []string{"ec2:DescribeSubnets", "ec2:Describe*", "elasticloadbalancing:CreateLoadBalancer", "ec2:CreateTags", "ec2:CreateSecurityGroup"}
+	missing, err := MissingActions(context.Background(), client, "arn:aws:iam::123456789012:user/test-user", actions)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []string{"ec2:CreateSecurityGroup", "elasticloadbalancing:CreateLoadBalancer"}
+	if diff := cmp.Diff(expected, missing); diff != "" {
+		t.Errorf("unexpected missing actions:\n%s", diff)
+	}
+}
@@ -0,0 +1,196 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+// ErrorClass is the class of the errors of the AWS API calls.
+type ErrorClass string
+
+const (
+	// ThrottlingErrorClass is the class of the errors of the calls which were rate limited by AWS
+	ThrottlingErrorClass ErrorClass = "Throttling"
+	// UnauthorizedErrorClass is the class of the errors of the calls which the credentials are not allowed to make
+	UnauthorizedErrorClass ErrorClass = "Unauthorized"
+	// NotFoundErrorClass is the class of the errors of the calls on resources which don't exist
+	NotFoundErrorClass ErrorClass = "NotFound"
+	// InvalidParameterErrorClass is the class of the errors of the calls with invalid parameters
+	InvalidParameterErrorClass ErrorClass = "InvalidParameter"
+	// EndpointUnreachableErrorClass is the class of the errors of the calls which couldn't be sent to the AWS endpoint
+	EndpointUnreachableErrorClass ErrorClass = "EndpointUnreachable"
+	// CallBudgetErrorClass is the class of the errors of the calls which exceeded the call budget
+	CallBudgetErrorClass ErrorClass = "CallBudgetExceeded"
+	// UnknownErrorClass is the class of the other errors
+	UnknownErrorClass ErrorClass = "Unknown"
+)
+
+// unauthorizedErrorCodes are the codes of the errors returned by AWS when the credentials are invalid or are not
+// allowed to make a call.
+var unauthorizedErrorCodes = map[string]struct{}{
+	"AuthFailure":                 {},
+	"UnauthorizedOperation":       {},
+	"AccessDenied":                {},
+	"AccessDeniedException":       {},
+	"InvalidClientTokenId":        {},
+	"SignatureDoesNotMatch":       {},
+	"ExpiredToken":                {},
+	"ExpiredTokenException":       {},
+	"UnrecognizedClientException": {},
+}
+
+// notFoundErrorCodes are the codes of the errors returned by AWS when a resource doesn't exist. The EC2 codes have a
+// ".NotFound" suffix, e.g. InvalidSubnetID.NotFound, and are not listed.
+var notFoundErrorCodes = map[string]struct{}{
+	"NoSuchEntity":      {},
+	"NotFoundException": {},
+}
+
+// invalidParameterErrorCodes are the codes of the errors returned by AWS when a parameter of a call is invalid. The
+// EC2 codes of the malformed IDs have a ".Malformed" suffix, e.g. InvalidVpcID.Malformed, and are not listed.
+var invalidParameterErrorCodes = map[string]struct{}{
+	"InvalidParameter":            {},
+	"InvalidParameterValue":       {},
+	"InvalidParameterCombination": {},
+	"InvalidFilter":               {},
+	"MissingParameter":            {},
+	"ValidationError":             {},
+	"InvalidInput":                {},
+	"TagLimitExceeded":            {},
+}
+
+// serviceIAMPrefixes maps the IDs of the AWS services to the prefixes of their IAM actions.
+var serviceIAMPrefixes = map[string]string{
+	ec2.ServiceID: "ec2",
+	iam.ServiceID: "iam",
+	sts.ServiceID: "sts",
+}
+
+// Error is the error of an AWS API call with its class, the IAM action of the call and the resources it was made on.
+type Error struct {
+	Class ErrorClass
+	// Action is the IAM action of the call, e.g. ec2:CreateTags
+	Action string
+	// Resources are the IDs or the ARNs of the resources of the call, when the call has any
+	Resources []string
+	Err       error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// newError returns the Error of a call of the given operation of the given service.
+func newError(err error, serviceID, operation string, resources []string) *Error {
+	prefix, ok := serviceIAMPrefixes[serviceID]
+	if !ok {
+		prefix = strings.ToLower(serviceID)
+	}
+	return &Error{
+		Class:     classifyError(err),
+		Action:    fmt.Sprintf("%s:%s", prefix, operation),
+		Resources: resources,
+		Err:       err,
+	}
+}
+
+// callResources returns the resources of the parameters of the calls which are made on specific resources.
+func callResources(params interface{}) []string {
+	switch p := params.(type) {
+	case *ec2.CreateTagsInput:
+		return p.Resources
+	case *ec2.DeleteTagsInput:
+		return p.Resources
+	case *ec2.DescribeVpcsInput:
+		return p.VpcIds
+	case *ec2.DescribeSubnetsInput:
+		return p.SubnetIds
+	case *iam.SimulatePrincipalPolicyInput:
+		if p.PolicySourceArn != nil {
+			return []string{*p.PolicySourceArn}
+		}
+	}
+	return nil
+}
+
+// ClassifyError returns the class of the error of an AWS API call. The error of the last attempt is classified when
+// the call was retried.
+func ClassifyError(err error) ErrorClass {
+	var awsErr *Error
+	if errors.As(err, &awsErr) {
+		return awsErr.Class
+	}
+	return classifyError(err)
+}
+
+func classifyError(err error) ErrorClass {
+	if errors.Is(err, ErrCallBudgetExceeded) {
+		return CallBudgetErrorClass
+	}
+
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		code := apiErr.ErrorCode()
+		if _, ok := retry.DefaultThrottleErrorCodes[code]; ok {
+			return ThrottlingErrorClass
+		}
+		if _, ok := unauthorizedErrorCodes[code]; ok {
+			return UnauthorizedErrorClass
+		}
+		if _, ok := notFoundErrorCodes[code]; ok || strings.HasSuffix(code, ".NotFound") {
+			return NotFoundErrorClass
+		}
+		if _, ok := invalidParameterErrorCodes[code]; ok || strings.HasSuffix(code, ".Malformed") {
+			return InvalidParameterErrorClass
+		}
+		return UnknownErrorClass
+	}
+
+	// the request couldn't be sent, e.g. the endpoint can't be resolved or the connection is refused
+	var sendErr *smithyhttp.RequestSendError
+	if errors.As(err, &sendErr) {
+		return EndpointUnreachableErrorClass
+	}
+	return UnknownErrorClass
+}
+
+// Remediation returns a hint to fix the error of an AWS API call made with the given credentials, e.g. "operator
+// credentials". An empty hint is returned for the errors of the unknown class.
+func Remediation(err error, credentials string) string {
+	action, resources := "the AWS API", "all resources"
+	var awsErr *Error
+	if errors.As(err, &awsErr) {
+		action = awsErr.Action
+		if len(awsErr.Resources) > 0 {
+			resources = strings.Join(awsErr.Resources, ", ")
+		}
+	}
+
+	switch ClassifyError(err) {
+	case UnauthorizedErrorClass:
+		return fmt.Sprintf("%s lack %s on %s, allow it in the IAM policy of the credentials or check that they have not expired", credentials, action, resources)
+	case ThrottlingErrorClass:
+		return fmt.Sprintf("AWS throttled %s, the calls are retried later, reduce the API calls made by other clients of the account", action)
+	case NotFoundErrorClass:
+		return fmt.Sprintf("%s did not find %s, check that the resources exist in the region of the cluster", action, resources)
+	case InvalidParameterErrorClass:
+		return fmt.Sprintf("%s was called with an invalid parameter, check the spec of the AWSLoadBalancerController", action)
+	case EndpointUnreachableErrorClass:
+		return fmt.Sprintf("the AWS endpoint of %s is unreachable, check the cluster proxy, the trusted CA bundle and the service endpoints of the Infrastructure", action)
+	case CallBudgetErrorClass:
+		return "the reconcile made too many AWS API calls, the calls are retried later"
+	}
+	return ""
+}
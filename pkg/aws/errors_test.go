@@ -0,0 +1,142 @@
+package aws
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
+)
+
+func TestClassifyError(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		err      error
+		expected ErrorClass
+	}{
+		{
+			name:     "throttling",
+			err:      &smithy.GenericAPIError{Code: "RequestLimitExceeded"},
+			expected: ThrottlingErrorClass,
+		},
+		{
+			name:     "wrapped throttling",
+			err:      fmt.Errorf("failed to list subnets: %w", &smithy.OperationError{Err: &smithy.GenericAPIError{Code: "Throttling"}}),
+			expected: ThrottlingErrorClass,
+		},
+		{
+			name:     "unauthorized",
+			err:      &smithy.GenericAPIError{Code: "UnauthorizedOperation"},
+			expected: UnauthorizedErrorClass,
+		},
+		{
+			name:     "EC2 resource not found",
+			err:      &smithy.GenericAPIError{Code: "InvalidSubnetID.NotFound"},
+			expected: NotFoundErrorClass,
+		},
+		{
+			name:     "IAM entity not found",
+			err:      &smithy.GenericAPIError{Code: "NoSuchEntity"},
+			expected: NotFoundErrorClass,
+		},
+		{
+			name:     "call budget",
+			err:      fmt.Errorf("%w: 10 calls were made", ErrCallBudgetExceeded),
+			expected: CallBudgetErrorClass,
+		},
+		{
+			name:     "invalid parameter",
+			err:      &smithy.GenericAPIError{Code: "InvalidParameterValue"},
+			expected: InvalidParameterErrorClass,
+		},
+		{
+			name:     "malformed ID",
+			err:      &smithy.GenericAPIError{Code: "InvalidVpcID.Malformed"},
+			expected: InvalidParameterErrorClass,
+		},
+		{
+			name:     "endpoint unreachable",
+			err:      &smithy.OperationError{Err: &smithyhttp.RequestSendError{Err: errors.New("dial tcp: connection refused")}},
+			expected: EndpointUnreachableErrorClass,
+		},
+		{
+			name:     "typed error",
+			err:      fmt.Errorf("failed to tag subnets: %w", &Error{Class: UnauthorizedErrorClass, Err: errors.New("forbidden")}),
+			expected: UnauthorizedErrorClass,
+		},
+		{
+			name:     "other API error",
+			err:      &smithy.GenericAPIError{Code: "IncorrectState"},
+			expected: UnknownErrorClass,
+		},
+		{
+			name:     "not an API error",
+			err:      errors.New("connection refused"),
+			expected: UnknownErrorClass,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if class := ClassifyError(tc.err); class != tc.expected {
+				t.Errorf("expected error class %q, got %q", tc.expected, class)
+			}
+		})
+	}
+}
+
+func TestNewError(t *testing.T) {
+	err := newError(&smithy.GenericAPIError{Code: "UnauthorizedOperation"}, ec2.ServiceID, "CreateTags", callResources(&ec2.CreateTagsInput{Resources: []string{"subnet-1", "subnet-2"}}))
+	expected := &Error{
+		Class:     UnauthorizedErrorClass,
+		Action:    "ec2:CreateTags",
+		Resources: []string{"subnet-1", "subnet-2"},
+	}
+	if err.Class != expected.Class || err.Action != expected.Action || strings.Join(err.Resources, ",") != strings.Join(expected.Resources, ",") {
+		t.Errorf("expected %+v, got %+v", expected, err)
+	}
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) || apiErr.ErrorCode() != "UnauthorizedOperation" {
+		t.Errorf("expected the API error to be unwrapped from %v", err)
+	}
+}
+
+func TestRemediation(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		err      error
+		expected string
+	}{
+		{
+			name:     "unauthorized call on resources",
+			err:      fmt.Errorf("failed to tag subnets: %w", &Error{Class: UnauthorizedErrorClass, Action: "ec2:CreateTags", Resources: []string{"subnet-xyz"}, Err: errors.New("forbidden")}),
+			expected: "operator credentials lack ec2:CreateTags on subnet-xyz, allow it in the IAM policy of the credentials or check that they have not expired",
+		},
+		{
+			name:     "unauthorized call without resources",
+			err:      &Error{Class: UnauthorizedErrorClass, Action: "ec2:DescribeSubnets", Err: errors.New("forbidden")},
+			expected: "operator credentials lack ec2:DescribeSubnets on all resources, allow it in the IAM policy of the credentials or check that they have not expired",
+		},
+		{
+			name:     "unreachable endpoint",
+			err:      &Error{Class: EndpointUnreachableErrorClass, Action: "ec2:DescribeVpcs", Err: errors.New("connection refused")},
+			expected: "the AWS endpoint of ec2:DescribeVpcs is unreachable, check the cluster proxy, the trusted CA bundle and the service endpoints of the Infrastructure",
+		},
+		{
+			name:     "untyped throttling error",
+			err:      &smithy.GenericAPIError{Code: "RequestLimitExceeded"},
+			expected: "AWS throttled the AWS API, the calls are retried later, reduce the API calls made by other clients of the account",
+		},
+		{
+			name: "unknown error",
+			err:  errors.New("unknown"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if hint := Remediation(tc.err, "operator credentials"); hint != tc.expected {
+				t.Errorf("expected hint %q, got %q", tc.expected, hint)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package aws
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+)
+
+const (
+	// FileCredentialsProviderName is the source of the credentials retrieved by the FileCredentialsProvider
+	FileCredentialsProviderName = "FileCredentialsProvider"
+
+	// sharedCredentialsFileEnvVar is the environment variable with the path of the shared credentials file
+	sharedCredentialsFileEnvVar = "AWS_SHARED_CREDENTIALS_FILE"
+	// profileEnvVar is the environment variable with the profile of the shared credentials file
+	profileEnvVar = "AWS_PROFILE"
+	// defaultProfile is the profile used when AWS_PROFILE is not set
+	defaultProfile = "default"
+
+	// keys of the shared credentials file
+	accessKeyIDKey     = "aws_access_key_id"
+	secretAccessKeyKey = "aws_secret_access_key"
+	sessionTokenKey    = "aws_session_token"
+)
+
+// credentialsFileRefreshInterval is how long the credentials read from the file are cached before the file is checked
+// again. A var so that tests don't have to wait for the refresh.
+var credentialsFileRefreshInterval = 10 * time.Second
+
+// FileCredentialsProvider retrieves the access keys from an AWS shared credentials file, e.g. the one mounted from the
+// secret minted by the cloud credential operator. The credentials expire after a short interval so that the SDK
+// retrieves them again and the keys rotated in the file are used without a restart.
+type FileCredentialsProvider struct {
+	filename string
+	profile  string
+
+	lock    sync.Mutex
+	content []byte
+	creds   aws.Credentials
+}
+
+// NewFileCredentialsProvider returns a FileCredentialsProvider for the given profile of the given file.
+func NewFileCredentialsProvider(filename, profile string) *FileCredentialsProvider {
+	return &FileCredentialsProvider{
+		filename: filename,
+		profile:  profile,
+	}
+}
+
+// Retrieve returns the access keys of the file. The file is parsed again only when its content has changed.
+func (p *FileCredentialsProvider) Retrieve(_ context.Context) (aws.Credentials, error) {
+	content, err := os.ReadFile(p.filename)
+	if err != nil {
+		return aws.Credentials{}, fmt.Errorf("failed to read credentials file %s: %w", p.filename, err)
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.content == nil || !bytes.Equal(content, p.content) {
+		creds, err := parseSharedCredentials(content, p.profile)
+		if err != nil {
+			return aws.Credentials{}, fmt.Errorf("failed to parse credentials file %s: %w", p.filename, err)
+		}
+		p.content, p.creds = content, creds
+	}
+
+	creds := p.creds
+	creds.CanExpire = true
+	creds.Expires = time.Now().Add(credentialsFileRefreshInterval)
+	return creds, nil
+}
+
+// parseSharedCredentials returns the access keys of the profile in the shared credentials file.
+func parseSharedCredentials(content []byte, profile string) (aws.Credentials, error) {
+	creds := aws.Credentials{Source: FileCredentialsProviderName}
+	found := false
+	section := ""
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(strings.Trim(line, "[]"))
+			found = found || section == profile
+			continue
+		}
+		if section != profile {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		switch strings.TrimSpace(key) {
+		case accessKeyIDKey:
+			creds.AccessKeyID = strings.TrimSpace(value)
+		case secretAccessKeyKey:
+			creds.SecretAccessKey = strings.TrimSpace(value)
+		case sessionTokenKey:
+			creds.SessionToken = strings.TrimSpace(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return aws.Credentials{}, err
+	}
+	if !found {
+		return aws.Credentials{}, fmt.Errorf("profile %q not found", profile)
+	}
+	if !creds.HasKeys() {
+		return aws.Credentials{}, fmt.Errorf("profile %q has no access keys", profile)
+	}
+	return creds, nil
+}
+
+// sharedCredentialsFileProvider returns a FileCredentialsProvider for the shared credentials file of the environment
+// if the file has access keys. The files of STS clusters have a role and a web identity token file instead, the SDK
+// already reads the rotated token from the token file so they are left to the default credentials chain.
+func sharedCredentialsFileProvider() (aws.CredentialsProvider, bool) {
+	filename := os.Getenv(sharedCredentialsFileEnvVar)
+	if filename == "" {
+		return nil, false
+	}
+	profile := os.Getenv(profileEnvVar)
+	if profile == "" {
+		profile = defaultProfile
+	}
+
+	content, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, false
+	}
+	if _, err := parseSharedCredentials(content, profile); err != nil {
+		return nil, false
+	}
+	return NewFileCredentialsProvider(filename, profile), true
+}
@@ -0,0 +1,167 @@
+package aws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func writeCredentialsFile(t *testing.T, filename, content string) {
+	t.Helper()
+	if err := os.WriteFile(filename, []byte(content), 0600); err != nil {
+		t.Fatalf("failed to write credentials file: %v", err)
+	}
+}
+
+func TestParseSharedCredentials(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		content       string
+		profile       string
+		expected      aws.Credentials
+		expectedError string
+	}{
+		{
+			name:    "default profile",
+			content: "[default]\naws_access_key_id = AKID1\naws_secret_access_key = SECRET1\n",
+			profile: "default",
+			expected: aws.Credentials{
+				AccessKeyID:     "AKID1",
+				SecretAccessKey: "SECRET1",
+				Source:          FileCredentialsProviderName,
+			},
+		},
+		{
+			name:    "other profile with session token and comments",
+			content: "[default]\naws_access_key_id = AKID1\naws_secret_access_key = SECRET1\n\n# operator\n[operator]\naws_access_key_id=AKID2\naws_secret_access_key=SECRET2\naws_session_token=TOKEN2\n",
+			profile: "operator",
+			expected: aws.Credentials{
+				AccessKeyID:     "AKID2",
+				SecretAccessKey: "SECRET2",
+				SessionToken:    "TOKEN2",
+				Source:          FileCredentialsProviderName,
+			},
+		},
+		{
+			name:          "missing profile",
+			content:       "[default]\naws_access_key_id = AKID1\naws_secret_access_key = SECRET1\n",
+			profile:       "operator",
+			expectedError: `profile "operator" not found`,
+		},
+		{
+			name:          "role of an STS cluster",
+			content:       "[default]\nrole_arn = arn:aws:iam::123456789012:role/operator\nweb_identity_token_file = /var/run/secrets/openshift/serviceaccount/token\n",
+			profile:       "default",
+			expectedError: `profile "default" has no access keys`,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			creds, err := parseSharedCredentials([]byte(tc.content), tc.profile)
+			if tc.expectedError != "" {
+				if err == nil || err.Error() != tc.expectedError {
+					t.Fatalf("expected error %q, got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expected, creds); diff != "" {
+				t.Errorf("unexpected credentials (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestFileCredentialsProviderRotation(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "credentials")
+	writeCredentialsFile(t, filename, "[default]\naws_access_key_id = AKID1\naws_secret_access_key = SECRET1\n")
+	provider := NewFileCredentialsProvider(filename, "default")
+	ignoreExpiry := cmpopts.IgnoreFields(aws.Credentials{}, "CanExpire", "Expires")
+
+	creds, err := provider.Retrieve(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := aws.Credentials{AccessKeyID: "AKID1", SecretAccessKey: "SECRET1", Source: FileCredentialsProviderName}
+	if diff := cmp.Diff(expected, creds, ignoreExpiry); diff != "" {
+		t.Errorf("unexpected credentials (-want +got):\n%s", diff)
+	}
+	if !creds.CanExpire || creds.Expires.After(time.Now().Add(credentialsFileRefreshInterval)) {
+		t.Errorf("expected credentials to expire within %v, got %v", credentialsFileRefreshInterval, creds.Expires)
+	}
+
+	writeCredentialsFile(t, filename, "[default]\naws_access_key_id = AKID2\naws_secret_access_key = SECRET2\n")
+	creds, err = provider.Retrieve(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected = aws.Credentials{AccessKeyID: "AKID2", SecretAccessKey: "SECRET2", Source: FileCredentialsProviderName}
+	if diff := cmp.Diff(expected, creds, ignoreExpiry); diff != "" {
+		t.Errorf("unexpected credentials after rotation (-want +got):\n%s", diff)
+	}
+
+	if err := os.Remove(filename); err != nil {
+		t.Fatalf("failed to remove credentials file: %v", err)
+	}
+	if _, err := provider.Retrieve(context.Background()); err == nil {
+		t.Errorf("expected error when the credentials file is removed")
+	}
+}
+
+// TestEC2ClientCredentialsRotation simulates the rotation of the secret mounted into the operator and checks that the
+// EC2 client signs the requests with the new access key without being made again.
+func TestEC2ClientCredentialsRotation(t *testing.T) {
+	refreshInterval := credentialsFileRefreshInterval
+	credentialsFileRefreshInterval = 0
+	t.Cleanup(func() { credentialsFileRefreshInterval = refreshInterval })
+
+	var (
+		lock         sync.Mutex
+		accessKeyIDs []string
+	)
+	credentialRegexp := regexp.MustCompile(`Credential=([^/]+)/`)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		if match := credentialRegexp.FindStringSubmatch(r.Header.Get("Authorization")); match != nil {
+			accessKeyIDs = append(accessKeyIDs, match[1])
+		}
+		w.Header().Set("Content-Type", "text/xml")
+		_, _ = w.Write([]byte(`<DescribeVpcsResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/"><vpcSet/></DescribeVpcsResponse>`))
+	}))
+	defer server.Close()
+
+	filename := filepath.Join(t.TempDir(), "credentials")
+	writeCredentialsFile(t, filename, "[default]\naws_access_key_id = AKID1\naws_secret_access_key = SECRET1\n")
+	t.Setenv(sharedCredentialsFileEnvVar, filename)
+	t.Setenv(profileEnvVar, "")
+
+	client, err := NewClient(context.Background(), "us-east-1", nil, map[string]string{EC2ServiceEndpointName: server.URL})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, err := client.DescribeVpcs(context.Background(), &ec2.DescribeVpcsInput{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	writeCredentialsFile(t, filename, "[default]\naws_access_key_id = AKID2\naws_secret_access_key = SECRET2\n")
+	if _, err := client.DescribeVpcs(context.Background(), &ec2.DescribeVpcsInput{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if diff := cmp.Diff([]string{"AKID1", "AKID2"}, accessKeyIDs); diff != "" {
+		t.Errorf("unexpected access keys of the requests (-want +got):\n%s", diff)
+	}
+}
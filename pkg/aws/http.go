@@ -0,0 +1,44 @@
+package aws
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	awshttp "github.com/aws/aws-sdk-go-v2/aws/transport/http"
+	"golang.org/x/net/http/httpproxy"
+)
+
+// NewHTTPClient returns an HTTP client for the AWS API calls. When the proxy configuration is given
+// the requests are sent through the proxy instead of the one from the environment. When the CA bundle
+// is given the servers are verified against it in addition to the system roots.
+func NewHTTPClient(proxyConfig *httpproxy.Config, caBundle []byte) (*awshttp.BuildableClient, error) {
+	var rootCAs *x509.CertPool
+	if len(caBundle) > 0 {
+		var err error
+		rootCAs, err = x509.SystemCertPool()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load the system cert pool: %w", err)
+		}
+		if !rootCAs.AppendCertsFromPEM(caBundle) {
+			return nil, fmt.Errorf("no valid certificates found in the CA bundle")
+		}
+	}
+
+	return awshttp.NewBuildableClient().WithTransportOptions(func(tr *http.Transport) {
+		if proxyConfig != nil {
+			proxyFunc := proxyConfig.ProxyFunc()
+			tr.Proxy = func(req *http.Request) (*url.URL, error) {
+				return proxyFunc(req.URL)
+			}
+		}
+		if rootCAs != nil {
+			if tr.TLSClientConfig == nil {
+				tr.TLSClientConfig = &tls.Config{MinVersion: awshttp.DefaultHTTPTransportTLSMinVersion}
+			}
+			tr.TLSClientConfig.RootCAs = rootCAs
+		}
+	}), nil
+}
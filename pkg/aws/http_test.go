@@ -0,0 +1,133 @@
+package aws
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"testing"
+	"time"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+func TestNewHTTPClientProxy(t *testing.T) {
+	proxyConfig := &httpproxy.Config{
+		HTTPProxy:  "http://http-proxy.example.com:3128",
+		HTTPSProxy: "http://https-proxy.example.com:3128",
+		NoProxy:    ".cluster.local,ec2.us-east-1.amazonaws.com",
+	}
+	for _, tc := range []struct {
+		name          string
+		requestURL    string
+		expectedProxy string
+	}{
+		{
+			name:          "https request through the proxy",
+			requestURL:    "https://elasticloadbalancing.us-east-1.amazonaws.com/",
+			expectedProxy: "http://https-proxy.example.com:3128",
+		},
+		{
+			name:          "http request through the proxy",
+			requestURL:    "http://elasticloadbalancing.us-east-1.amazonaws.com/",
+			expectedProxy: "http://http-proxy.example.com:3128",
+		},
+		{
+			name:       "request to a no proxy host",
+			requestURL: "https://ec2.us-east-1.amazonaws.com/",
+		},
+		{
+			name:       "request to a no proxy domain",
+			requestURL: "https://kubernetes.default.svc.cluster.local/",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			client, err := NewHTTPClient(proxyConfig, nil)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			req, err := http.NewRequest(http.MethodGet, tc.requestURL, nil)
+			if err != nil {
+				t.Fatalf("failed to create request: %v", err)
+			}
+			proxyURL, err := client.GetTransport().Proxy(req)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var proxy string
+			if proxyURL != nil {
+				proxy = proxyURL.String()
+			}
+			if proxy != tc.expectedProxy {
+				t.Errorf("expected proxy %q, got %q", tc.expectedProxy, proxy)
+			}
+		})
+	}
+}
+
+func TestNewHTTPClientCABundle(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		caBundle    []byte
+		expectedErr string
+		expectRoots bool
+	}{
+		{
+			name: "no CA bundle",
+		},
+		{
+			name:        "valid CA bundle",
+			caBundle:    testCACertificate(t),
+			expectRoots: true,
+		},
+		{
+			name:        "invalid CA bundle",
+			caBundle:    []byte("not a certificate"),
+			expectedErr: "no valid certificates found in the CA bundle",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			client, err := NewHTTPClient(nil, tc.caBundle)
+			if tc.expectedErr != "" {
+				if err == nil || err.Error() != tc.expectedErr {
+					t.Fatalf("expected error %q, got %v", tc.expectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			tlsConfig := client.GetTransport().TLSClientConfig
+			hasRoots := tlsConfig != nil && tlsConfig.RootCAs != nil
+			if hasRoots != tc.expectRoots {
+				t.Errorf("expected custom root CAs to be %t, instead was %t", tc.expectRoots, hasRoots)
+			}
+		})
+	}
+}
+
+func testCACertificate(t *testing.T) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
@@ -0,0 +1,46 @@
+package aws
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	metricsNamespace = "aws_load_balancer_operator"
+	metricsSubsystem = "aws_api"
+
+	// successResult is the result label of the calls which succeeded, the result of the failed calls is the error class
+	successResult = "Success"
+)
+
+var (
+	// callsTotal counts the AWS API calls by their result after the retries
+	callsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "calls_total",
+		Help:      "Number of AWS API calls made by the operator, by the result of the last attempt.",
+	}, []string{"service", "operation", "result"})
+
+	// attemptsTotal counts the attempts of the AWS API calls, including the retries
+	attemptsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "attempts_total",
+		Help:      "Number of attempts of the AWS API calls made by the operator, including the retries, by their result.",
+	}, []string{"service", "operation", "result"})
+
+	// callDuration measures the duration of the AWS API calls, including the backoff between the retries
+	callDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: metricsNamespace,
+		Subsystem: metricsSubsystem,
+		Name:      "call_duration_seconds",
+		Help:      "Duration of the AWS API calls made by the operator, including the retries and their backoff.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"service", "operation"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(callsTotal, attemptsTotal, callDuration)
+}
@@ -0,0 +1,128 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsmiddleware "github.com/aws/aws-sdk-go-v2/aws/middleware"
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/smithy-go/middleware"
+
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// retryMaxAttempts is the maximum number of attempts of an AWS API call
+	retryMaxAttempts = 5
+	// retryMaxBackoff is the maximum delay between the attempts of an AWS API call
+	retryMaxBackoff = 30 * time.Second
+
+	callMetricsMiddlewareID = "OperatorCallMetrics"
+	attemptMiddlewareID     = "OperatorAttempt"
+	// retryMiddlewareID is the ID of the SDK middleware which retries the attempts
+	retryMiddlewareID = "Retry"
+)
+
+// ErrCallBudgetExceeded is returned by the AWS API calls made after the call budget of the context was used.
+var ErrCallBudgetExceeded = errors.New("AWS API call budget exceeded")
+
+type callBudgetKey struct{}
+
+// callBudget is the number of AWS API attempts which can still be made with a context.
+type callBudget struct {
+	limit     int64
+	remaining atomic.Int64
+}
+
+// WithCallBudget returns a context which allows the given number of AWS API attempts, including the retries. The calls
+// made after the budget was used fail with ErrCallBudgetExceeded. This bounds the calls made by a single reconcile
+// when AWS throttles the account.
+func WithCallBudget(ctx context.Context, limit int) context.Context {
+	budget := &callBudget{limit: int64(limit)}
+	budget.remaining.Store(int64(limit))
+	return context.WithValue(ctx, callBudgetKey{}, budget)
+}
+
+// newRetryer returns the retryer of the clients. The adaptive mode backs off exponentially between the attempts and
+// rate limits the calls of the client when AWS returns throttling errors.
+func newRetryer() aws.Retryer {
+	return retry.NewAdaptiveMode(func(o *retry.AdaptiveModeOptions) {
+		o.StandardOptions = append(o.StandardOptions, func(so *retry.StandardOptions) {
+			so.MaxAttempts = retryMaxAttempts
+			so.MaxBackoff = retryMaxBackoff
+		})
+	})
+}
+
+type attemptsKey struct{}
+
+// addCallMiddlewares adds the middlewares which record the metrics of the calls and their attempts and enforce the
+// call budget of the context.
+func addCallMiddlewares(stack *middleware.Stack) error {
+	if err := stack.Initialize.Add(middleware.InitializeMiddlewareFunc(callMetricsMiddlewareID, handleCall), middleware.After); err != nil {
+		return err
+	}
+	// each attempt is made after the retry middleware
+	return stack.Finalize.Insert(middleware.FinalizeMiddlewareFunc(attemptMiddlewareID, handleAttempt), retryMiddlewareID, middleware.After)
+}
+
+// handleCall records the result and the duration of a call. The errors are returned as an Error with their class.
+func handleCall(ctx context.Context, in middleware.InitializeInput, next middleware.InitializeHandler) (middleware.InitializeOutput, middleware.Metadata, error) {
+	service, operation := awsmiddleware.GetServiceID(ctx), awsmiddleware.GetOperationName(ctx)
+	attempts := new(int)
+	ctx = middleware.WithStackValue(ctx, attemptsKey{}, attempts)
+
+	start := time.Now()
+	out, metadata, err := next.HandleInitialize(ctx, in)
+	callDuration.WithLabelValues(service, operation).Observe(time.Since(start).Seconds())
+
+	result := successResult
+	if err != nil {
+		awsErr := newError(err, service, operation, callResources(in.Parameters))
+		result, err = string(awsErr.Class), awsErr
+	}
+	callsTotal.WithLabelValues(service, operation, result).Inc()
+	if *attempts > 1 {
+		log.FromContext(ctx).V(1).Info("AWS API call retried", "service", service, "operation", operation, "attempts", *attempts, "result", result)
+	}
+	return out, metadata, err
+}
+
+// handleAttempt enforces the call budget of the context and records the result of an attempt.
+func handleAttempt(ctx context.Context, in middleware.FinalizeInput, next middleware.FinalizeHandler) (middleware.FinalizeOutput, middleware.Metadata, error) {
+	service, operation := awsmiddleware.GetServiceID(ctx), awsmiddleware.GetOperationName(ctx)
+	logger := log.FromContext(ctx)
+	attempts, _ := middleware.GetStackValue(ctx, attemptsKey{}).(*int)
+	if attempts != nil {
+		*attempts++
+	}
+
+	if budget, ok := ctx.Value(callBudgetKey{}).(*callBudget); ok && budget.remaining.Add(-1) < 0 {
+		attemptsTotal.WithLabelValues(service, operation, string(CallBudgetErrorClass)).Inc()
+		logger.Info("AWS API call budget exceeded", "service", service, "operation", operation, "budget", budget.limit)
+		return middleware.FinalizeOutput{}, middleware.Metadata{}, fmt.Errorf("%w: %d calls were made", ErrCallBudgetExceeded, budget.limit)
+	}
+
+	out, metadata, err := next.HandleFinalize(ctx, in)
+	if err == nil {
+		attemptsTotal.WithLabelValues(service, operation, successResult).Inc()
+		return out, metadata, nil
+	}
+	class := classifyError(err)
+	attemptsTotal.WithLabelValues(service, operation, string(class)).Inc()
+	if class == ThrottlingErrorClass {
+		logger.Info("AWS API call throttled, backing off", "service", service, "operation", operation, "attempt", attemptNumber(attempts))
+	}
+	return out, metadata, err
+}
+
+func attemptNumber(attempts *int) int {
+	if attempts == nil {
+		return 0
+	}
+	return *attempts
+}
@@ -0,0 +1,149 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws/retry"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+const (
+	describeVpcsResponse = `<DescribeVpcsResponse xmlns="http://ec2.amazonaws.com/doc/2016-11-15/"><vpcSet/></DescribeVpcsResponse>`
+	throttlingResponse   = `<Response><Errors><Error><Code>RequestLimitExceeded</Code><Message>Request limit exceeded.</Message></Error></Errors><RequestID>1</RequestID></Response>`
+)
+
+// testEC2Server returns DescribeVpcs responses after the given number of throttling errors.
+type testEC2Server struct {
+	lock      sync.Mutex
+	throttled int
+	requests  int
+}
+
+func (s *testEC2Server) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.requests++
+	w.Header().Set("Content-Type", "text/xml")
+	if s.requests <= s.throttled {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(throttlingResponse))
+		return
+	}
+	_, _ = w.Write([]byte(describeVpcsResponse))
+}
+
+// newTestEC2Client returns an EC2 client of the given server which doesn't wait long between the retries. The
+// standard retryer replaces the adaptive one, whose client side rate limiting would slow down the tests.
+func newTestEC2Client(t *testing.T, server *httptest.Server) *ec2.Client {
+	t.Helper()
+	t.Setenv(sharedCredentialsFileEnvVar, "")
+	t.Setenv("AWS_ACCESS_KEY_ID", "AKID")
+	t.Setenv("AWS_SECRET_ACCESS_KEY", "SECRET")
+
+	awsConfig, err := loadConfig(context.Background(), "us-east-1", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return ec2.NewFromConfig(awsConfig, func(o *ec2.Options) {
+		o.EndpointResolver = ec2.EndpointResolverFromURL(server.URL)
+		o.Retryer = retry.AddWithMaxBackoffDelay(retry.NewStandard(func(so *retry.StandardOptions) {
+			so.MaxAttempts = retryMaxAttempts
+		}), time.Millisecond)
+	})
+}
+
+func TestCallMiddlewaresRetryThrottledCalls(t *testing.T) {
+	for _, tc := range []struct {
+		name             string
+		throttled        int
+		expectedRequests int
+		expectedClass    ErrorClass
+	}{
+		{
+			name:             "throttled calls are retried",
+			throttled:        2,
+			expectedRequests: 3,
+		},
+		{
+			name:             "throttled calls fail after the maximum attempts",
+			throttled:        retryMaxAttempts,
+			expectedRequests: retryMaxAttempts,
+			expectedClass:    ThrottlingErrorClass,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ec2Server := &testEC2Server{throttled: tc.throttled}
+			server := httptest.NewServer(ec2Server)
+			defer server.Close()
+			client := newTestEC2Client(t, server)
+
+			throttledAttempts := testutil.ToFloat64(attemptsTotal.WithLabelValues("EC2", "DescribeVpcs", string(ThrottlingErrorClass)))
+			_, err := client.DescribeVpcs(context.Background(), &ec2.DescribeVpcsInput{})
+			if tc.expectedClass == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tc.expectedClass != "" {
+				var awsErr *Error
+				if !errors.As(err, &awsErr) {
+					t.Fatalf("expected typed error, got %v", err)
+				}
+				if awsErr.Class != tc.expectedClass || awsErr.Action != "ec2:DescribeVpcs" {
+					t.Errorf("expected error class %q of ec2:DescribeVpcs, got %q of %s", tc.expectedClass, awsErr.Class, awsErr.Action)
+				}
+			}
+			if ec2Server.requests != tc.expectedRequests {
+				t.Errorf("expected %d requests, got %d", tc.expectedRequests, ec2Server.requests)
+			}
+			throttledAttempts = testutil.ToFloat64(attemptsTotal.WithLabelValues("EC2", "DescribeVpcs", string(ThrottlingErrorClass))) - throttledAttempts
+			if int(throttledAttempts) != tc.throttled {
+				t.Errorf("expected %d throttled attempts in the metrics, got %v", tc.throttled, throttledAttempts)
+			}
+		})
+	}
+}
+
+func TestCallMiddlewaresCallBudget(t *testing.T) {
+	ec2Server := &testEC2Server{throttled: 2}
+	server := httptest.NewServer(ec2Server)
+	defer server.Close()
+	client := newTestEC2Client(t, server)
+
+	// the budget is used by the throttled attempts of the first call
+	ctx := WithCallBudget(context.Background(), 3)
+	if _, err := client.DescribeVpcs(ctx, &ec2.DescribeVpcsInput{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err := client.DescribeVpcs(ctx, &ec2.DescribeVpcsInput{})
+	if !errors.Is(err, ErrCallBudgetExceeded) {
+		t.Fatalf("expected call budget error, got %v", err)
+	}
+	if class := ClassifyError(err); class != CallBudgetErrorClass {
+		t.Errorf("expected error class %q, got %q", CallBudgetErrorClass, class)
+	}
+	if ec2Server.requests != 3 {
+		t.Errorf("expected 3 requests, got %d", ec2Server.requests)
+	}
+
+	// a new budget allows the calls again
+	if _, err := client.DescribeVpcs(WithCallBudget(context.Background(), 1), &ec2.DescribeVpcsInput{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+func TestNewRetryer(t *testing.T) {
+	retryer := newRetryer()
+	if _, ok := retryer.(*retry.AdaptiveMode); !ok {
+		t.Errorf("expected adaptive retryer, got %T", retryer)
+	}
+	if retryer.MaxAttempts() != retryMaxAttempts {
+		t.Errorf("expected %d maximum attempts, got %d", retryMaxAttempts, retryer.MaxAttempts())
+	}
+}
@@ -0,0 +1,60 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+)
+
+const (
+	// maxTaggedResourcesPerCall is the maximum number of resources of a CreateTags or DeleteTags call
+	maxTaggedResourcesPerCall = 1000
+	// maxTagsPerResource is the maximum number of tags of an EC2 resource
+	maxTagsPerResource = 50
+)
+
+// CreateTags adds the tags to the resources. The resources are tagged in batches which don't exceed the limits of
+// the CreateTags API.
+func CreateTags(ctx context.Context, client SubnetClient, resources []string, tags []ec2types.Tag) error {
+	if len(tags) > maxTagsPerResource {
+		return fmt.Errorf("%d tags exceed the limit of %d tags per resource", len(tags), maxTagsPerResource)
+	}
+	for _, batch := range resourceBatches(resources) {
+		if _, err := client.CreateTags(ctx, &ec2.CreateTagsInput{
+			Resources: batch,
+			Tags:      tags,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteTags removes the tags from the resources. The resources are untagged in batches which don't exceed the limits
+// of the DeleteTags API.
+func DeleteTags(ctx context.Context, client SubnetClient, resources []string, tags []ec2types.Tag) error {
+	for _, batch := range resourceBatches(resources) {
+		if _, err := client.DeleteTags(ctx, &ec2.DeleteTagsInput{
+			Resources: batch,
+			Tags:      tags,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resourceBatches splits the resources into batches of at most maxTaggedResourcesPerCall resources.
+func resourceBatches(resources []string) [][]string {
+	var batches [][]string
+	for len(resources) > maxTaggedResourcesPerCall {
+		batches = append(batches, resources[:maxTaggedResourcesPerCall])
+		resources = resources[maxTaggedResourcesPerCall:]
+	}
+	if len(resources) > 0 {
+		batches = append(batches, resources)
+	}
+	return batches
+}
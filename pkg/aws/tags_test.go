@@ -0,0 +1,103 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/google/go-cmp/cmp"
+)
+
+// testTaggingClient records the number of resources of the tagging calls.
+type testTaggingClient struct {
+	SubnetClient
+	createTagsCalls []int
+	deleteTagsCalls []int
+}
+
+func (c *testTaggingClient) CreateTags(_ context.Context, input *ec2.CreateTagsInput, _ ...func(*ec2.Options)) (*ec2.CreateTagsOutput, error) {
+	c.createTagsCalls = append(c.createTagsCalls, len(input.Resources))
+	return &ec2.CreateTagsOutput{}, nil
+}
+
+func (c *testTaggingClient) DeleteTags(_ context.Context, input *ec2.DeleteTagsInput, _ ...func(*ec2.Options)) (*ec2.DeleteTagsOutput, error) {
+	c.deleteTagsCalls = append(c.deleteTagsCalls, len(input.Resources))
+	return &ec2.DeleteTagsOutput{}, nil
+}
+
+func testSubnetIDs(n int) []string {
+	ids := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		ids = append(ids, fmt.Sprintf("subnet-%d", i))
+	}
+	return ids
+}
+
+func testTags(n int) []ec2types.Tag {
+	tags := make([]ec2types.Tag, 0, n)
+	for i := 0; i < n; i++ {
+		tags = append(tags, ec2types.Tag{Key: aws.String(fmt.Sprintf("key-%d", i)), Value: aws.String("value")})
+	}
+	return tags
+}
+
+func TestTaggingBatches(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		resources     int
+		tags          int
+		expectedCalls []int
+		expectedError string
+	}{
+		{
+			name:          "single batch",
+			resources:     3,
+			tags:          2,
+			expectedCalls: []int{3},
+		},
+		{
+			name:          "resources split into batches",
+			resources:     2500,
+			tags:          2,
+			expectedCalls: []int{1000, 1000, 500},
+		},
+		{
+			name:      "no resources",
+			resources: 0,
+			tags:      2,
+		},
+		{
+			name:          "too many tags",
+			resources:     3,
+			tags:          51,
+			expectedError: "51 tags exceed the limit of 50 tags per resource",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &testTaggingClient{}
+			err := CreateTags(context.Background(), client, testSubnetIDs(tc.resources), testTags(tc.tags))
+			if tc.expectedError != "" {
+				if err == nil || err.Error() != tc.expectedError {
+					t.Fatalf("expected error %q, got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expectedCalls, client.createTagsCalls); diff != "" {
+				t.Errorf("unexpected CreateTags calls (-want +got):\n%s", diff)
+			}
+
+			if err := DeleteTags(context.Background(), client, testSubnetIDs(tc.resources), testTags(tc.tags)); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expectedCalls, client.deleteTagsCalls); diff != "" {
+				t.Errorf("unexpected DeleteTags calls (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,36 @@
+package awsloadbalancercontroller
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+const (
+	// fieldManager is the field manager of the server-side apply patches of the operator
+	fieldManager = "aws-load-balancer-operator"
+)
+
+// apply creates or updates the resource with a server-side apply patch of the operator field manager. The operator
+// owns the fields set in the desired object: their drift is corrected and they are removed from the resource once
+// they are no longer desired. The fields set by others, like the CA bundle injected into the webhook configurations,
+// are left unchanged. The desired object is labelled as managed by the operator and is updated with the resource
+// returned by the API server.
+func (r *AWSLoadBalancerControllerReconciler) apply(ctx context.Context, desired client.Object) error {
+	gvk, err := apiutil.GVKForObject(desired, r.Scheme)
+	if err != nil {
+		return err
+	}
+	// the apply patch is the serialized object which needs its apiVersion and kind
+	desired.GetObjectKind().SetGroupVersionKind(gvk)
+	desired.SetManagedFields(nil)
+	// the label selects the resource in the cache of the operator
+	labels := desired.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[managedByLabelKey] = managedByLabelValue
+	desired.SetLabels(labels)
+	return r.Patch(ctx, desired, client.Apply, client.FieldOwner(fieldManager), client.ForceOwnership)
+}
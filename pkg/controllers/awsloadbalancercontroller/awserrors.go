@@ -0,0 +1,54 @@
+package awsloadbalancercontroller
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/openshift/aws-load-balancer-operator/pkg/aws"
+)
+
+const (
+	// operatorCredentials and operandCredentials name the credentials of the AWS calls in the remediation hints
+	operatorCredentials = "operator credentials"
+	operandCredentials  = "operand credentials"
+)
+
+// awsErrorReasons are the condition reasons of the AWS errors of the classes
+var awsErrorReasons = map[aws.ErrorClass]string{
+	aws.UnauthorizedErrorClass:        "AWSUnauthorized",
+	aws.ThrottlingErrorClass:          "AWSThrottled",
+	aws.NotFoundErrorClass:            "AWSResourceNotFound",
+	aws.InvalidParameterErrorClass:    "AWSInvalidParameter",
+	aws.EndpointUnreachableErrorClass: "AWSEndpointUnreachable",
+	aws.CallBudgetErrorClass:          "AWSCallBudgetExceeded",
+}
+
+// awsErrorRequeueDurations are the delays to re-enqueue after the AWS errors of the classes. The errors which need
+// a fix of the credentials or of the spec are retried less often, the changes of either trigger a reconcile anyway.
+var awsErrorRequeueDurations = map[aws.ErrorClass]time.Duration{
+	aws.UnauthorizedErrorClass:        5 * time.Minute,
+	aws.ThrottlingErrorClass:          2 * time.Minute,
+	aws.NotFoundErrorClass:            time.Minute,
+	aws.InvalidParameterErrorClass:    10 * time.Minute,
+	aws.EndpointUnreachableErrorClass: 30 * time.Second,
+	aws.CallBudgetErrorClass:          2 * time.Minute,
+}
+
+// awsErrorReasonAndMessage returns the condition reason and message of the error of an AWS call made with the given
+// credentials. The reason is given by the class of the error, the default reason is returned for the other
+// errors. The message starts with a remediation hint.
+func awsErrorReasonAndMessage(err error, defaultReason, credentials string) (string, string) {
+	class := aws.ClassifyError(err)
+	reason, ok := awsErrorReasons[class]
+	if !ok {
+		return defaultReason, err.Error()
+	}
+	return reason, fmt.Sprintf("%s: %v", aws.Remediation(err, credentials), err)
+}
+
+// awsErrorRequeueDuration returns the delay to re-enqueue after the error of an AWS call. False is returned for the
+// errors which are not classified, they are retried with the backoff of the controller.
+func awsErrorRequeueDuration(err error) (time.Duration, bool) {
+	duration, ok := awsErrorRequeueDurations[aws.ClassifyError(err)]
+	return duration, ok
+}
@@ -0,0 +1,93 @@
+package awsloadbalancercontroller
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/aws/smithy-go"
+
+	"github.com/openshift/aws-load-balancer-operator/pkg/aws"
+)
+
+func TestAWSErrorRequeueDuration(t *testing.T) {
+	for _, tc := range []struct {
+		name             string
+		err              error
+		expectedDuration time.Duration
+		expectedOK       bool
+	}{
+		{
+			name:             "throttled",
+			err:              fmt.Errorf("failed to list subnets: %w", &smithy.GenericAPIError{Code: "RequestLimitExceeded"}),
+			expectedDuration: 2 * time.Minute,
+			expectedOK:       true,
+		},
+		{
+			name:             "unauthorized",
+			err:              &aws.Error{Class: aws.UnauthorizedErrorClass, Err: errors.New("forbidden")},
+			expectedDuration: 5 * time.Minute,
+			expectedOK:       true,
+		},
+		{
+			name:             "endpoint unreachable",
+			err:              &aws.Error{Class: aws.EndpointUnreachableErrorClass, Err: errors.New("connection refused")},
+			expectedDuration: 30 * time.Second,
+			expectedOK:       true,
+		},
+		{
+			name: "unclassified",
+			err:  errors.New("no subnets found"),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			duration, ok := awsErrorRequeueDuration(tc.err)
+			if ok != tc.expectedOK || duration != tc.expectedDuration {
+				t.Errorf("expected requeue after %v (%t), got %v (%t)", tc.expectedDuration, tc.expectedOK, duration, ok)
+			}
+		})
+	}
+}
+
+func TestAWSErrorReasonAndMessage(t *testing.T) {
+	for _, tc := range []struct {
+		name            string
+		err             error
+		credentials     string
+		expectedReason  string
+		expectedMessage string
+	}{
+		{
+			name:            "operand credentials not found",
+			err:             &aws.Error{Class: aws.NotFoundErrorClass, Action: "iam:SimulatePrincipalPolicy", Resources: []string{"arn:aws:iam::123456789012:user/operand"}, Err: errors.New("api error NoSuchEntity")},
+			credentials:     operandCredentials,
+			expectedReason:  "AWSResourceNotFound",
+			expectedMessage: "iam:SimulatePrincipalPolicy did not find arn:aws:iam::123456789012:user/operand, check that the resources exist in the region of the cluster: api error NoSuchEntity",
+		},
+		{
+			name:            "invalid parameter",
+			err:             &aws.Error{Class: aws.InvalidParameterErrorClass, Action: "ec2:DescribeVpcs", Err: errors.New("api error InvalidVpcID.Malformed")},
+			credentials:     operatorCredentials,
+			expectedReason:  "AWSInvalidParameter",
+			expectedMessage: "ec2:DescribeVpcs was called with an invalid parameter, check the spec of the AWSLoadBalancerController: api error InvalidVpcID.Malformed",
+		},
+		{
+			name:            "unclassified",
+			err:             errors.New("multiple VPCs found"),
+			credentials:     operatorCredentials,
+			expectedReason:  "Failed",
+			expectedMessage: "multiple VPCs found",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			reason, message := awsErrorReasonAndMessage(tc.err, "Failed", tc.credentials)
+			if reason != tc.expectedReason {
+				t.Errorf("expected reason %q, got %q", tc.expectedReason, reason)
+			}
+			if message != tc.expectedMessage {
+				t.Errorf("expected message %q, got %q", tc.expectedMessage, message)
+			}
+		})
+	}
+}
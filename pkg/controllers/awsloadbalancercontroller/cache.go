@@ -0,0 +1,69 @@
+package awsloadbalancercontroller
+
+import (
+	arv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+
+	configv1 "github.com/openshift/api/config/v1"
+	cco "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// managedByLabelKey is the label of the resources applied by the operator, the cache only holds the labelled
+	// resources of the operand
+	managedByLabelKey   = "app.kubernetes.io/managed-by"
+	managedByLabelValue = "aws-load-balancer-operator"
+)
+
+// NewCache returns the cache of the operator. The cache is scoped to the resources read and watched by the operator:
+// the resources of the operand which are labelled by the operator, the secrets and the configmaps of the operator
+// namespace and the cluster-wide Infrastructure and Proxy. The endpoint slices of the operand service inherit its
+// labels.
+func NewCache(namespace string) cache.NewCacheFunc {
+	inNamespace := fields.OneTermEqualSelector("metadata.namespace", namespace)
+	managedBy := labels.SelectorFromSet(labels.Set{managedByLabelKey: managedByLabelValue})
+	operandInNamespace := cache.ObjectSelector{Label: managedBy, Field: inNamespace}
+	operand := cache.ObjectSelector{Label: managedBy}
+	cluster := cache.ObjectSelector{Field: fields.OneTermEqualSelector("metadata.name", clusterInfrastructureName)}
+
+	return cache.BuilderWithOptions(cache.Options{
+		SelectorsByObject: cache.SelectorsByObject{
+			&corev1.Secret{}:                       {Field: inNamespace},
+			&corev1.ConfigMap{}:                    {Field: inNamespace},
+			&corev1.ServiceAccount{}:               operandInNamespace,
+			&corev1.Service{}:                      operandInNamespace,
+			&networkingv1.NetworkPolicy{}:          operandInNamespace,
+			&appsv1.Deployment{}:                   operandInNamespace,
+			&policyv1.PodDisruptionBudget{}:        operandInNamespace,
+			&discoveryv1.EndpointSlice{}:           operandInNamespace,
+			&monitoringv1.ServiceMonitor{}:         operandInNamespace,
+			&monitoringv1.PrometheusRule{}:         operandInNamespace,
+			&rbacv1.Role{}:                         operandInNamespace,
+			&rbacv1.RoleBinding{}:                  operandInNamespace,
+			&rbacv1.ClusterRoleBinding{}:           operand,
+			&networkingv1.IngressClass{}:           operand,
+			&arv1.ValidatingWebhookConfiguration{}: operand,
+			&arv1.MutatingWebhookConfiguration{}:   operand,
+			&cco.CredentialsRequest{}:              {Label: managedBy, Field: fields.OneTermEqualSelector("metadata.namespace", credentialRequestNamespace)},
+			&configv1.Infrastructure{}:             cluster,
+			&configv1.Proxy{}:                      cluster,
+		},
+	})
+}
+
+// UncachedObjects returns the objects which are read from the API server by the client of the operator. The operator
+// is only allowed to get the cluster role of the operand, it can't watch the cluster roles.
+func UncachedObjects() []client.Object {
+	return []client.Object{&rbacv1.ClusterRole{}}
+}
@@ -0,0 +1,92 @@
+package awsloadbalancercontroller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	albo "github.com/openshift/aws-load-balancer-operator/api/v1alpha1"
+	"github.com/openshift/aws-load-balancer-operator/pkg/controllers/utils/test"
+)
+
+// BenchmarkReconcileAPIRequests measures the API requests of the reconciles of the ingress class, credentials and
+// operand controllers once the operand is deployed. With the cached reads only the writes and the reads of the
+// uncached objects reach the API server, the reads are served by the fake client which stands in for the cache.
+func BenchmarkReconcileAPIRequests(b *testing.B) {
+	for _, bc := range []struct {
+		name   string
+		cached bool
+	}{
+		{
+			name: "live reads",
+		},
+		{
+			name:   "cached reads",
+			cached: true,
+		},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			ctx := context.Background()
+			fakeClient := test.NewApplyClient(fake.NewClientBuilder().WithScheme(test.Scheme).WithObjects(
+				&albo.AWSLoadBalancerController{
+					ObjectMeta: metav1.ObjectMeta{Name: controllerName},
+					Spec:       albo.AWSLoadBalancerControllerSpec{IngressClass: "alb"},
+				},
+				&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: credentialsSecretPrefix + controllerName, Namespace: test.OperatorNamespace}},
+				testPreExistingClusterRole(),
+				testInjectedTrustedCABundle(),
+			).Build())
+			apiServer := test.NewCountingClient(fakeClient)
+			var c client.Client = apiServer
+			if bc.cached {
+				var err error
+				c, err = client.NewDelegatingClient(client.NewDelegatingClientInput{
+					CacheReader:     fakeClient,
+					Client:          apiServer,
+					UncachedObjects: UncachedObjects(),
+				})
+				if err != nil {
+					b.Fatalf("failed to make cached client: %v", err)
+				}
+			}
+			r := &AWSLoadBalancerControllerReconciler{
+				Client:      c,
+				APIReader:   apiServer,
+				Scheme:      test.Scheme,
+				Namespace:   test.OperatorNamespace,
+				Image:       test.OperandImage,
+				ClusterName: "test-cluster",
+				AWSRegion:   "us-east-1",
+				VPCID:       "test-vpc",
+			}
+			reconcilers := []interface {
+				Reconcile(context.Context, ctrl.Request) (ctrl.Result, error)
+			}{&ingressClassReconciler{r}, &credentialsReconciler{r}, &operandReconciler{r}}
+			req := ctrl.Request{NamespacedName: types.NamespacedName{Name: controllerName}}
+
+			reconcile := func() {
+				for _, reconciler := range reconcilers {
+					if _, err := reconciler.Reconcile(ctx, req); err != nil {
+						b.Fatalf("unexpected error: %v", err)
+					}
+				}
+			}
+			// the first reconciles deploy the operand
+			reconcile()
+
+			requests := apiServer.Requests()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				reconcile()
+			}
+			b.ReportMetric(float64(apiServer.Requests()-requests)/float64(b.N), "requests/op")
+		})
+	}
+}
@@ -20,24 +20,20 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
-	arv1 "k8s.io/api/admissionregistration/v1"
-	appsv1 "k8s.io/api/apps/v1"
-	corev1 "k8s.io/api/core/v1"
-	rbacv1 "k8s.io/api/rbac/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 
-	cco "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
-
 	ctrl "sigs.k8s.io/controller-runtime"
-	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	albo "github.com/openshift/aws-load-balancer-operator/api/v1alpha1"
 	"github.com/openshift/aws-load-balancer-operator/pkg/aws"
@@ -46,17 +42,36 @@ import (
 const (
 	// the name of the AWSLoadBalancerController resource which will be reconciled
 	controllerName = "cluster"
-	// the port on which controller metrics are served
+	// the port on which controller metrics are served, it's bound to the loopback interface of the pod
 	controllerMetricsPort = 8080
+	// the port on which the kube-rbac-proxy sidecar serves the controller metrics over TLS
+	metricsProxyPort = 8443
 	// the port on which the controller webhook is served
 	controllerWebhookPort = 9443
 	// common prefix for all resource of an operand
 	controllerResourcePrefix = "aws-load-balancer-controller"
 	// secretMissingReEnqueueDuration is the delay to re-enqueue.
 	secretMissingReEnqueueDuration = time.Second * 30
+	// trustedCABundleMissingReEnqueueDuration is the delay to re-enqueue when the trusted CA bundle is not injected.
+	trustedCABundleMissingReEnqueueDuration = time.Second * 30
+	// webhooksNotReadyReEnqueueDuration is the delay to re-enqueue when the webhooks are not ready.
+	webhooksNotReadyReEnqueueDuration = time.Second * 30
+	// vpcDiscoveryReEnqueueDuration is the delay to re-enqueue when the VPC was not found.
+	// The platform discovery failures are re-enqueued with the rate limited backoff of the controller.
+	vpcDiscoveryReEnqueueDuration = time.Minute
+	// credentialsRecheckInterval is the interval after which the credentials are checked again when neither the
+	// credentials secret nor the AWSLoadBalancerController changed.
+	credentialsRecheckInterval = time.Hour
+	// awsCallBudget is the maximum number of AWS API attempts of a reconcile, including the retries of the throttled calls
+	awsCallBudget = 100
+	// prefix of the name of the secret with the AWS credentials minted from the CredentialsRequest
+	credentialsSecretPrefix = controllerResourcePrefix + "-credentialsrequest-"
+	// prefix of the name of the secret with the webhook serving certificate
+	servingSecretPrefix = controllerResourcePrefix + "-serving-"
 )
 
-// AWSLoadBalancerControllerReconciler reconciles a AWSLoadBalancerController object
+// AWSLoadBalancerControllerReconciler holds the state shared by the sub-controllers which reconcile a
+// AWSLoadBalancerController object
 type AWSLoadBalancerControllerReconciler struct {
 	client.Client
 	Scheme      *runtime.Scheme
@@ -64,17 +79,63 @@ type AWSLoadBalancerControllerReconciler struct {
 	Image       string
 	EC2Client   aws.EC2Client
 	ClusterName string
-	VPCID       string
-	AWSRegion   string
+	// VPCID is the VPC discovered with the cluster tag, it's discovered on reconcile when empty
+	VPCID     string
+	AWSRegion string
+	// AWSServiceEndpoints maps the AWS service names to the URLs which override the default endpoints
+	AWSServiceEndpoints map[string]string
+	// KubeRBACProxyImage is the image of the sidecar which authorizes the scrapes of the operand metrics
+	KubeRBACProxyImage string
+	// WebhookDryRun enables the dry-run admission of an Ingress in the readiness check of the operand webhooks
+	WebhookDryRun bool
+	// Topology is the topology of the cluster which gives the defaults of the operand deployment
+	Topology ClusterTopology
+	// IAMClient simulates the IAM policies of the operand credentials, the simulation is skipped when it's nil
+	IAMClient aws.IAMClient
+	// NewSTSClient returns an STSClient with the given access keys to get the principal of the operand credentials
+	NewSTSClient func(ctx context.Context, accessKeyID, secretAccessKey string) (aws.STSClient, error)
+	// DiscoverPlatform discovers the cluster details and the AWS clients on every reconcile, the fields above are
+	// updated when they change and must be set when it's nil
+	DiscoverPlatform PlatformDiscoveryFunc
+	// APIReader reads from the API server the resources whose cached copy may not have the changes just written
+	// by the operator, the client is used when it's nil
+	APIReader client.Reader
+
+	// platformLock guards the discovery of the platform and of the VPC, which are shared by the sub-controllers
+	platformLock sync.Mutex
+	// platform is the last discovered platform
+	platform *Platform
+	// discoveryErr is the error of the last discovery of the platform reported by the readiness check, it has its
+	// own lock so that the check doesn't wait for a discovery in progress
+	discoveryErr  error
+	discoveryLock sync.Mutex
+	// specVPC is the VPC last selected with spec.vpcID or spec.vpcSelector, it's looked up again when the spec or
+	// the platform change
+	specVPC *cachedSpecVPC
+
+	// monitoringUnavailable is set when the monitoring.coreos.com CRDs were not installed when the operator started,
+	// the monitoring resources of the operand are then not reconciled
+	monitoringUnavailable bool
+
+	// credentialsCheckLock guards the last result of the pre-flight check of the credentials, which is reused until
+	// its inputs change
+	credentialsCheckLock sync.Mutex
+	credentialsCheck     *cachedCredentialsCheck
 }
 
 //+kubebuilder:rbac:groups=networking.olm.openshift.io,resources=awsloadbalancercontrollers,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=networking.olm.openshift.io,resources=awsloadbalancercontrollers/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=networking.olm.openshift.io,resources=awsloadbalancercontrollers/finalizers,verbs=update
-//+kubebuilder:rbac:groups="",resources=services;secrets,namespace=system,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="",resources=services;secrets;configmaps,namespace=system,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="networking.k8s.io",resources=ingressclasses,verbs=get;list;watch;create;update;patch;delete
-//+kubebuilder:rbac:groups="config.openshift.io",resources=infrastructures,verbs=get;list;watch
+//+kubebuilder:rbac:groups="networking.k8s.io",resources=ingresses,namespace=system,verbs=create
+//+kubebuilder:rbac:groups="networking.k8s.io",resources=networkpolicies,namespace=system,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="config.openshift.io",resources=infrastructures;proxies,verbs=get;list;watch
 //+kubebuilder:rbac:groups="apps",resources=deployments,namespace=system,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="policy",resources=poddisruptionbudgets,namespace=system,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="discovery.k8s.io",resources=endpointslices,namespace=system,verbs=get;list;watch
+//+kubebuilder:rbac:groups="monitoring.coreos.com",resources=servicemonitors;prometheusrules,namespace=system,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="cert-manager.io",resources=issuers;certificates,namespace=system,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=serviceaccounts,namespace=system,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=rbac.authorization.k8s.io,namespace=system,resources=roles;rolebindings,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterrolebindings,verbs=get;list;watch;create;update;patch;delete
@@ -82,138 +143,96 @@ type AWSLoadBalancerControllerReconciler struct {
 //+kubebuilder:rbac:groups=cloudcredential.openshift.io,resources=credentialsrequests;credentialsrequests/status;credentialsrequests/finalizers,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=admissionregistration.k8s.io,resources=validatingwebhookconfigurations;mutatingwebhookconfigurations,verbs=get;list;watch;create;update;patch;delete
 
-func (r *AWSLoadBalancerControllerReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	logger := log.FromContext(ctx)
-
-	lbController, exists, err := r.getAWSLoadBalancerController(ctx, req.Name)
-	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("failed to get AWSLoadBalancerController %q: %w", req.Name, err)
-	}
-	if !exists {
-		return ctrl.Result{}, nil
-	}
-
-	if lbController.DeletionTimestamp != nil {
-		logger.Info("AWSLoadBalancerController is going to be deleted. Skipping Reconcile")
-		return ctrl.Result{}, nil
-
-	}
-
-	servingSecretName := fmt.Sprintf("%s-serving-%s", controllerResourcePrefix, lbController.Name)
-
-	// if the processed subnets have not yet been written into the status or if the tagging policy has changed then update the subnets
-	if lbController.Status.Subnets == nil || (lbController.Spec.SubnetTagging != lbController.Status.Subnets.SubnetTagging) {
-		internalSubnets, publicSubnets, untaggedSubnets, taggedSubnets, err := r.tagSubnets(ctx, lbController)
-		if err != nil {
-			return ctrl.Result{}, fmt.Errorf("failed to update subnets: %w", err)
-		}
-		err = r.updateStatusSubnets(ctx, lbController, internalSubnets, publicSubnets, untaggedSubnets, taggedSubnets, lbController.Spec.SubnetTagging)
-		if err != nil {
-			return ctrl.Result{}, fmt.Errorf("failed to update AWSLoadBalancerController %q status with subnets: %w", req.Name, err)
-		}
-		// reload the resource after updating the status
-		lbController, _, err = r.getAWSLoadBalancerController(ctx, req.Name)
-		if err != nil {
-			return ctrl.Result{}, fmt.Errorf("failed to get AWSLoadBalancerController %q: %w", req.Name, err)
-		}
-	}
-
-	if err := r.ensureIngressClass(ctx, lbController); err != nil {
-		return ctrl.Result{}, fmt.Errorf("failed to ensure default IngressClass for AWSLoadBalancerController %q: %v", req.Name, err)
-	}
-	// if the ingress class in the status differs from what's in the spec update it
-	if lbController.Spec.IngressClass != lbController.Status.IngressClass {
-		err = r.updateStatusIngressClass(ctx, lbController, lbController.Spec.IngressClass)
-		if err != nil {
-			return ctrl.Result{}, fmt.Errorf("failed to update IngressClass in AWSLoadBalancerController %q Status: %w", req.Name, err)
-		}
-		// reload the resource after updating the status
-		lbController, _, err = r.getAWSLoadBalancerController(ctx, req.Name)
-		if err != nil {
-			return ctrl.Result{}, fmt.Errorf("failed to get AWSLoadBalancerController %q: %w", req.Name, err)
+// reconciledController returns the AWSLoadBalancerController reconciled by the sub-controllers, read with the given
+// reader. Nil is returned if it doesn't exist or is being deleted.
+func (r *AWSLoadBalancerControllerReconciler) reconciledController(ctx context.Context, reader client.Reader, name string) (*albo.AWSLoadBalancerController, error) {
+	lbController := &albo.AWSLoadBalancerController{}
+	if err := reader.Get(ctx, types.NamespacedName{Name: name}, lbController); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
 		}
+		return nil, fmt.Errorf("failed to get AWSLoadBalancerController %q: %w", name, err)
 	}
-
-	var credentialsRequest *cco.CredentialsRequest
-	if credentialsRequest, err = r.ensureCredentialsRequest(ctx, r.Namespace, lbController); err != nil {
-		return ctrl.Result{}, fmt.Errorf("failed to ensure CredentialsRequest for AWSLoadBalancerController %q: %w", req.Name, err)
+	if lbController.DeletionTimestamp != nil {
+		log.FromContext(ctx).Info("AWSLoadBalancerController is going to be deleted. Skipping Reconcile")
+		return nil, nil
 	}
+	return lbController, nil
+}
 
-	secretProvisioned, err := r.credentialsSecretProvisioned(ctx, credentialsRequest)
-	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("failed to verify credentials secret %q for AWSLoadBalancerController %q has been provisioned: %w", credentialsRequest.Spec.SecretRef.Name, req.Name, err)
+// apiReader returns the reader of the API server.
+func (r *AWSLoadBalancerControllerReconciler) apiReader() client.Reader {
+	if r.APIReader != nil {
+		return r.APIReader
 	}
+	return r.Client
+}
 
-	// updating CR status
-	if err := r.updateControllerStatus(ctx, lbController, nil, credentialsRequest, secretProvisioned); err != nil {
-		return ctrl.Result{}, fmt.Errorf("failed to update status of AWSLoadBalancerController %q: %w", req.Name, err)
+// SetupWithManager sets up the sub-controllers of the AWSLoadBalancerController with the Manager. The subnet tagging,
+// the ingress class, the credentials and the operand workload are reconciled by separate controllers, so that a
+// failure of one of them doesn't block the others. Each controller reports its own conditions. The controllers only
+// reconcile the spec changes of the AWSLoadBalancerController, so that the status written by one of them doesn't
+// re-enqueue the others.
+func (r *AWSLoadBalancerControllerReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := (&subnetTaggingReconciler{r}).SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("failed to set up %s controller: %w", subnetTaggingControllerName, err)
 	}
-
-	// re-enqueue if secret is not provisioned
-	if !secretProvisioned {
-		// retrying after delay to ensure secret provisioning.
-		logger.Info("(Retrying) failed to ensure secret from credentials request", "secret", credentialsRequest.Spec.SecretRef.Name)
-		return ctrl.Result{RequeueAfter: secretMissingReEnqueueDuration}, nil
+	if err := (&ingressClassReconciler{r}).SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("failed to set up %s controller: %w", ingressClassControllerName, err)
 	}
-
-	sa, err := r.ensureControllerServiceAccount(ctx, r.Namespace, lbController)
-	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("failed to ensure AWSLoadBalancerController %q service account: %w", req.Name, err)
+	if err := (&credentialsReconciler{r}).SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("failed to set up %s controller: %w", credentialsControllerName, err)
 	}
-
-	err = r.ensureClusterRoleAndBinding(ctx, sa, lbController)
-	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("failed to ensure ClusterRole and Binding for AWSLoadBalancerController %q: %w", req.Name, err)
+	if err := (&operandReconciler{r}).SetupWithManager(mgr); err != nil {
+		return fmt.Errorf("failed to set up %s controller: %w", operandControllerName, err)
 	}
+	return nil
+}
 
-	deployment, err := r.ensureDeployment(ctx, r.Namespace, r.Image, sa, credentialsRequest.Spec.SecretRef.Name, servingSecretName, lbController)
-	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("failed to ensure Deployment for AWSLoadbalancerController %q: %w", req.Name, err)
+// proxyToControllerRequests maps the cluster-wide Proxy to the AWSLoadBalancerController so that
+// the proxy configuration of the operand is updated when the cluster proxy changes.
+func proxyToControllerRequests(o client.Object) []reconcile.Request {
+	if o.GetName() != clusterProxyName {
+		return nil
 	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: controllerName}}}
+}
 
-	service, err := r.ensureService(ctx, r.Namespace, lbController, servingSecretName, deployment)
-	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("failed to ensure service for AWSLoadBalancerController %q: %w", req.Name, err)
+// infrastructureToControllerRequests maps the cluster-wide Infrastructure to the AWSLoadBalancerController so that
+// the default tags of the operand are updated when the user-defined resource tags change and the platform
+// discovery is retried when the Infrastructure status is filled.
+func infrastructureToControllerRequests(o client.Object) []reconcile.Request {
+	if o.GetName() != clusterInfrastructureName {
+		return nil
 	}
+	return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: controllerName}}}
+}
 
-	err = r.ensureWebhooks(ctx, lbController, service)
-	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("failed to ensure webhooks for AWSLoadBalancerController %q: %w", req.Name, err)
+// secretToControllerRequests maps the credentials and serving secrets of an operand to the AWSLoadBalancerController
+// which mounts them. This ensures that the rotation of either secret rolls out the operand deployment.
+func (r *AWSLoadBalancerControllerReconciler) secretToControllerRequests(o client.Object) []reconcile.Request {
+	if o.GetNamespace() != r.Namespace {
+		return nil
 	}
-
-	if err := r.updateControllerStatus(ctx, lbController, deployment, credentialsRequest, secretProvisioned); err != nil {
-		return ctrl.Result{}, fmt.Errorf("failed to update status of AWSLoadBalancerController %q: %w", req.Name, err)
+	for _, prefix := range []string{credentialsSecretPrefix, servingSecretPrefix} {
+		if name := strings.TrimPrefix(o.GetName(), prefix); name != o.GetName() && name != "" {
+			return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: name}}}
+		}
 	}
-	return ctrl.Result{}, nil
+	return nil
 }
 
-func (r *AWSLoadBalancerControllerReconciler) getAWSLoadBalancerController(ctx context.Context, name string) (*albo.AWSLoadBalancerController, bool, error) {
-	var controller albo.AWSLoadBalancerController
-	controllerKey := types.NamespacedName{Name: name}
-	err := r.Get(ctx, controllerKey, &controller)
-	if err != nil && errors.IsNotFound(err) {
-		return nil, false, nil
+// endpointSliceToControllerRequests maps the endpoint slices of the service of an operand to the
+// AWSLoadBalancerController so that the availability of the webhook is reported when its endpoints change.
+func (r *AWSLoadBalancerControllerReconciler) endpointSliceToControllerRequests(o client.Object) []reconcile.Request {
+	if o.GetNamespace() != r.Namespace {
+		return nil
 	}
-	if err != nil {
-		return nil, false, err
+	service := o.GetLabels()[discoveryv1.LabelServiceName]
+	if name := strings.TrimPrefix(service, controllerResourcePrefix+"-"); name != service && name != "" {
+		return []reconcile.Request{{NamespacedName: types.NamespacedName{Name: name}}}
 	}
-	return &controller, true, nil
-}
-
-// SetupWithManager sets up the controller with the Manager.
-func (r *AWSLoadBalancerControllerReconciler) SetupWithManager(mgr ctrl.Manager) error {
-	return ctrl.NewControllerManagedBy(mgr).
-		For(&albo.AWSLoadBalancerController{}, builder.WithPredicates(reconcileClusterNamedResource())).
-		Owns(&cco.CredentialsRequest{}).
-		Owns(&corev1.ServiceAccount{}).
-		Owns(&rbacv1.ClusterRoleBinding{}).
-		Owns(&rbacv1.Role{}).
-		Owns(&rbacv1.RoleBinding{}).
-		Owns(&appsv1.Deployment{}).
-		Owns(&corev1.Service{}).
-		Owns(&arv1.ValidatingWebhookConfiguration{}).
-		Owns(&arv1.MutatingWebhookConfiguration{}).
-		Complete(r)
+	return nil
 }
 
 func reconcileClusterNamedResource() predicate.Funcs {
@@ -0,0 +1,125 @@
+package awsloadbalancercontroller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	cco "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	albo "github.com/openshift/aws-load-balancer-operator/api/v1alpha1"
+	"github.com/openshift/aws-load-balancer-operator/pkg/aws"
+)
+
+const (
+	// credentialsControllerName is the name of the controller which ensures the credentials of the operand
+	credentialsControllerName = "credentials"
+)
+
+// credentialsReconciler ensures the CredentialsRequest of the operand and checks the AWS permissions of the operator
+// and the operand. It reports the CredentialsSecretAvailable and CredentialsValid conditions. The reconcile is
+// re-enqueued while the credentials secret is not provisioned, after the AWS errors of the check and after the
+// recheck interval of the credentials.
+type credentialsReconciler struct {
+	*AWSLoadBalancerControllerReconciler
+}
+
+func (r *credentialsReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	ctx = aws.WithCallBudget(ctx, awsCallBudget)
+
+	lbController, err := r.reconciledController(ctx, r.Client, req.Name)
+	if err != nil || lbController == nil {
+		return ctrl.Result{}, err
+	}
+
+	// the partition of the policy and the clients of the check are given by the platform, its discovery failures
+	// are reported by the subnet tagging controller
+	if err := r.ensurePlatform(ctx); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to discover platform: %w", err)
+	}
+
+	credentialsRequest, err := r.ensureCredentialsRequest(ctx, r.Namespace, lbController)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to ensure CredentialsRequest for AWSLoadBalancerController %q: %w", req.Name, err)
+	}
+
+	secretProvisioned, err := r.credentialsSecretProvisioned(ctx, credentialsRequest.Spec.SecretRef)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to verify credentials secret %q for AWSLoadBalancerController %q has been provisioned: %w", credentialsRequest.Spec.SecretRef.Name, req.Name, err)
+	}
+
+	// pre-flight check of the AWS permissions, the missing permissions are reported in the status
+	check, err := r.cachedCheckCredentials(ctx, lbController, credentialsRequest, secretProvisioned)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to check credentials for AWSLoadBalancerController %q: %w", req.Name, err)
+	}
+	credentialsValid := credentialsValidCondition(check, lbController.Generation)
+	if credentialsValid.Status != metav1.ConditionTrue {
+		logger.Info("pre-flight check of the AWS credentials did not pass", "reason", credentialsValid.Reason, "message", credentialsValid.Message)
+	}
+
+	status := &controllerStatus{}
+	status.addConditions(credentialRequestsConditions(credentialsRequest.Spec.SecretRef.Name, secretProvisioned, lbController.Generation)...)
+	status.addConditions(credentialsValid)
+	if err := r.writeStatus(ctx, credentialsControllerName, lbController, status); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// re-enqueue if secret is not provisioned
+	if !secretProvisioned {
+		// retrying after delay to ensure secret provisioning.
+		logger.Info("(Retrying) failed to ensure secret from credentials request", "secret", credentialsRequest.Spec.SecretRef.Name)
+		return ctrl.Result{RequeueAfter: secretMissingReEnqueueDuration}, nil
+	}
+	// the check is retried after a delay given by the class of its AWS error
+	if check.err != nil {
+		if requeueAfter, ok := awsErrorRequeueDuration(check.err); ok {
+			return ctrl.Result{RequeueAfter: requeueAfter}, nil
+		}
+		return ctrl.Result{}, nil
+	}
+	// the result of the check is reused until the recheck interval, the permissions may change in AWS meanwhile
+	return ctrl.Result{RequeueAfter: credentialsRecheckInterval}, nil
+}
+
+// SetupWithManager sets up the credentials controller with the Manager. The credentials secrets are watched to
+// check the credentials once they are minted or rotated. Besides the spec changes, the status changes of the
+// AWSLoadBalancerController are only reconciled when they change the subnet on which the operator credentials are
+// checked.
+func (r *credentialsReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(credentialsControllerName).
+		For(&albo.AWSLoadBalancerController{}, builder.WithPredicates(reconcileClusterNamedResource(), predicate.Or(predicate.GenerationChangedPredicate{}, clusterSubnetChanged()))).
+		Owns(&cco.CredentialsRequest{}).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, handler.EnqueueRequestsFromMapFunc(r.secretToControllerRequests)).
+		Complete(r)
+}
+
+// clusterSubnetChanged returns a predicate which passes the updates of the AWSLoadBalancerController which change the
+// cluster subnet found by the subnet tagging.
+func clusterSubnetChanged() predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldController, ok := e.ObjectOld.(*albo.AWSLoadBalancerController)
+			if !ok {
+				return false
+			}
+			newController, ok := e.ObjectNew.(*albo.AWSLoadBalancerController)
+			if !ok {
+				return false
+			}
+			return clusterSubnetID(oldController) != clusterSubnetID(newController)
+		},
+	}
+}
@@ -3,7 +3,7 @@ package awsloadbalancercontroller
 import (
 	"context"
 	"fmt"
-	"reflect"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -17,24 +17,13 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	albo "github.com/openshift/aws-load-balancer-operator/api/v1alpha1"
+	"github.com/openshift/aws-load-balancer-operator/pkg/aws"
 )
 
 const (
 	credentialRequestNamespace = "openshift-cloud-credential-operator"
 )
 
-// currentCredentialsRequest returns true if credentials request exists.
-func (r *AWSLoadBalancerControllerReconciler) currentCredentialsRequest(ctx context.Context, name types.NamespacedName) (bool, *cco.CredentialsRequest, error) {
-	cr := &cco.CredentialsRequest{}
-	if err := r.Client.Get(ctx, name, cr); err != nil {
-		if errors.IsNotFound(err) {
-			return false, nil, nil
-		}
-		return false, nil, err
-	}
-	return true, cr, nil
-}
-
 // ensureCredentialsRequest ensures the CredentialsRequest resource and return the secret where the credentials will be written
 func (r *AWSLoadBalancerControllerReconciler) ensureCredentialsRequest(ctx context.Context, namespace string, controller *albo.AWSLoadBalancerController) (*cco.CredentialsRequest, error) {
 	name := fmt.Sprintf("%s-%s", controllerResourcePrefix, controller.Name)
@@ -43,17 +32,12 @@ func (r *AWSLoadBalancerControllerReconciler) ensureCredentialsRequest(ctx conte
 	reqLogger := log.FromContext(ctx).WithValues("credentialsrequest", credReq)
 	reqLogger.Info("ensuring credentials secret for aws-load-balancer-controller instance")
 
-	exists, current, err := r.currentCredentialsRequest(ctx, credReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get existing credentials request %q: %w", credReq.Name, err)
-	}
-
-	credentialRequestSecretName := fmt.Sprintf("%s-credentialsrequest-%s", controllerResourcePrefix, controller.Name)
+	credentialRequestSecretName := credentialsSecretPrefix + controller.Name
 
 	// The secret created will be in the operator namespace.
 	secretRef := createCredentialsSecretRef(credentialRequestSecretName, namespace)
 
-	desired, err := desiredCredentialsRequest(credReq, secretRef, name)
+	desired, err := desiredCredentialsRequest(credReq, secretRef, name, aws.GetPartition(r.AWSRegion))
 	if err != nil {
 		return nil, fmt.Errorf("failed to build desired credentials request: %w", err)
 	}
@@ -63,26 +47,18 @@ func (r *AWSLoadBalancerControllerReconciler) ensureCredentialsRequest(ctx conte
 		return nil, fmt.Errorf("failed to set owner reference on desired credentials request: %w", err)
 	}
 
-	if !exists {
-		if err := r.createCredentialsRequest(ctx, desired); err != nil {
-			return nil, fmt.Errorf("failed to create credentials request %s: %w", desired.Name, err)
-		}
-	} else if err := r.updateCredentialsRequest(ctx, current, desired); err != nil {
-		return nil, fmt.Errorf("failed to update credentials request %q: %w", credReq.Name, err)
-	}
-
-	_, current, err = r.currentCredentialsRequest(ctx, credReq)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get existing credentials request %q: %w", credReq.Name, err)
+	if err := r.apply(ctx, desired); err != nil {
+		return nil, fmt.Errorf("failed to apply credentials request %q: %w", credReq.Name, err)
 	}
-	return current, nil
+	return desired, nil
 }
 
-func (r *AWSLoadBalancerControllerReconciler) credentialsSecretProvisioned(ctx context.Context, cr *cco.CredentialsRequest) (bool, error) {
-	name := types.NamespacedName{Name: cr.Spec.SecretRef.Name, Namespace: cr.Spec.SecretRef.Namespace}
+// credentialsSecretProvisioned returns true if the secret of the CredentialsRequest exists.
+func (r *AWSLoadBalancerControllerReconciler) credentialsSecretProvisioned(ctx context.Context, secretRef corev1.ObjectReference) (bool, error) {
+	name := types.NamespacedName{Name: secretRef.Name, Namespace: secretRef.Namespace}
 	var secret corev1.Secret
 
-	err := r.Client.Get(context.TODO(), name, &secret)
+	err := r.Client.Get(ctx, name, &secret)
 	if err != nil && errors.IsNotFound(err) {
 		log.FromContext(ctx).Info("failed to get secret associated with credentials request", "secret", name)
 		return false, nil
@@ -93,34 +69,7 @@ func (r *AWSLoadBalancerControllerReconciler) credentialsSecretProvisioned(ctx c
 	return true, nil
 }
 
-func (r *AWSLoadBalancerControllerReconciler) createCredentialsRequest(ctx context.Context, desired *cco.CredentialsRequest) error {
-	if err := r.Client.Create(ctx, desired); err != nil {
-		return err
-	}
-	return nil
-}
-
-// updateCredentialsRequest updates the CredentialsRequest if needed
-func (r *AWSLoadBalancerControllerReconciler) updateCredentialsRequest(ctx context.Context, current *cco.CredentialsRequest, desired *cco.CredentialsRequest) error {
-	var updated *cco.CredentialsRequest
-	changed, err := isCredentialsRequestChanged(current, desired)
-	if err != nil {
-		return err
-	}
-	if !changed {
-		return nil
-	}
-	updated = current.DeepCopy()
-	updated.Name = desired.Name
-	updated.Namespace = desired.Namespace
-	updated.Spec = desired.Spec
-	if err := r.Client.Update(ctx, updated); err != nil {
-		return err
-	}
-	return nil
-}
-
-func desiredCredentialsRequest(name types.NamespacedName, secretRef corev1.ObjectReference, saName string) (*cco.CredentialsRequest, error) {
+func desiredCredentialsRequest(name types.NamespacedName, secretRef corev1.ObjectReference, saName, partition string) (*cco.CredentialsRequest, error) {
 	credentialsRequest := &cco.CredentialsRequest{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name.Name,
@@ -137,7 +86,7 @@ func desiredCredentialsRequest(name types.NamespacedName, secretRef corev1.Objec
 		return nil, err
 	}
 
-	providerSpec, err := createProviderConfig(codec)
+	providerSpec, err := createProviderConfig(codec, partition)
 	if err != nil {
 		return nil, err
 	}
@@ -145,12 +94,30 @@ func desiredCredentialsRequest(name types.NamespacedName, secretRef corev1.Objec
 	return credentialsRequest, nil
 }
 
-func createProviderConfig(codec *cco.ProviderCodec) (*runtime.RawExtension, error) {
+func createProviderConfig(codec *cco.ProviderCodec, partition string) (*runtime.RawExtension, error) {
 	return codec.EncodeProviderSpec(&cco.AWSProviderSpec{
-		StatementEntries: GetIAMPolicy().Statement,
+		StatementEntries: partitionStatementEntries(GetIAMPolicy().Statement, partition),
 	})
 }
 
+// partitionStatementEntries returns a copy of the statement entries whose resource ARNs are in the given partition.
+// The IAM policy is written for the commercial partition ("arn:aws:...") which is replaced with the partition of
+// the cluster's region, e.g. "arn:aws-us-gov:..." or "arn:aws-cn:...".
+func partitionStatementEntries(statements []cco.StatementEntry, partition string) []cco.StatementEntry {
+	commercialPrefix := fmt.Sprintf("arn:%s:", aws.DefaultPartition)
+	partitionPrefix := fmt.Sprintf("arn:%s:", partition)
+
+	partitioned := make([]cco.StatementEntry, 0, len(statements))
+	for _, statement := range statements {
+		s := *statement.DeepCopy()
+		if strings.HasPrefix(s.Resource, commercialPrefix) {
+			s.Resource = partitionPrefix + strings.TrimPrefix(s.Resource, commercialPrefix)
+		}
+		partitioned = append(partitioned, s)
+	}
+	return partitioned
+}
+
 // createCredentialsRequestName will always return a fixed namespaced resource, so as to
 // make it future-proof. The credentials operator will have limitations in the future, wrt watched namespaces.
 func createCredentialsRequestName(name string) types.NamespacedName {
@@ -166,36 +133,3 @@ func createCredentialsSecretRef(name string, namespace string) corev1.ObjectRefe
 		Namespace: namespace,
 	}
 }
-
-func isCredentialsRequestChanged(current, desired *cco.CredentialsRequest) (bool, error) {
-	if current.Name != desired.Name {
-		return true, nil
-	}
-
-	if current.Namespace != desired.Namespace {
-		return true, nil
-	}
-
-	codec, err := cco.NewCodec()
-	if err != nil {
-		return false, err
-	}
-
-	currentAwsSpec := cco.AWSProviderSpec{}
-	err = codec.DecodeProviderSpec(current.Spec.ProviderSpec, &currentAwsSpec)
-	if err != nil {
-		return false, err
-	}
-
-	desiredAwsSpec := cco.AWSProviderSpec{}
-	err = codec.DecodeProviderSpec(desired.Spec.ProviderSpec, &desiredAwsSpec)
-	if err != nil {
-		return false, err
-	}
-
-	if !(reflect.DeepEqual(currentAwsSpec, desiredAwsSpec)) {
-		return true, nil
-	}
-
-	return false, nil
-}
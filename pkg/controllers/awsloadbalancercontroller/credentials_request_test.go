@@ -3,6 +3,7 @@ package awsloadbalancercontroller
 import (
 	"context"
 	"fmt"
+	"os"
 	"testing"
 	"time"
 
@@ -16,6 +17,7 @@ import (
 	"github.com/google/go-cmp/cmp"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/yaml"
 
 	albo "github.com/openshift/aws-load-balancer-operator/api/v1alpha1"
 	"github.com/openshift/aws-load-balancer-operator/pkg/controllers/utils/test"
@@ -82,9 +84,9 @@ func TestEnsureCredentialsRequest(t *testing.T) {
 
 	for _, tc := range testcases {
 		t.Run(tc.name, func(t *testing.T) {
-			cl := fake.NewClientBuilder().WithScheme(test.Scheme).
+			cl := test.NewApplyClient(fake.NewClientBuilder().WithScheme(test.Scheme).
 				WithRuntimeObjects(tc.existingObjects...).
-				Build()
+				Build())
 
 			r := &AWSLoadBalancerControllerReconciler{
 				Client:    cl,
@@ -144,15 +146,18 @@ func testPartialCredentialsRequest() *cco.CredentialsRequest {
 
 func testCompleteCredentialsRequest() *cco.CredentialsRequest {
 	codec, _ := cco.NewCodec()
-	cfg, _ := createProviderConfig(codec)
+	cfg, _ := createProviderConfig(codec, "aws")
 	return &cco.CredentialsRequest{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      "aws-load-balancer-controller-cluster",
-			Namespace: testCredentialsRequestNamespace,
+			Name:            "aws-load-balancer-controller-cluster",
+			Namespace:       testCredentialsRequestNamespace,
+			Labels:          map[string]string{managedByLabelKey: managedByLabelValue},
+			OwnerReferences: testControllerReferences(controllerName),
 		},
 		Spec: cco.CredentialsRequestSpec{
-			ProviderSpec: cfg,
-			SecretRef:    createCredentialsSecretRef("aws-load-balancer-controller-credentialsrequest-cluster", test.OperatorNamespace),
+			ServiceAccountNames: []string{"aws-load-balancer-controller-cluster"},
+			ProviderSpec:        cfg,
+			SecretRef:           createCredentialsSecretRef("aws-load-balancer-controller-credentialsrequest-cluster", test.OperatorNamespace),
 		},
 	}
 }
@@ -162,3 +167,97 @@ func testAWSProviderSpec() *runtime.RawExtension {
 	providerSpec, _ := codec.EncodeProviderSpec(&cco.AWSProviderSpec{})
 	return providerSpec
 }
+
+func TestPartitionStatementEntries(t *testing.T) {
+	statements := []cco.StatementEntry{
+		{
+			Effect:   "Allow",
+			Action:   []string{"ec2:DescribeSubnets"},
+			Resource: "*",
+		},
+		{
+			Effect:   "Allow",
+			Action:   []string{"ec2:CreateTags"},
+			Resource: "arn:aws:ec2:*:*:security-group/*",
+		},
+	}
+	for _, tc := range []struct {
+		name              string
+		partition         string
+		expectedResources []string
+	}{
+		{
+			name:              "commercial partition",
+			partition:         "aws",
+			expectedResources: []string{"*", "arn:aws:ec2:*:*:security-group/*"},
+		},
+		{
+			name:              "GovCloud partition",
+			partition:         "aws-us-gov",
+			expectedResources: []string{"*", "arn:aws-us-gov:ec2:*:*:security-group/*"},
+		},
+		{
+			name:              "China partition",
+			partition:         "aws-cn",
+			expectedResources: []string{"*", "arn:aws-cn:ec2:*:*:security-group/*"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			partitioned := partitionStatementEntries(statements, tc.partition)
+			var resources []string
+			for _, s := range partitioned {
+				resources = append(resources, s.Resource)
+			}
+			if diff := cmp.Diff(tc.expectedResources, resources); diff != "" {
+				t.Errorf("unexpected resources:\n%s", diff)
+			}
+			if statements[1].Resource != "arn:aws:ec2:*:*:security-group/*" {
+				t.Errorf("original statements were modified")
+			}
+		})
+	}
+}
+
+// TestGeneratedCredentialsRequest verifies that the CredentialsRequest generated by iamctl
+// for the STS workflows matches the one created by the operator.
+func TestGeneratedCredentialsRequest(t *testing.T) {
+	manifest, err := os.ReadFile("../../../hack/controller/controller-credentials-request.yaml")
+	if err != nil {
+		t.Fatalf("failed to read generated credentials request: %v", err)
+	}
+	var generated cco.CredentialsRequest
+	if err := yaml.Unmarshal(manifest, &generated); err != nil {
+		t.Fatalf("failed to parse generated credentials request: %v", err)
+	}
+
+	name := fmt.Sprintf("%s-%s", controllerResourcePrefix, controllerName)
+	desired, err := desiredCredentialsRequest(createCredentialsRequestName(name), createCredentialsSecretRef(credentialsSecretPrefix+controllerName, "aws-load-balancer-operator"), name, "aws")
+	if err != nil {
+		t.Fatalf("failed to build desired credentials request: %v", err)
+	}
+
+	if diff := cmp.Diff(desired.ObjectMeta, generated.ObjectMeta); diff != "" {
+		t.Errorf("generated credentials request metadata differs:\n%s", diff)
+	}
+	if diff := cmp.Diff(desired.Spec.SecretRef, generated.Spec.SecretRef); diff != "" {
+		t.Errorf("generated credentials request secret differs:\n%s", diff)
+	}
+	if diff := cmp.Diff(desired.Spec.ServiceAccountNames, generated.Spec.ServiceAccountNames); diff != "" {
+		t.Errorf("generated credentials request service accounts differ:\n%s", diff)
+	}
+
+	codec, err := cco.NewCodec()
+	if err != nil {
+		t.Fatalf("failed to create codec: %v", err)
+	}
+	var desiredSpec, generatedSpec cco.AWSProviderSpec
+	if err := codec.DecodeProviderSpec(desired.Spec.ProviderSpec, &desiredSpec); err != nil {
+		t.Fatalf("failed to decode desired provider spec: %v", err)
+	}
+	if err := codec.DecodeProviderSpec(generated.Spec.ProviderSpec, &generatedSpec); err != nil {
+		t.Fatalf("failed to decode generated provider spec: %v", err)
+	}
+	if diff := cmp.Diff(desiredSpec.StatementEntries, generatedSpec.StatementEntries); diff != "" {
+		t.Errorf("generated credentials request statements differ, run make generate:\n%s", diff)
+	}
+}
@@ -2,6 +2,9 @@ package awsloadbalancercontroller
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	goerrors "errors"
 	"fmt"
 	"sort"
 	"strings"
@@ -11,13 +14,14 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/utils/pointer"
 
-	"github.com/google/go-cmp/cmp"
-	"github.com/google/go-cmp/cmp/cmpopts"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
+	configv1 "github.com/openshift/api/config/v1"
+
 	albo "github.com/openshift/aws-load-balancer-operator/api/v1alpha1"
 )
 
@@ -46,52 +50,74 @@ const (
 	boundSATokenVolumeName = "bound-sa-token"
 	// boundSATokenDir is the sa token directory
 	boundSATokenDir = "/var/run/secrets/openshift/serviceaccount"
+	// trustedCABundleVolumeName is the name of the volume with the trusted CA bundle
+	trustedCABundleVolumeName = "trusted-ca-bundle"
+	// trustedCABundleDir is the directory from which the system trust bundle is loaded
+	trustedCABundleDir = "/etc/pki/ca-trust/extracted/pem"
+	// trustedCABundlePath is the file name of the system trust bundle
+	trustedCABundlePath = "tls-ca-bundle.pem"
 	// all capabilities in the pod security context
 	allCapabilities = "ALL"
+	// credentialsSecretHashAnnotation is the pod template annotation with the hash of the AWS credentials secret.
+	// A change in the hash rolls out the deployment so that the controller picks up rotated credentials.
+	credentialsSecretHashAnnotation = "networking.olm.openshift.io/credentials-secret-hash"
+	// servingSecretHashAnnotation is the pod template annotation with the hash of the webhook serving secret.
+	// A change in the hash rolls out the deployment so that the controller picks up the rotated certificate.
+	servingSecretHashAnnotation = "networking.olm.openshift.io/serving-secret-hash"
+	// trustedCABundleHashAnnotation is the pod template annotation with the hash of the trusted CA bundle.
+	trustedCABundleHashAnnotation = "networking.olm.openshift.io/trusted-ca-bundle-hash"
+	// controllerContainerName is the name of the container of the controller
+	controllerContainerName = "controller"
+	// defaultTagsArg is the argument of the controller with the tags added to all the AWS resources
+	defaultTagsArg = "--default-tags="
+	// metricsProxyContainerName is the name of the sidecar which serves the metrics of the controller over TLS
+	metricsProxyContainerName = "kube-rbac-proxy"
 )
 
-func (r *AWSLoadBalancerControllerReconciler) ensureDeployment(ctx context.Context, namespace, image string, sa *corev1.ServiceAccount, crSecretName, servingSecretName string, controller *albo.AWSLoadBalancerController) (*appsv1.Deployment, error) {
+func (r *AWSLoadBalancerControllerReconciler) ensureDeployment(ctx context.Context, namespace, image, vpcID string, sa *corev1.ServiceAccount, crSecretName, servingSecretName string, trustedCABundle *corev1.ConfigMap, controller *albo.AWSLoadBalancerController, config operandConfig) (*appsv1.Deployment, error) {
 	deploymentName := fmt.Sprintf("%s-%s", controllerResourcePrefix, controller.Name)
 
 	reqLogger := log.FromContext(ctx).WithValues("deployment", deploymentName)
 	reqLogger.Info("ensuring deployment for aws-load-balancer-controller instance")
 
-	exists, current, err := r.currentDeployment(ctx, deploymentName, namespace)
+	templateAnnotations, err := r.secretHashAnnotations(ctx, namespace, crSecretName, servingSecretName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get existing deployment %s: %w", deploymentName, err)
+		return nil, fmt.Errorf("failed to compute secret hashes for deployment %s: %w", deploymentName, err)
+	}
+	if caBundle, ok := trustedCABundle.Data[trustedCABundleKey]; ok {
+		if templateAnnotations == nil {
+			templateAnnotations = make(map[string]string)
+		}
+		templateAnnotations[trustedCABundleHashAnnotation] = dataHash(map[string][]byte{trustedCABundleKey: []byte(caBundle)})
 	}
 
-	desired := desiredDeployment(deploymentName, namespace, image, r.VPCID, r.ClusterName, r.AWSRegion, crSecretName, servingSecretName, controller, sa)
-	err = controllerutil.SetControllerReference(controller, desired, r.Scheme)
+	proxy, err := r.currentClusterProxy(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to set owner reference on deployment %s: %w", deploymentName, err)
+		return nil, fmt.Errorf("failed to get cluster proxy for deployment %s: %w", deploymentName, err)
 	}
 
-	if !exists {
-		err = r.createDeployment(ctx, desired)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create deployment %s: %w", deploymentName, err)
-		}
-		_, current, err = r.currentDeployment(ctx, deploymentName, namespace)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get new deployment %s: %w", deploymentName, err)
-		}
-		return current, nil
+	resourceTags, err := r.resourceTags(ctx, controller)
+	if goerrors.Is(err, errTooManyResourceTags) {
+		// the merged tags over the AWS limit are reported in the ResourceTagsValid condition
+		resourceTags, err = r.deployedResourceTags(ctx, namespace, deploymentName)
 	}
-	updated, err := r.updateDeployment(ctx, current, desired)
 	if err != nil {
-		return nil, fmt.Errorf("failed to update existing deployment: %w", err)
+		return nil, fmt.Errorf("failed to get resource tags for deployment %s: %w", deploymentName, err)
 	}
-	if updated {
-		_, current, err = r.currentDeployment(ctx, deploymentName, namespace)
-		if err != nil {
-			return nil, fmt.Errorf("failed to get existing deployment: %w", err)
-		}
+
+	desired := desiredDeployment(deploymentName, namespace, image, r.KubeRBACProxyImage, vpcID, r.ClusterName, r.AWSRegion, r.AWSServiceEndpoints, resourceTags, crSecretName, servingSecretName, trustedCABundle.Name, controller, config, sa, proxy, templateAnnotations)
+	err = controllerutil.SetControllerReference(controller, desired, r.Scheme)
+	if err != nil {
+		return nil, fmt.Errorf("failed to set owner reference on deployment %s: %w", deploymentName, err)
+	}
+
+	if err := r.apply(ctx, desired); err != nil {
+		return nil, fmt.Errorf("failed to apply deployment %s: %w", deploymentName, err)
 	}
-	return current, nil
+	return desired, nil
 }
 
-func desiredDeployment(name, namespace, image, vpcID, clusterName, awsRegion, credentialsRequestSecretName, servingSecret string, controller *albo.AWSLoadBalancerController, sa *corev1.ServiceAccount) *appsv1.Deployment {
+func desiredDeployment(name, namespace, image, metricsProxyImage, vpcID, clusterName, awsRegion string, awsServiceEndpoints, resourceTags map[string]string, credentialsRequestSecretName, servingSecret, trustedCABundleConfigMap string, controller *albo.AWSLoadBalancerController, config operandConfig, sa *corev1.ServiceAccount, proxy *configv1.ProxyStatus, templateAnnotations map[string]string) *appsv1.Deployment {
 	d := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
@@ -110,14 +136,15 @@ func desiredDeployment(name, namespace, image, vpcID, clusterName, awsRegion, cr
 						appLabelName:    appName,
 						appInstanceName: controller.Name,
 					},
+					Annotations: templateAnnotations,
 				},
 				Spec: corev1.PodSpec{
 					Containers: []corev1.Container{
 						{
-							Name:  "controller",
+							Name:  controllerContainerName,
 							Image: image,
-							Args:  desiredContainerArgs(controller, clusterName, vpcID),
-							Env: []corev1.EnvVar{
+							Args:  desiredContainerArgs(controller, config, clusterName, vpcID, awsServiceEndpoints, resourceTags),
+							Env: append([]corev1.EnvVar{
 								{
 									Name:  awsRegionEnvVarName,
 									Value: awsRegion,
@@ -130,7 +157,7 @@ func desiredDeployment(name, namespace, image, vpcID, clusterName, awsRegion, cr
 									Name:  awsSDKLoadConfigName,
 									Value: "1",
 								},
-							},
+							}, desiredProxyEnvVars(proxy)...),
 							VolumeMounts: []corev1.VolumeMount{
 								{
 									Name:      awsCredentialsVolumeName,
@@ -145,6 +172,11 @@ func desiredDeployment(name, namespace, image, vpcID, clusterName, awsRegion, cr
 									MountPath: boundSATokenDir,
 									ReadOnly:  true,
 								},
+								{
+									Name:      trustedCABundleVolumeName,
+									MountPath: trustedCABundleDir,
+									ReadOnly:  true,
+								},
 							},
 							SecurityContext: &corev1.SecurityContext{
 								Capabilities: &corev1.Capabilities{
@@ -158,6 +190,7 @@ func desiredDeployment(name, namespace, image, vpcID, clusterName, awsRegion, cr
 								},
 							},
 						},
+						desiredMetricsProxyContainer(metricsProxyImage),
 					},
 					ServiceAccountName: sa.Name,
 					Volumes: []corev1.Volume{
@@ -192,35 +225,192 @@ func desiredDeployment(name, namespace, image, vpcID, clusterName, awsRegion, cr
 								},
 							},
 						},
+						{
+							Name: trustedCABundleVolumeName,
+							VolumeSource: corev1.VolumeSource{
+								ConfigMap: &corev1.ConfigMapVolumeSource{
+									LocalObjectReference: corev1.LocalObjectReference{
+										Name: trustedCABundleConfigMap,
+									},
+									Items: []corev1.KeyToPath{
+										{
+											Key:  trustedCABundleKey,
+											Path: trustedCABundlePath,
+										},
+									},
+								},
+							},
+						},
 					},
 				},
 			},
 		},
 	}
-	if controller.Spec.Config != nil && controller.Spec.Config.Replicas != 0 {
-		d.Spec.Replicas = pointer.Int32(controller.Spec.Config.Replicas)
+	d.Spec.Replicas = pointer.Int32(config.replicas)
+	if config.avoidControlPlane {
+		d.Spec.Template.Spec.Affinity = desiredAffinity()
+	}
+	if config.highAvailability {
+		// a new replica is available before an old one is stopped so that the webhook always has an endpoint
+		maxUnavailable, maxSurge := intstr.FromInt(0), intstr.FromInt(1)
+		d.Spec.Strategy = appsv1.DeploymentStrategy{
+			Type: appsv1.RollingUpdateDeploymentStrategyType,
+			RollingUpdate: &appsv1.RollingUpdateDeployment{
+				MaxUnavailable: &maxUnavailable,
+				MaxSurge:       &maxSurge,
+			},
+		}
+		d.Spec.Template.Spec.TopologySpreadConstraints = desiredTopologySpreadConstraints(d.Spec.Selector)
 	}
 	return d
 }
 
-func desiredContainerArgs(controller *albo.AWSLoadBalancerController, clusterName, vpcID string) []string {
+// desiredMetricsProxyContainer returns the kube-rbac-proxy sidecar which serves the metrics of the controller over
+// TLS with the webhook serving certificate. The scrapes are authorized with the bearer token of the scraper, the
+// controller serves the metrics on the loopback interface so that they can't be read without the proxy.
+func desiredMetricsProxyContainer(image string) corev1.Container {
+	return corev1.Container{
+		Name:  metricsProxyContainerName,
+		Image: image,
+		Args: []string{
+			fmt.Sprintf("--secure-listen-address=0.0.0.0:%d", metricsProxyPort),
+			fmt.Sprintf("--upstream=http://127.0.0.1:%d/", controllerMetricsPort),
+			fmt.Sprintf("--tls-cert-file=%s/tls.crt", webhookTLSDir),
+			fmt.Sprintf("--tls-private-key-file=%s/tls.key", webhookTLSDir),
+			"--logtostderr=true",
+		},
+		Ports: []corev1.ContainerPort{
+			{
+				Name:          "metrics",
+				ContainerPort: metricsProxyPort,
+				Protocol:      corev1.ProtocolTCP,
+			},
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      webhookTLSVolumeName,
+				MountPath: webhookTLSDir,
+				ReadOnly:  true,
+			},
+		},
+		SecurityContext: &corev1.SecurityContext{
+			Capabilities: &corev1.Capabilities{
+				Drop: []corev1.Capability{allCapabilities},
+			},
+			Privileged:               pointer.Bool(false),
+			RunAsNonRoot:             pointer.Bool(true),
+			AllowPrivilegeEscalation: pointer.Bool(false),
+			SeccompProfile: &corev1.SeccompProfile{
+				Type: corev1.SeccompProfileTypeRuntimeDefault,
+			},
+		},
+	}
+}
+
+// desiredAffinity returns the affinity which prefers the nodes which are not control plane nodes, the control plane
+// nodes may be schedulable, e.g. on compact clusters.
+func desiredAffinity() *corev1.Affinity {
+	return &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.PreferredSchedulingTerm{
+				{
+					Weight: 100,
+					Preference: corev1.NodeSelectorTerm{
+						MatchExpressions: []corev1.NodeSelectorRequirement{
+							{
+								Key:      controlPlaneNodeLabel,
+								Operator: corev1.NodeSelectorOpDoesNotExist,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// desiredTopologySpreadConstraints returns the constraints which spread the replicas across the zones and the nodes.
+// The zones are a preference as the cluster may have fewer zones than replicas, two replicas are never scheduled
+// on the same node while other nodes are available.
+func desiredTopologySpreadConstraints(selector *metav1.LabelSelector) []corev1.TopologySpreadConstraint {
+	return []corev1.TopologySpreadConstraint{
+		{
+			MaxSkew:           1,
+			TopologyKey:       corev1.LabelTopologyZone,
+			WhenUnsatisfiable: corev1.ScheduleAnyway,
+			LabelSelector:     selector,
+		},
+		{
+			MaxSkew:           1,
+			TopologyKey:       corev1.LabelHostname,
+			WhenUnsatisfiable: corev1.DoNotSchedule,
+			LabelSelector:     selector,
+		},
+	}
+}
+
+// deployedResourceTags returns the resource tags passed to the controller by the current deployment, nil if the
+// deployment doesn't exist yet.
+func (r *AWSLoadBalancerControllerReconciler) deployedResourceTags(ctx context.Context, namespace, name string) (map[string]string, error) {
+	var deployment appsv1.Deployment
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &deployment); err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get deployment %s: %w", name, err)
+	}
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		if container.Name != controllerContainerName {
+			continue
+		}
+		for _, arg := range container.Args {
+			if !strings.HasPrefix(arg, defaultTagsArg) {
+				continue
+			}
+			// the webhook rejects the separators in the tags
+			tags := make(map[string]string)
+			for _, tag := range strings.Split(strings.TrimPrefix(arg, defaultTagsArg), ",") {
+				kv := strings.SplitN(tag, "=", 2)
+				if len(kv) == 2 {
+					tags[kv[0]] = kv[1]
+				}
+			}
+			return tags, nil
+		}
+	}
+	return nil, nil
+}
+
+func desiredContainerArgs(controller *albo.AWSLoadBalancerController, config operandConfig, clusterName, vpcID string, awsServiceEndpoints, resourceTags map[string]string) []string {
 	var args []string
 	args = append(args, fmt.Sprintf("--webhook-cert-dir=%s", webhookTLSDir))
 	args = append(args, fmt.Sprintf("--aws-vpc-id=%s", vpcID))
 	args = append(args, fmt.Sprintf("--cluster-name=%s", clusterName))
+	// the metrics are served over TLS by the kube-rbac-proxy sidecar
+	args = append(args, fmt.Sprintf("--metrics-bind-addr=127.0.0.1:%d", controllerMetricsPort))
+
+	// if custom service endpoints are present then sort them and append it to the arguments
+	if len(awsServiceEndpoints) > 0 {
+		var endpoints []string
+		for name, url := range awsServiceEndpoints {
+			endpoints = append(endpoints, fmt.Sprintf("%s=%s", name, url))
+		}
+		sort.Strings(endpoints)
+		args = append(args, fmt.Sprintf("--aws-api-endpoints=%s", strings.Join(endpoints, ",")))
+	}
 
-	// if additional keys are present then sort them and append it to the arguments
-	if controller.Spec.AdditionalResourceTags != nil {
+	// if resource tags are present then sort them and append it to the arguments
+	if len(resourceTags) > 0 {
 		var tags []string
-		for k, v := range controller.Spec.AdditionalResourceTags {
+		for k, v := range resourceTags {
 			tags = append(tags, fmt.Sprintf("%s=%s", k, v))
 		}
 		sort.Strings(tags)
-		args = append(args, fmt.Sprintf(`--default-tags=%s`, strings.Join(tags, ",")))
+		args = append(args, defaultTagsArg+strings.Join(tags, ","))
 	}
 	args = append(args, "--disable-ingress-class-annotation")
 	args = append(args, "--disable-ingress-group-name-annotation")
-	if controller.Spec.Config != nil && controller.Spec.Config.Replicas > 1 {
+	if config.leaderElection {
 		args = append(args, "--enable-leader-election")
 	}
 	enabledAddons := make(map[albo.AWSAddon]struct{})
@@ -247,206 +437,45 @@ func desiredContainerArgs(controller *albo.AWSLoadBalancerController, clusterNam
 	return args
 }
 
-func (r *AWSLoadBalancerControllerReconciler) currentDeployment(ctx context.Context, name string, namespace string) (bool, *appsv1.Deployment, error) {
-	var deployment appsv1.Deployment
-	err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &deployment)
-	if err != nil {
-		if errors.IsNotFound(err) {
-			return false, nil, nil
-		}
-		return false, nil, err
-	}
-	return true, &deployment, nil
-}
-
-func (r *AWSLoadBalancerControllerReconciler) createDeployment(ctx context.Context, deployment *appsv1.Deployment) error {
-	return r.Create(ctx, deployment)
-}
-
-// updateDeployment updates the deployment if required and indicates if an update actually occurred
-func (r *AWSLoadBalancerControllerReconciler) updateDeployment(ctx context.Context, current, desired *appsv1.Deployment) (bool, error) {
-	updated := current.DeepCopy()
-
-	var outdated bool
-
-	// if replicas don't match then update
-	if desired.Spec.Replicas != nil {
-		if updated.Spec.Replicas == nil {
-			updated.Spec.Replicas = pointer.Int32(*desired.Spec.Replicas)
-			outdated = true
-		}
-		if *desired.Spec.Replicas != *updated.Spec.Replicas {
-			updated.Spec.Replicas = desired.Spec.Replicas
-			outdated = true
-		}
-	}
-
-	// if the desired and current deployment container are not the same then just update
-	if len(desired.Spec.Template.Spec.Containers) != len(updated.Spec.Template.Spec.Containers) {
-		updated.Spec.Template.Spec.Containers = desired.Spec.Template.Spec.Containers
-		outdated = true
-	} else {
-		// for each of the container in the desired deployment ensure the corresponding container in the current matches
-		for _, desiredContainer := range desired.Spec.Template.Spec.Containers {
-			foundIndex := -1
-			for i, currentContainer := range updated.Spec.Template.Spec.Containers {
-				if currentContainer.Name == desiredContainer.Name {
-					foundIndex = i
-					break
-				}
-			}
-			if foundIndex < 0 {
-				return false, fmt.Errorf("deployment %s does not have a container with the name %s", current.Name, desiredContainer.Name)
-			}
-
-			if changed := hasContainerChanged(updated.Spec.Template.Spec.Containers[foundIndex], desiredContainer); changed {
-				updated.Spec.Template.Spec.Containers[foundIndex] = desiredContainer
-				outdated = true
-			}
-		}
-	}
-
-	if haveVolumesChanged(updated.Spec.Template.Spec.Volumes, desired.Spec.Template.Spec.Volumes) {
-		updated.Spec.Template.Spec.Volumes = desired.Spec.Template.Spec.Volumes
-		outdated = true
-	}
-
-	if outdated {
-		err := r.Update(ctx, updated)
+// secretHashAnnotations returns the pod template annotations with the hashes of the credentials and serving secrets.
+// Secrets which are not yet provisioned are skipped, their annotation will be added once they exist.
+func (r *AWSLoadBalancerControllerReconciler) secretHashAnnotations(ctx context.Context, namespace, crSecretName, servingSecretName string) (map[string]string, error) {
+	annotations := make(map[string]string)
+	for annotation, secretName := range map[string]string{
+		credentialsSecretHashAnnotation: crSecretName,
+		servingSecretHashAnnotation:     servingSecretName,
+	} {
+		var secret corev1.Secret
+		err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: secretName}, &secret)
 		if err != nil {
-			return false, fmt.Errorf("failed to update existing deployment %s: %w", updated.Name, err)
-		}
-		return true, nil
-	}
-	return false, nil
-}
-
-// haveVolumesChanged if the current volumes differs from the desired volumes
-func haveVolumesChanged(current []corev1.Volume, desired []corev1.Volume) bool {
-	if len(current) != len(desired) {
-		return true
-	}
-	for i := 0; i < len(current); i++ {
-		cv := current[i]
-		dv := desired[i]
-		if cv.Name != dv.Name {
-			return true
-		}
-		if dv.Secret != nil {
-			if cv.Secret == nil {
-				return true
+			if errors.IsNotFound(err) {
+				continue
 			}
-			if cv.Secret.SecretName != dv.Secret.SecretName {
-				return true
-			}
-		}
-	}
-	return false
-}
-
-func hasContainerChanged(current, desired corev1.Container) bool {
-	if current.Image != desired.Image {
-		return true
-	}
-	if !cmp.Equal(current.Args, desired.Args) {
-		return true
-	}
-
-	if hasSecurityContextChanged(current.SecurityContext, desired.SecurityContext) {
-		return true
-	}
-
-	if len(current.Env) != len(desired.Env) {
-		return true
-	}
-	currentEnvs := indexedContainerEnv(current.Env)
-	for _, e := range desired.Env {
-		if ce, ok := currentEnvs[e.Name]; !ok {
-			return true
-		} else if !cmp.Equal(ce, e) {
-			return true
-		}
-	}
-
-	if len(current.VolumeMounts) != len(desired.VolumeMounts) {
-		return true
-	}
-
-	for i := 0; i < len(current.VolumeMounts); i++ {
-		cvm := current.VolumeMounts[i]
-		dvm := desired.VolumeMounts[i]
-		if cvm.Name != dvm.Name || cvm.MountPath != dvm.MountPath {
-			return true
+			return nil, fmt.Errorf("failed to get secret %s/%s: %w", namespace, secretName, err)
 		}
+		annotations[annotation] = dataHash(secret.Data)
 	}
-
-	return false
-}
-
-func indexedContainerEnv(envs []corev1.EnvVar) map[string]corev1.EnvVar {
-	indexed := make(map[string]corev1.EnvVar)
-	for _, e := range envs {
-		indexed[e.Name] = e
+	if len(annotations) == 0 {
+		return nil, nil
 	}
-	return indexed
+	return annotations, nil
 }
 
-func hasSecurityContextChanged(current, desired *corev1.SecurityContext) bool {
-	if desired == nil {
-		return false
-	}
-
-	if current == nil {
-		return true
-	}
-
-	if desired.Capabilities != nil {
-		if current.Capabilities == nil {
-			return true
-		}
-		cmpCapabilities := cmpopts.SortSlices(func(a, b corev1.Capability) bool { return a < b })
-		if !cmp.Equal(desired.Capabilities.Add, current.Capabilities.Add, cmpCapabilities) {
-			return true
-		}
-
-		if !cmp.Equal(desired.Capabilities.Drop, current.Capabilities.Drop, cmpCapabilities) {
-			return true
-		}
-	}
-
-	if !equalBoolPtr(current.RunAsNonRoot, desired.RunAsNonRoot) {
-		return true
-	}
-
-	if !equalBoolPtr(current.Privileged, desired.Privileged) {
-		return true
-	}
-	if !equalBoolPtr(current.AllowPrivilegeEscalation, desired.AllowPrivilegeEscalation) {
-		return true
-	}
-
-	if desired.SeccompProfile != nil {
-		if current.SeccompProfile == nil {
-			return true
-		}
-		if desired.SeccompProfile.Type != "" && desired.SeccompProfile.Type != current.SeccompProfile.Type {
-			return true
-		}
-	}
-	return false
-}
-
-func equalBoolPtr(current, desired *bool) bool {
-	if desired == nil {
-		return true
-	}
-
-	if current == nil {
-		return false
+// dataHash returns a hash of secret or configmap data which is stable across the ordering of the keys.
+func dataHash(data map[string][]byte) string {
+	keys := make([]string, 0, len(data))
+	for k := range data {
+		keys = append(keys, k)
 	}
+	sort.Strings(keys)
 
-	if *current != *desired {
-		return false
+	hash := sha256.New()
+	for _, k := range keys {
+		// the separators ensure that different key and value splits don't produce the same hash
+		hash.Write([]byte(k))
+		hash.Write([]byte{0})
+		hash.Write(data[k])
+		hash.Write([]byte{0})
 	}
-	return true
+	return hex.EncodeToString(hash.Sum(nil))
 }
@@ -11,10 +11,12 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/utils/pointer"
 
 	"github.com/google/go-cmp/cmp"
+	configv1 "github.com/openshift/api/config/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	albo "github.com/openshift/aws-load-balancer-operator/api/v1alpha1"
@@ -27,9 +29,12 @@ const (
 
 func TestDesiredArgs(t *testing.T) {
 	for _, tc := range []struct {
-		name         string
-		controller   *albo.AWSLoadBalancerController
-		expectedArgs sets.String
+		name                string
+		controller          *albo.AWSLoadBalancerController
+		awsServiceEndpoints map[string]string
+		infraResourceTags   []configv1.AWSResourceTag
+		topology            ClusterTopology
+		expectedArgs        sets.String
 	}{
 		{
 			name: "non-default ingress class",
@@ -43,6 +48,7 @@ func TestDesiredArgs(t *testing.T) {
 				"--enable-waf=false",
 				"--enable-wafv2=false",
 				"--ingress-class=special-ingress-class",
+				"--enable-leader-election",
 			),
 		},
 		{
@@ -60,6 +66,33 @@ func TestDesiredArgs(t *testing.T) {
 				"--enable-leader-election",
 			),
 		},
+		{
+			name: "single replica",
+			controller: &albo.AWSLoadBalancerController{
+				Spec: albo.AWSLoadBalancerControllerSpec{
+					Config: &albo.AWSLoadBalancerDeploymentConfig{Replicas: 1},
+				},
+			},
+			expectedArgs: sets.NewString(
+				"--enable-shield=false",
+				"--enable-waf=false",
+				"--enable-wafv2=false",
+				"--ingress-class=alb",
+			),
+		},
+		{
+			name: "single replica topology",
+			controller: &albo.AWSLoadBalancerController{
+				Spec: albo.AWSLoadBalancerControllerSpec{},
+			},
+			topology: ClusterTopology{ControlPlane: configv1.SingleReplicaTopologyMode, Infrastructure: configv1.SingleReplicaTopologyMode},
+			expectedArgs: sets.NewString(
+				"--enable-shield=false",
+				"--enable-waf=false",
+				"--enable-wafv2=false",
+				"--ingress-class=alb",
+			),
+		},
 		{
 			name: "wafv1 addon enabled",
 			controller: &albo.AWSLoadBalancerController{
@@ -74,6 +107,7 @@ func TestDesiredArgs(t *testing.T) {
 				"--enable-waf=true",
 				"--enable-wafv2=false",
 				"--ingress-class=alb",
+				"--enable-leader-election",
 			),
 		},
 		{
@@ -90,6 +124,7 @@ func TestDesiredArgs(t *testing.T) {
 				"--enable-waf=false",
 				"--enable-wafv2=true",
 				"--ingress-class=alb",
+				"--enable-leader-election",
 			),
 		},
 		{
@@ -106,6 +141,7 @@ func TestDesiredArgs(t *testing.T) {
 				"--enable-waf=false",
 				"--enable-wafv2=false",
 				"--ingress-class=alb",
+				"--enable-leader-election",
 			),
 		},
 		{
@@ -124,9 +160,69 @@ func TestDesiredArgs(t *testing.T) {
 				"--enable-waf=false",
 				"--enable-wafv2=false",
 				"--ingress-class=alb",
+				"--enable-leader-election",
 				"--default-tags=test-key1=test-value1,test-key2=test-value2,test-key3=test-value3",
 			),
 		},
+		{
+			name: "infrastructure resource tags",
+			controller: &albo.AWSLoadBalancerController{
+				Spec: albo.AWSLoadBalancerControllerSpec{},
+			},
+			infraResourceTags: []configv1.AWSResourceTag{
+				{Key: "cost-center", Value: "1234"},
+				{Key: "owner", Value: "team-a"},
+			},
+			expectedArgs: sets.NewString(
+				"--enable-shield=false",
+				"--enable-waf=false",
+				"--enable-wafv2=false",
+				"--ingress-class=alb",
+				"--enable-leader-election",
+				"--default-tags=cost-center=1234,owner=team-a",
+			),
+		},
+		{
+			name: "infrastructure and spec resource tags, spec takes precedence",
+			controller: &albo.AWSLoadBalancerController{
+				Spec: albo.AWSLoadBalancerControllerSpec{
+					AdditionalResourceTags: map[string]string{
+						"owner":    "team-b",
+						"test-key": "test-value",
+					},
+				},
+			},
+			infraResourceTags: []configv1.AWSResourceTag{
+				{Key: "cost-center", Value: "1234"},
+				{Key: "owner", Value: "team-a"},
+			},
+			expectedArgs: sets.NewString(
+				"--enable-shield=false",
+				"--enable-waf=false",
+				"--enable-wafv2=false",
+				"--ingress-class=alb",
+				"--enable-leader-election",
+				"--default-tags=cost-center=1234,owner=team-b,test-key=test-value",
+			),
+		},
+		{
+			name: "custom service endpoints",
+			controller: &albo.AWSLoadBalancerController{
+				Spec: albo.AWSLoadBalancerControllerSpec{},
+			},
+			awsServiceEndpoints: map[string]string{
+				"elasticloadbalancing": "https://elasticloadbalancing.us-gov-west-1.amazonaws.com",
+				"ec2":                  "https://vpce-1234.ec2.us-gov-west-1.vpce.amazonaws.com",
+			},
+			expectedArgs: sets.NewString(
+				"--enable-shield=false",
+				"--enable-waf=false",
+				"--enable-wafv2=false",
+				"--ingress-class=alb",
+				"--enable-leader-election",
+				"--aws-api-endpoints=ec2=https://vpce-1234.ec2.us-gov-west-1.vpce.amazonaws.com,elasticloadbalancing=https://elasticloadbalancing.us-gov-west-1.amazonaws.com",
+			),
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			defaultArgs := sets.NewString(
@@ -134,13 +230,15 @@ func TestDesiredArgs(t *testing.T) {
 				"--cluster-name=test-cluster",
 				"--disable-ingress-class-annotation",
 				"--disable-ingress-group-name-annotation",
+				"--metrics-bind-addr=127.0.0.1:8080",
 				"--webhook-cert-dir=/tls",
 			)
 			expectedArgs := defaultArgs.Union(tc.expectedArgs)
 			if tc.controller.Spec.IngressClass == "" {
 				tc.controller.Spec.IngressClass = "alb"
 			}
-			args := desiredContainerArgs(tc.controller, "test-cluster", "test-vpc")
+			resourceTags := mergeResourceTags(tc.infraResourceTags, tc.controller.Spec.AdditionalResourceTags)
+			args := desiredContainerArgs(tc.controller, desiredOperandConfig(tc.controller, tc.topology), "test-cluster", "test-vpc", tc.awsServiceEndpoints, resourceTags)
 
 			expected := expectedArgs.List()
 			sort.Strings(expected)
@@ -161,6 +259,11 @@ type testDeploymentBuilder struct {
 	ownerReference []metav1.OwnerReference
 	volumes        []corev1.Volume
 	certsSecret    string
+	annotations    map[string]string
+	// highAvailability adds the placement and the rollout strategy of the high availability mode
+	highAvailability bool
+	// metricsProxy adds the kube-rbac-proxy sidecar
+	metricsProxy bool
 }
 
 func testDeployment(name, namespace, serviceAccount string, certsSecret string) *testDeploymentBuilder {
@@ -172,6 +275,18 @@ func (b *testDeploymentBuilder) withReplicas(replicas int32) *testDeploymentBuil
 	return b
 }
 
+// withTopologyDefaults sets the replicas and the placement defaulted on a highly available topology.
+func (b *testDeploymentBuilder) withTopologyDefaults() *testDeploymentBuilder {
+	b.replicas = pointer.Int32(defaultReplicas)
+	b.highAvailability = true
+	return b
+}
+
+func (b *testDeploymentBuilder) withMetricsProxy() *testDeploymentBuilder {
+	b.metricsProxy = true
+	return b
+}
+
 func (b *testDeploymentBuilder) withResourceVersion(version string) *testDeploymentBuilder {
 	b.version = version
 	return b
@@ -183,7 +298,13 @@ func (b *testDeploymentBuilder) withContainers(containers ...corev1.Container) *
 }
 
 func (b *testDeploymentBuilder) withControllerReference(name string) *testDeploymentBuilder {
-	b.ownerReference = []metav1.OwnerReference{
+	b.ownerReference = testControllerReferences(name)
+	return b
+}
+
+// testControllerReferences returns the owner references of the resources of the AWSLoadBalancerController with the given name.
+func testControllerReferences(name string) []metav1.OwnerReference {
+	return []metav1.OwnerReference{
 		{
 			APIVersion:         albo.GroupVersion.Identifier(),
 			Kind:               "AWSLoadBalancerController",
@@ -192,7 +313,6 @@ func (b *testDeploymentBuilder) withControllerReference(name string) *testDeploy
 			BlockOwnerDeletion: pointer.BoolPtr(true),
 		},
 	}
-	return b
 }
 
 func (b *testDeploymentBuilder) withVolumes(volumes ...corev1.Volume) *testDeploymentBuilder {
@@ -200,6 +320,11 @@ func (b *testDeploymentBuilder) withVolumes(volumes ...corev1.Volume) *testDeplo
 	return b
 }
 
+func (b *testDeploymentBuilder) withTemplateAnnotations(annotations map[string]string) *testDeploymentBuilder {
+	b.annotations = annotations
+	return b
+}
+
 func (b *testDeploymentBuilder) build() *appsv1.Deployment {
 	d := &appsv1.Deployment{
 		TypeMeta: metav1.TypeMeta{
@@ -209,6 +334,7 @@ func (b *testDeploymentBuilder) build() *appsv1.Deployment {
 		ObjectMeta: metav1.ObjectMeta{
 			Name:            fmt.Sprintf("%s-%s", controllerResourcePrefix, b.name),
 			Namespace:       "test-namespace",
+			Labels:          map[string]string{managedByLabelKey: managedByLabelValue},
 			OwnerReferences: b.ownerReference,
 		},
 		Spec: appsv1.DeploymentSpec{
@@ -225,6 +351,7 @@ func (b *testDeploymentBuilder) build() *appsv1.Deployment {
 						appInstanceName: b.name,
 						appLabelName:    appName,
 					},
+					Annotations: b.annotations,
 				},
 				Spec: corev1.PodSpec{
 					Containers:         b.containers,
@@ -234,6 +361,37 @@ func (b *testDeploymentBuilder) build() *appsv1.Deployment {
 			},
 		},
 	}
+	if b.highAvailability {
+		maxUnavailable, maxSurge := intstr.FromInt(0), intstr.FromInt(1)
+		d.Spec.Strategy = appsv1.DeploymentStrategy{
+			Type:          appsv1.RollingUpdateDeploymentStrategyType,
+			RollingUpdate: &appsv1.RollingUpdateDeployment{MaxUnavailable: &maxUnavailable, MaxSurge: &maxSurge},
+		}
+		d.Spec.Template.Spec.Affinity = desiredAffinity()
+		d.Spec.Template.Spec.TopologySpreadConstraints = desiredTopologySpreadConstraints(d.Spec.Selector)
+	}
+	if b.metricsProxy {
+		d.Spec.Template.Spec.Containers = append(d.Spec.Template.Spec.Containers, corev1.Container{
+			Name:  "kube-rbac-proxy",
+			Image: test.KubeRBACProxyImage,
+			Args: []string{
+				"--secure-listen-address=0.0.0.0:8443",
+				"--upstream=http://127.0.0.1:8080/",
+				"--tls-cert-file=/tls/tls.crt",
+				"--tls-private-key-file=/tls/tls.key",
+				"--logtostderr=true",
+			},
+			Ports:        []corev1.ContainerPort{{Name: "metrics", ContainerPort: 8443, Protocol: corev1.ProtocolTCP}},
+			VolumeMounts: []corev1.VolumeMount{{Name: "tls", MountPath: "/tls", ReadOnly: true}},
+			SecurityContext: &corev1.SecurityContext{
+				Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+				Privileged:               pointer.BoolPtr(false),
+				RunAsNonRoot:             pointer.BoolPtr(true),
+				AllowPrivilegeEscalation: pointer.BoolPtr(false),
+				SeccompProfile:           &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+			},
+		})
+	}
 	if b.version != "" {
 		d.ResourceVersion = b.version
 	} else {
@@ -259,7 +417,7 @@ func testContainer(name, image string) *testContainerBuilder {
 }
 
 func (b *testContainerBuilder) withEnvs(envs ...corev1.EnvVar) *testContainerBuilder {
-	b.env = envs
+	b.env = append(b.env, envs...)
 	return b
 }
 
@@ -294,17 +452,19 @@ func (b *testContainerBuilder) build() corev1.Container {
 	}
 }
 
-func TestUpdateDeployment(t *testing.T) {
+func TestApplyDeployment(t *testing.T) {
 	for _, tc := range []struct {
-		name               string
-		existingDeployment *appsv1.Deployment
+		name string
+		// appliedDeployment is the deployment previously applied by the operator
+		appliedDeployment *appsv1.Deployment
+		// changedDeployment is the applied deployment once changed by another client
+		changedDeployment  *appsv1.Deployment
 		desiredDeployment  *appsv1.Deployment
 		expectedDeployment *appsv1.Deployment
-		expectUpdate       bool
 	}{
 		{
 			name: "image changed",
-			existingDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
+			appliedDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
 				testContainer("controller", "controller:v1").build(),
 			).build(),
 			desiredDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
@@ -313,11 +473,25 @@ func TestUpdateDeployment(t *testing.T) {
 			expectedDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
 				testContainer("controller", "controller:v2").build(),
 			).build(),
-			expectUpdate: true,
+		},
+		{
+			name: "image drift corrected",
+			appliedDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
+				testContainer("controller", "controller:v1").build(),
+			).build(),
+			changedDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
+				testContainer("controller", "controller:v0").build(),
+			).build(),
+			desiredDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
+				testContainer("controller", "controller:v1").build(),
+			).build(),
+			expectedDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
+				testContainer("controller", "controller:v1").build(),
+			).build(),
 		},
 		{
 			name: "replicas changed from value",
-			existingDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
+			appliedDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
 				testContainer("controller", "controller:v1").build(),
 			).withReplicas(1).build(),
 			desiredDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
@@ -326,11 +500,10 @@ func TestUpdateDeployment(t *testing.T) {
 			expectedDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
 				testContainer("controller", "controller:v1").build(),
 			).withReplicas(2).build(),
-			expectUpdate: true,
 		},
 		{
 			name: "replicas changed from nil",
-			existingDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
+			appliedDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
 				testContainer("controller", "controller:v1").build(),
 			).build(),
 			desiredDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
@@ -339,11 +512,10 @@ func TestUpdateDeployment(t *testing.T) {
 			expectedDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
 				testContainer("controller", "controller:v1").build(),
 			).withReplicas(1).build(),
-			expectUpdate: true,
 		},
 		{
 			name: "container args changed",
-			existingDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
+			appliedDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
 				testContainer("controller", "controller:v1").withArgs("--arg1", "--arg2").build(),
 			).build(),
 			desiredDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
@@ -352,11 +524,10 @@ func TestUpdateDeployment(t *testing.T) {
 			expectedDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
 				testContainer("controller", "controller:v1").withArgs("--arg2", "--arg3").build(),
 			).build(),
-			expectUpdate: true,
 		},
 		{
 			name: "container environment variables changed",
-			existingDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
+			appliedDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
 				testContainer("controller", "controller:v1").withEnvs(
 					corev1.EnvVar{Name: "test-1", Value: "value-1"},
 				).build(),
@@ -387,11 +558,13 @@ func TestUpdateDeployment(t *testing.T) {
 					corev1.EnvVar{Name: "test-2", Value: "value-2"},
 				).build(),
 			).build(),
-			expectUpdate: true,
 		},
 		{
-			name: "container injected into current deployment",
-			existingDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
+			name: "container injected into current deployment is kept",
+			appliedDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
+				testContainer("controller", "controller:v1").build(),
+			).build(),
+			changedDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
 				testContainer("controller", "controller:v1").build(),
 				testContainer("sidecar", "sidecar:v1").build(),
 			).build(),
@@ -400,12 +573,16 @@ func TestUpdateDeployment(t *testing.T) {
 			).build(),
 			expectedDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
 				testContainer("controller", "controller:v1").build(),
+				testContainer("sidecar", "sidecar:v1").build(),
 			).build(),
-			expectUpdate: true,
 		},
 		{
 			name: "desired container removed from deployment",
-			existingDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
+			appliedDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
+				testContainer("controller", "controller:v1").build(),
+				testContainer("sidecar", "sidecar:v1").build(),
+			).build(),
+			changedDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
 				testContainer("sidecar", "sidecar:v1").build(),
 			).build(),
 			desiredDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
@@ -416,11 +593,10 @@ func TestUpdateDeployment(t *testing.T) {
 				testContainer("controller", "controller:v1").build(),
 				testContainer("sidecar", "sidecar:v1").build(),
 			).build(),
-			expectUpdate: true,
 		},
 		{
 			name: "no change in deployment",
-			existingDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
+			appliedDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
 				testContainer("controller", "controller:v1").withArgs("--arg1", "--arg2").withEnvs(
 					corev1.EnvVar{Name: "test-1", Value: "test-1"},
 					corev1.EnvVar{Name: "test-2", Value: "test-2"},
@@ -441,7 +617,7 @@ func TestUpdateDeployment(t *testing.T) {
 		},
 		{
 			name: "volume added",
-			existingDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
+			appliedDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
 				testContainer("controller", "controller:v1").build(),
 			).build(),
 			desiredDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
@@ -454,11 +630,10 @@ func TestUpdateDeployment(t *testing.T) {
 			).withVolumes(
 				corev1.Volume{Name: "test-mount"},
 			).build(),
-			expectUpdate: true,
 		},
 		{
 			name: "volume changed",
-			existingDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
+			appliedDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
 				testContainer("controller", "controller:v1").build(),
 			).withVolumes(
 				corev1.Volume{Name: "test-mount-1"},
@@ -473,11 +648,10 @@ func TestUpdateDeployment(t *testing.T) {
 			).withVolumes(
 				corev1.Volume{Name: "test-mount-2"},
 			).build(),
-			expectUpdate: true,
 		},
 		{
 			name: "volume mount added",
-			existingDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
+			appliedDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
 				testContainer("controller", "controller:v1").withVolumeMounts(
 					corev1.VolumeMount{Name: "config", MountPath: "/opt/config"},
 				).build(),
@@ -494,11 +668,10 @@ func TestUpdateDeployment(t *testing.T) {
 					corev1.VolumeMount{Name: "config", MountPath: "/opt/config"},
 				).build(),
 			).build(),
-			expectUpdate: true,
 		},
 		{
 			name: "volume mount changed",
-			existingDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
+			appliedDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
 				testContainer("controller", "controller:v1").withVolumeMounts(
 					corev1.VolumeMount{Name: "credentials", MountPath: "/opt/credentials"},
 					corev1.VolumeMount{Name: "config", MountPath: "/opt/config"},
@@ -516,11 +689,70 @@ func TestUpdateDeployment(t *testing.T) {
 					corev1.VolumeMount{Name: "config", MountPath: "/var/config"},
 				).build(),
 			).build(),
-			expectUpdate: true,
+		},
+		{
+			name: "secret hash annotation added",
+			appliedDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
+				testContainer("controller", "controller:v1").build(),
+			).build(),
+			desiredDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
+				testContainer("controller", "controller:v1").build(),
+			).withTemplateAnnotations(map[string]string{credentialsSecretHashAnnotation: "hash-1"}).build(),
+			expectedDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
+				testContainer("controller", "controller:v1").build(),
+			).withTemplateAnnotations(map[string]string{credentialsSecretHashAnnotation: "hash-1"}).build(),
+		},
+		{
+			name: "secret hash annotation changed",
+			appliedDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
+				testContainer("controller", "controller:v1").build(),
+			).withTemplateAnnotations(map[string]string{
+				credentialsSecretHashAnnotation: "hash-1",
+				servingSecretHashAnnotation:     "hash-2",
+			}).build(),
+			changedDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
+				testContainer("controller", "controller:v1").build(),
+			).withTemplateAnnotations(map[string]string{
+				credentialsSecretHashAnnotation: "hash-1",
+				servingSecretHashAnnotation:     "hash-2",
+				"test-annotation":               "test-value",
+			}).build(),
+			desiredDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
+				testContainer("controller", "controller:v1").build(),
+			).withTemplateAnnotations(map[string]string{
+				credentialsSecretHashAnnotation: "hash-1",
+				servingSecretHashAnnotation:     "hash-3",
+			}).build(),
+			expectedDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
+				testContainer("controller", "controller:v1").build(),
+			).withTemplateAnnotations(map[string]string{
+				credentialsSecretHashAnnotation: "hash-1",
+				servingSecretHashAnnotation:     "hash-3",
+				"test-annotation":               "test-value",
+			}).build(),
+		},
+		{
+			name: "hash annotation no longer desired",
+			appliedDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
+				testContainer("controller", "controller:v1").build(),
+			).withTemplateAnnotations(map[string]string{
+				credentialsSecretHashAnnotation: "hash-1",
+				trustedCABundleHashAnnotation:   "hash-2",
+			}).build(),
+			desiredDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
+				testContainer("controller", "controller:v1").build(),
+			).withTemplateAnnotations(map[string]string{
+				credentialsSecretHashAnnotation: "hash-1",
+			}).build(),
+			expectedDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
+				testContainer("controller", "controller:v1").build(),
+			).withTemplateAnnotations(map[string]string{
+				credentialsSecretHashAnnotation: "hash-1",
+			}).build(),
 		},
 		{
 			name: "security context added",
-			existingDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
+			appliedDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
 				testContainer("controller", "controller:v1").build(),
 			).build(),
 			desiredDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
@@ -529,11 +761,13 @@ func TestUpdateDeployment(t *testing.T) {
 			expectedDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
 				testContainer("controller", "controller:v1").withSecurityContext(corev1.SecurityContext{RunAsNonRoot: pointer.BoolPtr(true)}).build(),
 			).build(),
-			expectUpdate: true,
 		},
 		{
-			name: "security context changed",
-			existingDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
+			name: "security context drift corrected",
+			appliedDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
+				testContainer("controller", "controller:v1").withSecurityContext(corev1.SecurityContext{RunAsNonRoot: pointer.BoolPtr(true)}).build(),
+			).build(),
+			changedDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
 				testContainer("controller", "controller:v1").withSecurityContext(corev1.SecurityContext{RunAsNonRoot: pointer.BoolPtr(false)}).build(),
 			).build(),
 			desiredDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
@@ -542,11 +776,15 @@ func TestUpdateDeployment(t *testing.T) {
 			expectedDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
 				testContainer("controller", "controller:v1").withSecurityContext(corev1.SecurityContext{RunAsNonRoot: pointer.BoolPtr(true)}).build(),
 			).build(),
-			expectUpdate: true,
 		},
 		{
-			name: "security context is same",
-			existingDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
+			name: "security context field set by another client is kept",
+			appliedDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
+				testContainer("controller", "controller:v1").withSecurityContext(
+					corev1.SecurityContext{RunAsNonRoot: pointer.BoolPtr(true)},
+				).build(),
+			).build(),
+			changedDeployment: testDeployment("operator", "test-namespace", "test-sa", "test-serving").withContainers(
 				testContainer("controller", "controller:v1").withSecurityContext(
 					corev1.SecurityContext{RunAsNonRoot: pointer.BoolPtr(true), ReadOnlyRootFilesystem: pointer.BoolPtr(false)},
 				).build(),
@@ -561,24 +799,32 @@ func TestUpdateDeployment(t *testing.T) {
 					corev1.SecurityContext{RunAsNonRoot: pointer.BoolPtr(true), ReadOnlyRootFilesystem: pointer.BoolPtr(false)},
 				).build(),
 			).build(),
-			expectUpdate: false,
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			ctx := context.Background()
-			client := fake.NewClientBuilder().WithObjects(tc.existingDeployment).Build()
+			client := test.NewApplyClient(fake.NewClientBuilder().WithScheme(test.Scheme).Build())
 			r := &AWSLoadBalancerControllerReconciler{
 				Client: client,
+				Scheme: test.Scheme,
 			}
-			updated, err := r.updateDeployment(ctx, tc.existingDeployment, tc.desiredDeployment)
-			if err != nil {
+			// the desired objects of the operator don't have a resource version
+			tc.appliedDeployment.ResourceVersion = ""
+			tc.desiredDeployment.ResourceVersion = ""
+			if err := r.apply(ctx, tc.appliedDeployment); err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
-			if tc.expectUpdate != updated {
-				t.Errorf("expected update to be %t, instead was %t", tc.expectUpdate, updated)
+			if tc.changedDeployment != nil {
+				tc.changedDeployment.ResourceVersion = tc.appliedDeployment.ResourceVersion
+				if err := client.Update(ctx, tc.changedDeployment); err != nil {
+					t.Fatalf("failed to change deployment: %v", err)
+				}
+			}
+			if err := r.apply(ctx, tc.desiredDeployment); err != nil {
+				t.Fatalf("unexpected error: %v", err)
 			}
 			currentDeployment := &appsv1.Deployment{}
-			err = r.Get(ctx, types.NamespacedName{Namespace: tc.expectedDeployment.Namespace, Name: tc.expectedDeployment.Name}, currentDeployment)
+			err := r.Get(ctx, types.NamespacedName{Namespace: tc.expectedDeployment.Namespace, Name: tc.expectedDeployment.Name}, currentDeployment)
 			if err != nil {
 				t.Fatalf("failed to get existing deployment: %v", err)
 			}
@@ -597,12 +843,14 @@ func TestEnsureDeployment(t *testing.T) {
 		serviceAccount     *corev1.ServiceAccount
 		controller         *albo.AWSLoadBalancerController
 		expectedDeployment *appsv1.Deployment
+		trustedCABundle    *corev1.ConfigMap
 		clusterName        string
 		vpcID              string
 	}{
 		{
-			name:           "new controller",
-			serviceAccount: &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "test-sa"}},
+			name:            "new controller",
+			serviceAccount:  &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "test-sa"}},
+			trustedCABundle: testTrustedCABundle,
 			controller: &albo.AWSLoadBalancerController{
 				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
 				Spec:       albo.AWSLoadBalancerControllerSpec{},
@@ -621,8 +869,9 @@ func TestEnsureDeployment(t *testing.T) {
 					corev1.VolumeMount{Name: "aws-credentials", MountPath: "/aws"},
 					corev1.VolumeMount{Name: "tls", MountPath: "/tls"},
 					corev1.VolumeMount{Name: "bound-sa-token", MountPath: "/var/run/secrets/openshift/serviceaccount", ReadOnly: true},
+					corev1.VolumeMount{Name: "trusted-ca-bundle", MountPath: "/etc/pki/ca-trust/extracted/pem", ReadOnly: true},
 				).build(),
-			).withControllerReference("cluster").withVolumes(
+			).withControllerReference("cluster").withTopologyDefaults().withMetricsProxy().withVolumes(
 				corev1.Volume{Name: "aws-credentials", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "test-credentials"}}},
 				corev1.Volume{Name: "tls", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "test-serving"}}},
 				corev1.Volume{Name: "bound-sa-token", VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{
@@ -635,11 +884,16 @@ func TestEnsureDeployment(t *testing.T) {
 						},
 					}},
 				}}},
+				corev1.Volume{Name: "trusted-ca-bundle", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "test-trusted-ca"},
+					Items:                []corev1.KeyToPath{{Key: "ca-bundle.crt", Path: "tls-ca-bundle.pem"}},
+				}}},
 			).build(),
 		},
 		{
-			name:           "existing controller",
-			serviceAccount: &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "test-sa"}},
+			name:            "existing controller",
+			serviceAccount:  &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "test-sa"}},
+			trustedCABundle: testTrustedCABundle,
 			controller: &albo.AWSLoadBalancerController{
 				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
 				Spec:       albo.AWSLoadBalancerControllerSpec{},
@@ -663,6 +917,7 @@ func TestEnsureDeployment(t *testing.T) {
 					corev1.VolumeMount{Name: "aws-credentials", MountPath: "/aws"},
 					corev1.VolumeMount{Name: "tls", MountPath: "/tls"},
 					corev1.VolumeMount{Name: "bound-sa-token", MountPath: "/var/run/secrets/openshift/serviceaccount", ReadOnly: true},
+					corev1.VolumeMount{Name: "trusted-ca-bundle", MountPath: "/etc/pki/ca-trust/extracted/pem", ReadOnly: true},
 				).withSecurityContext(corev1.SecurityContext{
 					Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
 					Privileged:               pointer.BoolPtr(false),
@@ -670,7 +925,118 @@ func TestEnsureDeployment(t *testing.T) {
 					AllowPrivilegeEscalation: pointer.BoolPtr(false),
 					SeccompProfile:           &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
 				}).build(),
-			).withResourceVersion("2").withVolumes(
+			).withResourceVersion("2").withControllerReference("cluster").withTopologyDefaults().withMetricsProxy().withVolumes(
+				corev1.Volume{Name: "aws-credentials", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "test-credentials"}}},
+				corev1.Volume{Name: "tls", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "test-serving"}}},
+				corev1.Volume{Name: "bound-sa-token", VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{
+					DefaultMode: pointer.Int32(420),
+					Sources: []corev1.VolumeProjection{{
+						ServiceAccountToken: &corev1.ServiceAccountTokenProjection{
+							Audience:          "openshift",
+							ExpirationSeconds: pointer.Int64(3600),
+							Path:              "token",
+						},
+					}},
+				}}},
+				corev1.Volume{Name: "trusted-ca-bundle", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "test-trusted-ca"},
+					Items:                []corev1.KeyToPath{{Key: "ca-bundle.crt", Path: "tls-ca-bundle.pem"}},
+				}}},
+			).build(),
+		},
+		{
+			name:           "credentials and serving secrets provisioned",
+			serviceAccount: &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "test-sa"}},
+			trustedCABundle: &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-trusted-ca", Namespace: "test-namespace"},
+				Data:       map[string]string{"ca-bundle.crt": "test-ca-bundle"},
+			},
+			controller: &albo.AWSLoadBalancerController{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Spec:       albo.AWSLoadBalancerControllerSpec{},
+			},
+			existingObjects: []runtime.Object{
+				testCredentialsSecret,
+				testServingSecret,
+			},
+			expectedDeployment: testDeployment(
+				"cluster",
+				"test-namespace",
+				"test-sa", "test-serving").withContainers(
+				testContainer("controller", "test-image").withSecurityContext(corev1.SecurityContext{
+					Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+					Privileged:               pointer.BoolPtr(false),
+					RunAsNonRoot:             pointer.BoolPtr(true),
+					AllowPrivilegeEscalation: pointer.BoolPtr(false),
+					SeccompProfile:           &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+				}).withDefaultEnvs().withVolumeMounts(
+					corev1.VolumeMount{Name: "aws-credentials", MountPath: "/aws"},
+					corev1.VolumeMount{Name: "tls", MountPath: "/tls"},
+					corev1.VolumeMount{Name: "bound-sa-token", MountPath: "/var/run/secrets/openshift/serviceaccount", ReadOnly: true},
+					corev1.VolumeMount{Name: "trusted-ca-bundle", MountPath: "/etc/pki/ca-trust/extracted/pem", ReadOnly: true},
+				).build(),
+			).withControllerReference("cluster").withTopologyDefaults().withMetricsProxy().withVolumes(
+				corev1.Volume{Name: "aws-credentials", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "test-credentials"}}},
+				corev1.Volume{Name: "tls", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "test-serving"}}},
+				corev1.Volume{Name: "bound-sa-token", VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{
+					DefaultMode: pointer.Int32(420),
+					Sources: []corev1.VolumeProjection{{
+						ServiceAccountToken: &corev1.ServiceAccountTokenProjection{
+							Audience:          "openshift",
+							ExpirationSeconds: pointer.Int64(3600),
+							Path:              "token",
+						},
+					}},
+				}}},
+				corev1.Volume{Name: "trusted-ca-bundle", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "test-trusted-ca"},
+					Items:                []corev1.KeyToPath{{Key: "ca-bundle.crt", Path: "tls-ca-bundle.pem"}},
+				}}},
+			).withTemplateAnnotations(map[string]string{
+				credentialsSecretHashAnnotation: dataHash(testCredentialsSecret.Data),
+				servingSecretHashAnnotation:     dataHash(testServingSecret.Data),
+				trustedCABundleHashAnnotation:   dataHash(map[string][]byte{"ca-bundle.crt": []byte("test-ca-bundle")}),
+			}).build(),
+		},
+		{
+			name:            "cluster proxy configured",
+			serviceAccount:  &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "test-sa"}},
+			trustedCABundle: testTrustedCABundle,
+			controller: &albo.AWSLoadBalancerController{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Spec:       albo.AWSLoadBalancerControllerSpec{},
+			},
+			existingObjects: []runtime.Object{
+				&configv1.Proxy{
+					ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+					Status: configv1.ProxyStatus{
+						HTTPProxy:  "http://proxy.example.com:3128",
+						HTTPSProxy: "https://proxy.example.com:3129",
+						NoProxy:    ".cluster.local,.svc",
+					},
+				},
+			},
+			expectedDeployment: testDeployment(
+				"cluster",
+				"test-namespace",
+				"test-sa", "test-serving").withContainers(
+				testContainer("controller", "test-image").withSecurityContext(corev1.SecurityContext{
+					Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+					Privileged:               pointer.BoolPtr(false),
+					RunAsNonRoot:             pointer.BoolPtr(true),
+					AllowPrivilegeEscalation: pointer.BoolPtr(false),
+					SeccompProfile:           &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+				}).withDefaultEnvs().withEnvs(
+					corev1.EnvVar{Name: "HTTP_PROXY", Value: "http://proxy.example.com:3128"},
+					corev1.EnvVar{Name: "HTTPS_PROXY", Value: "https://proxy.example.com:3129"},
+					corev1.EnvVar{Name: "NO_PROXY", Value: ".cluster.local,.svc"},
+				).withVolumeMounts(
+					corev1.VolumeMount{Name: "aws-credentials", MountPath: "/aws"},
+					corev1.VolumeMount{Name: "tls", MountPath: "/tls"},
+					corev1.VolumeMount{Name: "bound-sa-token", MountPath: "/var/run/secrets/openshift/serviceaccount", ReadOnly: true},
+					corev1.VolumeMount{Name: "trusted-ca-bundle", MountPath: "/etc/pki/ca-trust/extracted/pem", ReadOnly: true},
+				).build(),
+			).withControllerReference("cluster").withTopologyDefaults().withMetricsProxy().withVolumes(
 				corev1.Volume{Name: "aws-credentials", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "test-credentials"}}},
 				corev1.Volume{Name: "tls", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "test-serving"}}},
 				corev1.Volume{Name: "bound-sa-token", VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{
@@ -683,23 +1049,29 @@ func TestEnsureDeployment(t *testing.T) {
 						},
 					}},
 				}}},
+				corev1.Volume{Name: "trusted-ca-bundle", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "test-trusted-ca"},
+					Items:                []corev1.KeyToPath{{Key: "ca-bundle.crt", Path: "tls-ca-bundle.pem"}},
+				}}},
 			).build(),
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
-			client := fake.NewClientBuilder().WithScheme(test.Scheme).WithRuntimeObjects(tc.existingObjects...).Build()
+			client := test.NewApplyClient(fake.NewClientBuilder().WithScheme(test.Scheme).WithRuntimeObjects(tc.existingObjects...).Build())
 			r := &AWSLoadBalancerControllerReconciler{
-				Client:      client,
-				Scheme:      test.Scheme,
-				ClusterName: "test-cluster",
-				VPCID:       "test-vpc",
-				AWSRegion:   testAWSRegion,
+				Client:             client,
+				Scheme:             test.Scheme,
+				ClusterName:        "test-cluster",
+				VPCID:              "test-vpc",
+				AWSRegion:          testAWSRegion,
+				KubeRBACProxyImage: test.KubeRBACProxyImage,
 			}
-			_, err := r.ensureDeployment(context.Background(), "test-namespace", "test-image", tc.serviceAccount, "test-credentials", "test-serving", tc.controller)
+			config := desiredOperandConfig(tc.controller, ClusterTopology{})
+			_, err := r.ensureDeployment(context.Background(), "test-namespace", "test-image", "test-vpc", tc.serviceAccount, "test-credentials", "test-serving", tc.trustedCABundle, tc.controller, config)
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
-			tc.expectedDeployment.Spec.Template.Spec.Containers[0].Args = desiredContainerArgs(tc.controller, "test-cluster", "test-vpc")
+			tc.expectedDeployment.Spec.Template.Spec.Containers[0].Args = desiredContainerArgs(tc.controller, config, "test-cluster", "test-vpc", nil, tc.controller.Spec.AdditionalResourceTags)
 			var deployment appsv1.Deployment
 			err = client.Get(context.Background(), types.NamespacedName{Namespace: "test-namespace", Name: fmt.Sprintf("%s-%s", controllerResourcePrefix, tc.controller.Name)}, &deployment)
 			if err != nil {
@@ -712,203 +1084,322 @@ func TestEnsureDeployment(t *testing.T) {
 	}
 }
 
-func TestHasSecurityContextChanged(t *testing.T) {
+func TestDesiredDeploymentTopology(t *testing.T) {
+	haStrategy := appsv1.DeploymentStrategy{
+		Type: appsv1.RollingUpdateDeploymentStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateDeployment{
+			MaxUnavailable: &intstr.IntOrString{IntVal: 0},
+			MaxSurge:       &intstr.IntOrString{IntVal: 1},
+		},
+	}
+	haTopologyKeys := []string{"topology.kubernetes.io/zone", "kubernetes.io/hostname"}
 	for _, tc := range []struct {
-		name      string
-		currentSC *corev1.SecurityContext
-		desiredSC *corev1.SecurityContext
-		changed   bool
+		name                      string
+		config                    *albo.AWSLoadBalancerDeploymentConfig
+		topology                  ClusterTopology
+		expectedReplicas          int32
+		expectedStrategy          appsv1.DeploymentStrategy
+		expectedTopologyKeys      []string
+		expectedAvoidControlPlane bool
+		expectedLeaderElectionOn  bool
 	}{
 		{
-			name:      "current RunAsNonRoot is nil",
-			currentSC: &corev1.SecurityContext{},
-			desiredSC: &corev1.SecurityContext{RunAsNonRoot: pointer.BoolPtr(false)},
-			changed:   true,
-		},
-		{
-			// should be ignored to handle defaulting
-			name:      "desired RunAsNonRoot is nil",
-			currentSC: &corev1.SecurityContext{RunAsNonRoot: pointer.BoolPtr(false)},
-			desiredSC: &corev1.SecurityContext{},
-			changed:   false,
-		},
-		{
-			name:      "RunAsNonRoot changes true->false",
-			currentSC: &corev1.SecurityContext{RunAsNonRoot: pointer.BoolPtr(true)},
-			desiredSC: &corev1.SecurityContext{RunAsNonRoot: pointer.BoolPtr(false)},
-			changed:   true,
-		},
-		{
-			name:      "RunAsNonRoot changes false->true",
-			currentSC: &corev1.SecurityContext{RunAsNonRoot: pointer.BoolPtr(true)},
-			desiredSC: &corev1.SecurityContext{RunAsNonRoot: pointer.BoolPtr(false)},
-			changed:   true,
-		},
-		{
-			name:      "RunAsNonRoot changes is same",
-			currentSC: &corev1.SecurityContext{RunAsNonRoot: pointer.BoolPtr(true)},
-			desiredSC: &corev1.SecurityContext{RunAsNonRoot: pointer.BoolPtr(true)},
-			changed:   false,
-		},
-		{
-			name:      "current Privileged is nil",
-			currentSC: &corev1.SecurityContext{},
-			desiredSC: &corev1.SecurityContext{Privileged: pointer.BoolPtr(false)},
-			changed:   true,
-		},
-		{
-			// should be ignored to handle defaulting
-			name:      "desired Privileged is nil",
-			desiredSC: &corev1.SecurityContext{},
-			currentSC: &corev1.SecurityContext{Privileged: pointer.BoolPtr(false)},
-			changed:   false,
-		},
-		{
-			name:      "Privileged changes true->false",
-			currentSC: &corev1.SecurityContext{Privileged: pointer.BoolPtr(true)},
-			desiredSC: &corev1.SecurityContext{Privileged: pointer.BoolPtr(false)},
-			changed:   true,
-		},
-		{
-			name:      "Privileged changes false->true",
-			currentSC: &corev1.SecurityContext{Privileged: pointer.BoolPtr(true)},
-			desiredSC: &corev1.SecurityContext{Privileged: pointer.BoolPtr(false)},
-			changed:   true,
-		},
-		{
-			name:      "Privileged is same",
-			currentSC: &corev1.SecurityContext{Privileged: pointer.BoolPtr(true)},
-			desiredSC: &corev1.SecurityContext{Privileged: pointer.BoolPtr(true)},
-			changed:   false,
-		},
-		{
-			name:      "current AllowPrivilegeEscalation is nil",
-			currentSC: &corev1.SecurityContext{},
-			desiredSC: &corev1.SecurityContext{AllowPrivilegeEscalation: pointer.BoolPtr(false)},
-			changed:   true,
-		},
-		{
-			// should be ignored to handle defaulting
-			name:      "desired AllowPrivilegeEscalation is nil",
-			desiredSC: &corev1.SecurityContext{},
-			currentSC: &corev1.SecurityContext{AllowPrivilegeEscalation: pointer.BoolPtr(false)},
-			changed:   false,
-		},
-		{
-			name:      "AllowPrivilegeEscalation changes true->false",
-			currentSC: &corev1.SecurityContext{AllowPrivilegeEscalation: pointer.BoolPtr(true)},
-			desiredSC: &corev1.SecurityContext{AllowPrivilegeEscalation: pointer.BoolPtr(false)},
-			changed:   true,
-		},
-		{
-			name:      "AllowPrivilegeEscalation changes false->true",
-			currentSC: &corev1.SecurityContext{AllowPrivilegeEscalation: pointer.BoolPtr(true)},
-			desiredSC: &corev1.SecurityContext{AllowPrivilegeEscalation: pointer.BoolPtr(false)},
-			changed:   true,
-		},
-		{
-			name:      "AllowPrivilegeEscalation is same",
-			currentSC: &corev1.SecurityContext{AllowPrivilegeEscalation: pointer.BoolPtr(true)},
-			desiredSC: &corev1.SecurityContext{AllowPrivilegeEscalation: pointer.BoolPtr(true)},
-			changed:   false,
-		},
-		{
-			name:      "Add Capabilities are the same",
-			currentSC: &corev1.SecurityContext{Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"A", "B", "C"}}},
-			desiredSC: &corev1.SecurityContext{Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"C", "B", "A"}}},
-			changed:   false,
+			name:                      "default replicas",
+			expectedReplicas:          2,
+			expectedStrategy:          haStrategy,
+			expectedTopologyKeys:      haTopologyKeys,
+			expectedAvoidControlPlane: true,
+			expectedLeaderElectionOn:  true,
 		},
 		{
-			name:      "Add Capabilities are the different",
-			currentSC: &corev1.SecurityContext{Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"A", "B", "C"}}},
-			desiredSC: &corev1.SecurityContext{Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"C", "B", "C"}}},
-			changed:   true,
+			name:                      "single replica",
+			config:                    &albo.AWSLoadBalancerDeploymentConfig{Replicas: 1},
+			expectedReplicas:          1,
+			expectedAvoidControlPlane: true,
 		},
 		{
-			name:      "current Capabilities are nil",
-			currentSC: &corev1.SecurityContext{},
-			desiredSC: &corev1.SecurityContext{Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"A", "B", "C"}}},
-			changed:   true,
+			name:                      "multiple replicas",
+			config:                    &albo.AWSLoadBalancerDeploymentConfig{Replicas: 3},
+			expectedReplicas:          3,
+			expectedStrategy:          haStrategy,
+			expectedTopologyKeys:      haTopologyKeys,
+			expectedAvoidControlPlane: true,
+			expectedLeaderElectionOn:  true,
 		},
 		{
-			// ignore the desired because the capabilities might be defaulting or set by something else.
-			name:      "desired Capabilities are nil",
-			desiredSC: &corev1.SecurityContext{},
-			currentSC: &corev1.SecurityContext{Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"A", "B", "C"}}},
-			changed:   false,
+			name:                      "highly available topology",
+			topology:                  ClusterTopology{ControlPlane: configv1.HighlyAvailableTopologyMode, Infrastructure: configv1.HighlyAvailableTopologyMode},
+			expectedReplicas:          2,
+			expectedStrategy:          haStrategy,
+			expectedTopologyKeys:      haTopologyKeys,
+			expectedAvoidControlPlane: true,
+			expectedLeaderElectionOn:  true,
 		},
 		{
-			name:      "current Add Capabilities are nil",
-			currentSC: &corev1.SecurityContext{Capabilities: &corev1.Capabilities{}},
-			desiredSC: &corev1.SecurityContext{Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"A", "B", "C"}}},
-			changed:   true,
+			name:             "single node cluster",
+			topology:         ClusterTopology{ControlPlane: configv1.SingleReplicaTopologyMode, Infrastructure: configv1.SingleReplicaTopologyMode},
+			expectedReplicas: 1,
 		},
 		{
-			name:      "desired Add Capabilities are nil",
-			desiredSC: &corev1.SecurityContext{Capabilities: &corev1.Capabilities{}},
-			currentSC: &corev1.SecurityContext{Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"A", "B", "C"}}},
-			changed:   true,
+			name:                     "single node cluster with multiple replicas",
+			config:                   &albo.AWSLoadBalancerDeploymentConfig{Replicas: 2},
+			topology:                 ClusterTopology{ControlPlane: configv1.SingleReplicaTopologyMode, Infrastructure: configv1.SingleReplicaTopologyMode},
+			expectedReplicas:         2,
+			expectedLeaderElectionOn: true,
 		},
 		{
-			name:      "Drop Capabilities are the same",
-			currentSC: &corev1.SecurityContext{Capabilities: &corev1.Capabilities{Drop: []corev1.Capability{"A", "B", "C"}}},
-			desiredSC: &corev1.SecurityContext{Capabilities: &corev1.Capabilities{Drop: []corev1.Capability{"C", "B", "A"}}},
-			changed:   false,
+			name:                     "external control plane",
+			topology:                 ClusterTopology{ControlPlane: configv1.ExternalTopologyMode, Infrastructure: configv1.HighlyAvailableTopologyMode},
+			expectedReplicas:         2,
+			expectedStrategy:         haStrategy,
+			expectedTopologyKeys:     haTopologyKeys,
+			expectedLeaderElectionOn: true,
 		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			controller := &albo.AWSLoadBalancerController{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Spec:       albo.AWSLoadBalancerControllerSpec{Config: tc.config},
+			}
+			sa := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "test-sa"}}
+			config := desiredOperandConfig(controller, tc.topology)
+			d := desiredDeployment("test", "test-namespace", "test-image", test.KubeRBACProxyImage, "test-vpc", "test-cluster", testAWSRegion, nil, nil, "test-credentials", "test-serving", "test-trusted-ca", controller, config, sa, nil, nil)
+
+			if d.Spec.Replicas == nil || *d.Spec.Replicas != tc.expectedReplicas {
+				t.Errorf("expected %d replicas, got %v", tc.expectedReplicas, d.Spec.Replicas)
+			}
+			if diff := cmp.Diff(tc.expectedStrategy, d.Spec.Strategy); diff != "" {
+				t.Errorf("unexpected strategy\n%s", diff)
+			}
+			var topologyKeys []string
+			for _, constraint := range d.Spec.Template.Spec.TopologySpreadConstraints {
+				topologyKeys = append(topologyKeys, constraint.TopologyKey)
+				if diff := cmp.Diff(d.Spec.Selector, constraint.LabelSelector); diff != "" {
+					t.Errorf("unexpected label selector of the %s topology spread constraint\n%s", constraint.TopologyKey, diff)
+				}
+			}
+			if diff := cmp.Diff(tc.expectedTopologyKeys, topologyKeys); diff != "" {
+				t.Errorf("unexpected topology spread constraints\n%s", diff)
+			}
+			if avoidControlPlane := d.Spec.Template.Spec.Affinity != nil; avoidControlPlane != tc.expectedAvoidControlPlane {
+				t.Errorf("expected control plane nodes to be avoided %t, got %t", tc.expectedAvoidControlPlane, avoidControlPlane)
+			}
+			var leaderElectionOn bool
+			for _, arg := range d.Spec.Template.Spec.Containers[0].Args {
+				if arg == "--enable-leader-election" {
+					leaderElectionOn = true
+				}
+			}
+			if leaderElectionOn != tc.expectedLeaderElectionOn {
+				t.Errorf("expected leader election %t, got %t", tc.expectedLeaderElectionOn, leaderElectionOn)
+			}
+			if status := config.statusDeployment(); status.Replicas != tc.expectedReplicas || status.LeaderElection != tc.expectedLeaderElectionOn || status.InfrastructureTopology != string(tc.topology.Infrastructure) {
+				t.Errorf("unexpected effective configuration in status: %+v", status)
+			}
+		})
+	}
+}
+
+var (
+	testTrustedCABundle = &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-trusted-ca", Namespace: "test-namespace"},
+	}
+	testCredentialsSecret = &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-credentials", Namespace: "test-namespace"},
+		Data:       map[string][]byte{"credentials": []byte("aws_access_key_id = key-1")},
+	}
+	testServingSecret = &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-serving", Namespace: "test-namespace"},
+		Data:       map[string][]byte{"tls.crt": []byte("cert-1"), "tls.key": []byte("key-1")},
+	}
+)
+
+func TestEnsureDeploymentSecretRotation(t *testing.T) {
+	for _, tc := range []struct {
+		name                string
+		rotatedSecret       *corev1.Secret
+		changedAnnotation   string
+		unchangedAnnotation string
+	}{
 		{
-			name:      "Drop Capabilities are the different",
-			currentSC: &corev1.SecurityContext{Capabilities: &corev1.Capabilities{Drop: []corev1.Capability{"A", "B", "C"}}},
-			desiredSC: &corev1.SecurityContext{Capabilities: &corev1.Capabilities{Drop: []corev1.Capability{"C", "B", "C"}}},
-			changed:   true,
+			name: "credentials rotated",
+			rotatedSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-credentials", Namespace: "test-namespace"},
+				Data:       map[string][]byte{"credentials": []byte("aws_access_key_id = key-2")},
+			},
+			changedAnnotation:   credentialsSecretHashAnnotation,
+			unchangedAnnotation: servingSecretHashAnnotation,
 		},
 		{
-			name:      "current Drop Capabilities are nil",
-			currentSC: &corev1.SecurityContext{Capabilities: &corev1.Capabilities{}},
-			desiredSC: &corev1.SecurityContext{Capabilities: &corev1.Capabilities{Drop: []corev1.Capability{"A", "B", "C"}}},
-			changed:   true,
+			name: "serving certificate rotated",
+			rotatedSecret: &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-serving", Namespace: "test-namespace"},
+				Data:       map[string][]byte{"tls.crt": []byte("cert-2"), "tls.key": []byte("key-2")},
+			},
+			changedAnnotation:   servingSecretHashAnnotation,
+			unchangedAnnotation: credentialsSecretHashAnnotation,
 		},
-		{
-			name:      "desired Drop Capabilities are nil",
-			desiredSC: &corev1.SecurityContext{Capabilities: &corev1.Capabilities{}},
-			currentSC: &corev1.SecurityContext{Capabilities: &corev1.Capabilities{Drop: []corev1.Capability{"A", "B", "C"}}},
-			changed:   true,
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			client := test.NewApplyClient(fake.NewClientBuilder().WithScheme(test.Scheme).WithRuntimeObjects(testCredentialsSecret.DeepCopy(), testServingSecret.DeepCopy()).Build())
+			r := &AWSLoadBalancerControllerReconciler{
+				Client:      client,
+				Scheme:      test.Scheme,
+				ClusterName: "test-cluster",
+				VPCID:       "test-vpc",
+				AWSRegion:   testAWSRegion,
+			}
+			controller := &albo.AWSLoadBalancerController{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}}
+			sa := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "test-sa"}}
+
+			initial, err := r.ensureDeployment(ctx, "test-namespace", "test-image", "test-vpc", sa, "test-credentials", "test-serving", testTrustedCABundle, controller, desiredOperandConfig(controller, ClusterTopology{}))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var secret corev1.Secret
+			if err := client.Get(ctx, types.NamespacedName{Namespace: tc.rotatedSecret.Namespace, Name: tc.rotatedSecret.Name}, &secret); err != nil {
+				t.Fatalf("failed to get secret: %v", err)
+			}
+			secret.Data = tc.rotatedSecret.Data
+			if err := client.Update(ctx, &secret); err != nil {
+				t.Fatalf("failed to rotate secret: %v", err)
+			}
+
+			rotated, err := r.ensureDeployment(ctx, "test-namespace", "test-image", "test-vpc", sa, "test-credentials", "test-serving", testTrustedCABundle, controller, desiredOperandConfig(controller, ClusterTopology{}))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			initialAnnotations := initial.Spec.Template.Annotations
+			rotatedAnnotations := rotated.Spec.Template.Annotations
+			if rotatedAnnotations[tc.changedAnnotation] != dataHash(tc.rotatedSecret.Data) {
+				t.Errorf("expected annotation %q to be %q, instead was %q", tc.changedAnnotation, dataHash(tc.rotatedSecret.Data), rotatedAnnotations[tc.changedAnnotation])
+			}
+			if initialAnnotations[tc.changedAnnotation] == rotatedAnnotations[tc.changedAnnotation] {
+				t.Errorf("expected annotation %q to change after the rotation", tc.changedAnnotation)
+			}
+			if initialAnnotations[tc.unchangedAnnotation] != rotatedAnnotations[tc.unchangedAnnotation] {
+				t.Errorf("expected annotation %q to remain unchanged, changed from %q to %q", tc.unchangedAnnotation, initialAnnotations[tc.unchangedAnnotation], rotatedAnnotations[tc.unchangedAnnotation])
+			}
+		})
+	}
+}
+
+func TestEnsureDeploymentInfrastructureTagsChange(t *testing.T) {
+	ctx := context.Background()
+	infra := &configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Status: configv1.InfrastructureStatus{
+			PlatformStatus: &configv1.PlatformStatus{
+				Type: configv1.AWSPlatformType,
+				AWS: &configv1.AWSPlatformStatus{
+					Region:       testAWSRegion,
+					ResourceTags: []configv1.AWSResourceTag{{Key: "cost-center", Value: "1234"}},
+				},
+			},
 		},
-		{
-			name:      "current SeccompProfile is nil",
-			currentSC: &corev1.SecurityContext{},
-			desiredSC: &corev1.SecurityContext{SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeLocalhost}},
-			changed:   true,
+	}
+	client := test.NewApplyClient(fake.NewClientBuilder().WithScheme(test.Scheme).WithRuntimeObjects(infra).Build())
+	r := &AWSLoadBalancerControllerReconciler{
+		Client:      client,
+		Scheme:      test.Scheme,
+		ClusterName: "test-cluster",
+		VPCID:       "test-vpc",
+		AWSRegion:   testAWSRegion,
+	}
+	controller := &albo.AWSLoadBalancerController{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec: albo.AWSLoadBalancerControllerSpec{
+			AdditionalResourceTags: map[string]string{"owner": "team-b"},
 		},
+	}
+	sa := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "test-sa"}}
+
+	initial, err := r.ensureDeployment(ctx, "test-namespace", "test-image", "test-vpc", sa, "test-credentials", "test-serving", testTrustedCABundle, controller, desiredOperandConfig(controller, ClusterTopology{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedTags := "--default-tags=cost-center=1234,owner=team-b"
+	if !sets.NewString(initial.Spec.Template.Spec.Containers[0].Args...).Has(expectedTags) {
+		t.Errorf("expected argument %q in %v", expectedTags, initial.Spec.Template.Spec.Containers[0].Args)
+	}
+
+	var current configv1.Infrastructure
+	if err := client.Get(ctx, types.NamespacedName{Name: "cluster"}, &current); err != nil {
+		t.Fatalf("failed to get infrastructure: %v", err)
+	}
+	current.Status.PlatformStatus.AWS.ResourceTags = []configv1.AWSResourceTag{
+		{Key: "cost-center", Value: "5678"},
+		{Key: "owner", Value: "team-a"},
+	}
+	if err := client.Update(ctx, &current); err != nil {
+		t.Fatalf("failed to update infrastructure: %v", err)
+	}
+
+	updated, err := r.ensureDeployment(ctx, "test-namespace", "test-image", "test-vpc", sa, "test-credentials", "test-serving", testTrustedCABundle, controller, desiredOperandConfig(controller, ClusterTopology{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedTags = "--default-tags=cost-center=5678,owner=team-b"
+	if !sets.NewString(updated.Spec.Template.Spec.Containers[0].Args...).Has(expectedTags) {
+		t.Errorf("expected argument %q in %v", expectedTags, updated.Spec.Template.Spec.Containers[0].Args)
+	}
+
+	// the merged tags over the AWS limit are not applied, the deployment keeps the last valid tags
+	if err := client.Get(ctx, types.NamespacedName{Name: "cluster"}, &current); err != nil {
+		t.Fatalf("failed to get infrastructure: %v", err)
+	}
+	for i := 0; i < albo.MaxResourceTags; i++ {
+		current.Status.PlatformStatus.AWS.ResourceTags = append(current.Status.PlatformStatus.AWS.ResourceTags, configv1.AWSResourceTag{Key: fmt.Sprintf("tag-%d", i), Value: "value"})
+	}
+	if err := client.Update(ctx, &current); err != nil {
+		t.Fatalf("failed to update infrastructure: %v", err)
+	}
+	overLimit, err := r.ensureDeployment(ctx, "test-namespace", "test-image", "test-vpc", sa, "test-credentials", "test-serving", testTrustedCABundle, controller, desiredOperandConfig(controller, ClusterTopology{}))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !sets.NewString(overLimit.Spec.Template.Spec.Containers[0].Args...).Has(expectedTags) {
+		t.Errorf("expected argument %q in %v", expectedTags, overLimit.Spec.Template.Spec.Containers[0].Args)
+	}
+}
+
+func TestDataHash(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		data1     map[string][]byte
+		data2     map[string][]byte
+		equalHash bool
+	}{
 		{
-			// ignore the desired seccompprofile if it is being defaulted elsewhere
-			name:      "desired SeccompProfile is nil",
-			desiredSC: &corev1.SecurityContext{},
-			currentSC: &corev1.SecurityContext{SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeLocalhost}},
-			changed:   false,
+			name:      "same data",
+			data1:     map[string][]byte{"a": []byte("1"), "b": []byte("2")},
+			data2:     map[string][]byte{"b": []byte("2"), "a": []byte("1")},
+			equalHash: true,
 		},
 		{
-			name:      "SeccompProfile is different",
-			currentSC: &corev1.SecurityContext{SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}},
-			desiredSC: &corev1.SecurityContext{SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeLocalhost}},
-			changed:   true,
+			name:  "value changed",
+			data1: map[string][]byte{"a": []byte("1"), "b": []byte("2")},
+			data2: map[string][]byte{"a": []byte("1"), "b": []byte("3")},
 		},
 		{
-			name:      "SeccompProfile is same",
-			currentSC: &corev1.SecurityContext{SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}},
-			desiredSC: &corev1.SecurityContext{SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}},
-			changed:   false,
+			name:  "key added",
+			data1: map[string][]byte{"a": []byte("1")},
+			data2: map[string][]byte{"a": []byte("1"), "b": []byte("")},
 		},
 		{
-			name:      "SeccompProfile is empty",
-			currentSC: &corev1.SecurityContext{SeccompProfile: &corev1.SeccompProfile{}},
-			desiredSC: &corev1.SecurityContext{SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}},
-			changed:   true,
+			name:  "key and value boundary moved",
+			data1: map[string][]byte{"ab": []byte("c")},
+			data2: map[string][]byte{"a": []byte("bc")},
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
-			changed := hasSecurityContextChanged(tc.currentSC, tc.desiredSC)
-			if changed != tc.changed {
-				t.Errorf("expected %v, instead was %v", tc.changed, changed)
+			hash1 := dataHash(tc.data1)
+			hash2 := dataHash(tc.data2)
+			if (hash1 == hash2) != tc.equalHash {
+				t.Errorf("expected equal hashes to be %t, got %q and %q", tc.equalHash, hash1, hash2)
 			}
 		})
 	}
@@ -7,8 +7,10 @@ type IAMPolicy struct {
 	Statement []cco.StatementEntry
 }
 
+// GetIAMPolicy returns the IAM policy of the CredentialsRequest of the controller.
 func GetIAMPolicy() IAMPolicy {
 	return IAMPolicy{
+		Version: "2012-10-17",
 		Statement: []cco.StatementEntry{
 			{
 				Effect:          "Allow",
@@ -0,0 +1,94 @@
+package awsloadbalancercontroller
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	configv1 "github.com/openshift/api/config/v1"
+
+	albo "github.com/openshift/aws-load-balancer-operator/api/v1alpha1"
+)
+
+const (
+	// clusterInfrastructureName is the name of the cluster-wide Infrastructure
+	clusterInfrastructureName = "cluster"
+	// ResourceTagsValidCondition reports whether the merged resource tags can be applied to the AWS resources
+	ResourceTagsValidCondition = "ResourceTagsValid"
+)
+
+// errTooManyResourceTags is returned when the merged resource tags exceed the AWS limit
+var errTooManyResourceTags = goerrors.New("too many resource tags")
+
+// resourceTags returns the tags which are applied to all the AWS resources managed by the controller. The
+// user-defined tags from the Infrastructure status are merged with the additional resource tags from the spec.
+// When a key is present in both the value from the spec takes precedence. errTooManyResourceTags is returned when
+// the merged tags exceed the AWS limit, which the webhook only checks for the spec tags.
+func (r *AWSLoadBalancerControllerReconciler) resourceTags(ctx context.Context, controller *albo.AWSLoadBalancerController) (map[string]string, error) {
+	infraTags, err := r.currentInfrastructureResourceTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	tags := mergeResourceTags(infraTags, controller.Spec.AdditionalResourceTags)
+	if len(tags) > albo.MaxResourceTags {
+		return nil, fmt.Errorf("%w: %d tags of the Infrastructure and of the spec, at most %d are allowed", errTooManyResourceTags, len(tags), albo.MaxResourceTags)
+	}
+	return tags, nil
+}
+
+// resourceTagsValidCondition returns the ResourceTagsValid condition with the result of the merge of the resource tags.
+func resourceTagsValidCondition(err error, generation int64) metav1.Condition {
+	if err != nil {
+		return metav1.Condition{
+			Type:               ResourceTagsValidCondition,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: generation,
+			Reason:             "TooManyResourceTags",
+			Message:            err.Error(),
+		}
+	}
+	return metav1.Condition{
+		Type:               ResourceTagsValidCondition,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             "ResourceTagsValid",
+		Message:            "Resource tags of the Infrastructure and of the spec are within the AWS limit",
+	}
+}
+
+// currentInfrastructureResourceTags returns the user-defined AWS tags from the status of the cluster-wide Infrastructure.
+// If the Infrastructure doesn't exist or has no AWS platform status then no tags are returned.
+func (r *AWSLoadBalancerControllerReconciler) currentInfrastructureResourceTags(ctx context.Context) ([]configv1.AWSResourceTag, error) {
+	var infra configv1.Infrastructure
+	err := r.Get(ctx, types.NamespacedName{Name: clusterInfrastructureName}, &infra)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get Infrastructure %q: %w", clusterInfrastructureName, err)
+	}
+	if infra.Status.PlatformStatus == nil || infra.Status.PlatformStatus.AWS == nil {
+		return nil, nil
+	}
+	return infra.Status.PlatformStatus.AWS.ResourceTags, nil
+}
+
+// mergeResourceTags merges the Infrastructure tags with the spec tags. The spec tags override
+// the Infrastructure tags with the same key. nil is returned when there are no tags.
+func mergeResourceTags(infraTags []configv1.AWSResourceTag, specTags map[string]string) map[string]string {
+	if len(infraTags) == 0 && len(specTags) == 0 {
+		return nil
+	}
+	tags := make(map[string]string, len(infraTags)+len(specTags))
+	for _, t := range infraTags {
+		tags[t.Key] = t.Value
+	}
+	for k, v := range specTags {
+		tags[k] = v
+	}
+	return tags
+}
@@ -0,0 +1,97 @@
+package awsloadbalancercontroller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	albo "github.com/openshift/aws-load-balancer-operator/api/v1alpha1"
+	"github.com/openshift/aws-load-balancer-operator/pkg/controllers/utils/test"
+)
+
+func TestResourceTagsLimit(t *testing.T) {
+	testTags := func(prefix string, n int) map[string]string {
+		tags := make(map[string]string, n)
+		for i := 0; i < n; i++ {
+			tags[fmt.Sprintf("%s-%d", prefix, i)] = "value"
+		}
+		return tags
+	}
+	for _, tc := range []struct {
+		name          string
+		infraTags     map[string]string
+		specTags      map[string]string
+		expectedTags  int
+		expectedError bool
+	}{
+		{
+			name:         "spec tags at the limit",
+			specTags:     testTags("spec", albo.MaxResourceTags),
+			expectedTags: albo.MaxResourceTags,
+		},
+		{
+			name:         "merged tags at the limit",
+			infraTags:    testTags("infra", 40),
+			specTags:     testTags("spec", albo.MaxResourceTags-40),
+			expectedTags: albo.MaxResourceTags,
+		},
+		{
+			// the spec overrides the value of the Infrastructure tags with the same key
+			name:         "overridden tags are counted once",
+			infraTags:    testTags("shared", 40),
+			specTags:     testTags("shared", 40),
+			expectedTags: 40,
+		},
+		{
+			name:          "merged tags over the limit",
+			infraTags:     testTags("infra", 40),
+			specTags:      testTags("spec", 10),
+			expectedError: true,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			infra := &configv1.Infrastructure{
+				ObjectMeta: metav1.ObjectMeta{Name: clusterInfrastructureName},
+				Status: configv1.InfrastructureStatus{
+					PlatformStatus: &configv1.PlatformStatus{
+						Type: configv1.AWSPlatformType,
+						AWS:  &configv1.AWSPlatformStatus{Region: testAWSRegion},
+					},
+				},
+			}
+			for k, v := range tc.infraTags {
+				infra.Status.PlatformStatus.AWS.ResourceTags = append(infra.Status.PlatformStatus.AWS.ResourceTags, configv1.AWSResourceTag{Key: k, Value: v})
+			}
+			r := &AWSLoadBalancerControllerReconciler{
+				Client: fake.NewClientBuilder().WithScheme(test.Scheme).WithRuntimeObjects(infra).Build(),
+			}
+			controller := &albo.AWSLoadBalancerController{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Spec:       albo.AWSLoadBalancerControllerSpec{AdditionalResourceTags: tc.specTags},
+			}
+
+			tags, err := r.resourceTags(context.Background(), controller)
+			if tc.expectedError {
+				if !errors.Is(err, errTooManyResourceTags) {
+					t.Fatalf("expected too many resource tags error, got %v", err)
+				}
+				if condition := resourceTagsValidCondition(err, 1); condition.Status != metav1.ConditionFalse {
+					t.Errorf("expected %s condition to be false, got %s", ResourceTagsValidCondition, condition.Status)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(tags) != tc.expectedTags {
+				t.Errorf("expected %d tags, got %d", tc.expectedTags, len(tags))
+			}
+		})
+	}
+}
@@ -20,19 +20,13 @@ const (
 // ensureIngressClass create the default IngressClass which is specified in the controller. This is required because the OpenShift router
 // reconciles any Ingress resource whose class is not defined or if the IngressClass does not have the spec.controllerName set.
 // Steps to ensure the IngressClass
-// 1. Check the status to see if the IngressClass already exists
-// 2. If the name matches then do nothing.
-// 3. If the name does not match then delete the existing IngressClass. Ignore if it doesn't exist.
-// 4. Create the new IngresClass with the correct controller name. If there is an AlreadyExists error, ignore it.
+// 1. Check the status to see if the IngressClass was renamed.
+// 2. If the name does not match then delete the existing IngressClass. Ignore if it doesn't exist.
+// 3. Apply the IngressClass with the correct controller name.
 func (r *AWSLoadBalancerControllerReconciler) ensureIngressClass(ctx context.Context, controller *albo.AWSLoadBalancerController) error {
 
-	// if the current ingress class is the same then do nothing.
-	if controller.Status.IngressClass == controller.Spec.IngressClass {
-		return nil
-	}
-
 	// if the current ingress class name does not match then delete it.
-	if controller.Status.IngressClass != "" {
+	if controller.Status.IngressClass != "" && controller.Status.IngressClass != controller.Spec.IngressClass {
 		err := r.Delete(ctx, &networkingv1.IngressClass{ObjectMeta: metav1.ObjectMeta{Name: controller.Status.IngressClass}})
 		if err != nil && !errors.IsNotFound(err) {
 			return fmt.Errorf("failed to delete existing IngressClass %q: %w", controller.Status.IngressClass, err)
@@ -45,9 +39,8 @@ func (r *AWSLoadBalancerControllerReconciler) ensureIngressClass(ctx context.Con
 		return fmt.Errorf("failed to set owner reference on new IngressClass %q: %w", ingressClass.Name, err)
 	}
 
-	err = r.Create(ctx, ingressClass)
-	if err != nil && !errors.IsAlreadyExists(err) {
-		return fmt.Errorf("failed to create default IngressClass %s: %w", controller.Spec.IngressClass, err)
+	if err := r.apply(ctx, ingressClass); err != nil {
+		return fmt.Errorf("failed to apply default IngressClass %s: %w", controller.Spec.IngressClass, err)
 	}
 	return nil
 }
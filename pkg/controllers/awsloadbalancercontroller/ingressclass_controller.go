@@ -0,0 +1,80 @@
+package awsloadbalancercontroller
+
+import (
+	"context"
+	"fmt"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	albo "github.com/openshift/aws-load-balancer-operator/api/v1alpha1"
+)
+
+const (
+	// ingressClassControllerName is the name of the controller which ensures the default IngressClass
+	ingressClassControllerName = "ingressclass"
+
+	// IngressClassAvailableCondition reports whether the IngressClass of the spec was ensured
+	IngressClassAvailableCondition = "IngressClassAvailable"
+)
+
+// ingressClassReconciler ensures the IngressClass of the AWSLoadBalancerController. It reports the
+// IngressClassAvailable condition and the IngressClass in the status. The failures are retried with backoff.
+type ingressClassReconciler struct {
+	*AWSLoadBalancerControllerReconciler
+}
+
+func (r *ingressClassReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	lbController, err := r.reconciledController(ctx, r.Client, req.Name)
+	if err != nil || lbController == nil {
+		return ctrl.Result{}, err
+	}
+
+	// the IngressClass is reported in the status once it's ensured, along with the condition
+	err = r.ensureIngressClass(ctx, lbController)
+	status := &controllerStatus{}
+	status.addConditions(ingressClassAvailableCondition(lbController.Spec.IngressClass, err, lbController.Generation))
+	if err == nil {
+		status.setIngressClass(lbController.Spec.IngressClass)
+	}
+	if statusErr := r.writeStatus(ctx, ingressClassControllerName, lbController, status); statusErr != nil {
+		return ctrl.Result{}, statusErr
+	}
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to ensure default IngressClass for AWSLoadBalancerController %q: %w", req.Name, err)
+	}
+	return ctrl.Result{}, nil
+}
+
+// ingressClassAvailableCondition returns the IngressClassAvailable condition with the result of ensuring the IngressClass.
+func ingressClassAvailableCondition(name string, err error, generation int64) metav1.Condition {
+	if err != nil {
+		return metav1.Condition{
+			Type:               IngressClassAvailableCondition,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: generation,
+			Reason:             "IngressClassFailed",
+			Message:            err.Error(),
+		}
+	}
+	return metav1.Condition{
+		Type:               IngressClassAvailableCondition,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             "IngressClassAvailable",
+		Message:            fmt.Sprintf("IngressClass %q is handled by the controller", name),
+	}
+}
+
+// SetupWithManager sets up the ingress class controller with the Manager.
+func (r *ingressClassReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(ingressClassControllerName).
+		For(&albo.AWSLoadBalancerController{}, builder.WithPredicates(reconcileClusterNamedResource(), predicate.GenerationChangedPredicate{})).
+		Owns(&networkingv1.IngressClass{}).
+		Complete(r)
+}
@@ -64,7 +64,7 @@ func TestEnsureIngressClass(t *testing.T) {
 				controller.Status.IngressClass = tc.existingIngressClass.Name
 			}
 			existingObjects = append(existingObjects, controller)
-			testClient := fake.NewClientBuilder().WithScheme(test.Scheme).WithObjects(existingObjects...).Build()
+			testClient := test.NewApplyClient(fake.NewClientBuilder().WithScheme(test.Scheme).WithObjects(existingObjects...).Build())
 			r := &AWSLoadBalancerControllerReconciler{
 				Scheme: test.Scheme,
 				Client: testClient,
@@ -0,0 +1,33 @@
+package awsloadbalancercontroller
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// conditionStatus exposes the status of the conditions reported by the sub-controllers. The reconciles of the
+	// sub-controllers are counted and timed by controller-runtime with the name of the sub-controller.
+	conditionStatus = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "aws_load_balancer_operator",
+		Name:      "condition",
+		Help:      "Status of the conditions of the AWSLoadBalancerController by the controller which reports them, 1 if the condition is true, 0 otherwise.",
+	}, []string{"controller", "condition"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(conditionStatus)
+}
+
+// recordConditions sets the status of the conditions reported by the given sub-controller in the metrics.
+func recordConditions(controller string, conditions ...metav1.Condition) {
+	for _, condition := range conditions {
+		var value float64
+		if condition.Status == metav1.ConditionTrue {
+			value = 1
+		}
+		conditionStatus.WithLabelValues(controller, condition.Type).Set(value)
+	}
+}
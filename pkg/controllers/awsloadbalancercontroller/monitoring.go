@@ -0,0 +1,205 @@
+package awsloadbalancercontroller
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	albo "github.com/openshift/aws-load-balancer-operator/api/v1alpha1"
+)
+
+const (
+	// prometheusBearerTokenFile is the token with which Prometheus authenticates to the kube-rbac-proxy sidecar
+	prometheusBearerTokenFile = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	// prometheusServiceCAFile is the service CA bundle mounted in the Prometheus of the cluster monitoring, it
+	// verifies the serving certificate of the service signed by the service CA
+	prometheusServiceCAFile = "/etc/prometheus/configmaps/serving-certs-ca-bundle/service-ca.crt"
+	// alertSeverityLabel is the label with the severity of the alerts
+	alertSeverityLabel = "severity"
+)
+
+// monitoringAPIAvailable returns whether the ServiceMonitor and PrometheusRule CRDs of the monitoring.coreos.com
+// group are installed in the cluster.
+func monitoringAPIAvailable(mapper meta.RESTMapper) (bool, error) {
+	for _, kind := range []string{monitoringv1.ServiceMonitorsKind, monitoringv1.PrometheusRuleKind} {
+		gk := schema.GroupKind{Group: monitoringv1.SchemeGroupVersion.Group, Kind: kind}
+		if _, err := mapper.RESTMapping(gk, monitoringv1.SchemeGroupVersion.Version); err != nil {
+			if meta.IsNoMatchError(err) {
+				return false, nil
+			}
+			return false, fmt.Errorf("failed to get REST mapping of %s: %w", gk, err)
+		}
+	}
+	return true, nil
+}
+
+// ensureServiceMonitor ensures the ServiceMonitor which scrapes the metrics of the operand through the kube-rbac-proxy
+// sidecar. The ServiceMonitor is deleted when it's disabled in the spec.
+func (r *AWSLoadBalancerControllerReconciler) ensureServiceMonitor(ctx context.Context, controller *albo.AWSLoadBalancerController, service *corev1.Service, deployment *appsv1.Deployment) error {
+	if !serviceMonitorEnabled(controller) {
+		return r.deleteMonitoringResource(ctx, &monitoringv1.ServiceMonitor{}, types.NamespacedName{Namespace: service.Namespace, Name: service.Name})
+	}
+
+	desired := desiredServiceMonitor(service, deployment)
+	if err := controllerutil.SetControllerReference(controller, desired, r.Scheme); err != nil {
+		return fmt.Errorf("failed to set owner reference on service monitor %s: %w", desired.Name, err)
+	}
+	if err := r.apply(ctx, desired); err != nil {
+		return fmt.Errorf("failed to apply service monitor %s: %w", desired.Name, err)
+	}
+	return nil
+}
+
+// ensurePrometheusRule ensures the PrometheusRule with the alerts on the operand. The PrometheusRule is deleted when
+// it's disabled in the spec.
+func (r *AWSLoadBalancerControllerReconciler) ensurePrometheusRule(ctx context.Context, controller *albo.AWSLoadBalancerController, service *corev1.Service, deployment *appsv1.Deployment) error {
+	if !alertingRulesEnabled(controller) {
+		return r.deleteMonitoringResource(ctx, &monitoringv1.PrometheusRule{}, types.NamespacedName{Namespace: deployment.Namespace, Name: deployment.Name})
+	}
+
+	desired := desiredPrometheusRule(service, deployment)
+	if err := controllerutil.SetControllerReference(controller, desired, r.Scheme); err != nil {
+		return fmt.Errorf("failed to set owner reference on prometheus rule %s: %w", desired.Name, err)
+	}
+	if err := r.apply(ctx, desired); err != nil {
+		return fmt.Errorf("failed to apply prometheus rule %s: %w", desired.Name, err)
+	}
+	return nil
+}
+
+// deleteMonitoringResource deletes the given monitoring resource if it exists.
+func (r *AWSLoadBalancerControllerReconciler) deleteMonitoringResource(ctx context.Context, obj client.Object, name types.NamespacedName) error {
+	if err := r.Get(ctx, name, obj); err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("failed to get %T %s: %w", obj, name, err)
+	}
+	if err := r.Delete(ctx, obj); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete %T %s: %w", obj, name, err)
+	}
+	return nil
+}
+
+// serviceMonitorEnabled returns true unless the ServiceMonitor is disabled in the spec.
+func serviceMonitorEnabled(controller *albo.AWSLoadBalancerController) bool {
+	return controller.Spec.Monitoring == nil || controller.Spec.Monitoring.ServiceMonitor != albo.MonitoringDisabled
+}
+
+// alertingRulesEnabled returns true unless the alerting rules are disabled in the spec.
+func alertingRulesEnabled(controller *albo.AWSLoadBalancerController) bool {
+	return controller.Spec.Monitoring == nil || controller.Spec.Monitoring.AlertingRules != albo.MonitoringDisabled
+}
+
+// desiredServiceMonitor returns the ServiceMonitor which scrapes the metrics port of the service over TLS. The serving
+// certificate is signed by the service CA, Prometheus authenticates with its service account token which is
+// authorized by the kube-rbac-proxy sidecar.
+func desiredServiceMonitor(service *corev1.Service, deployment *appsv1.Deployment) *monitoringv1.ServiceMonitor {
+	return &monitoringv1.ServiceMonitor{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      service.Name,
+			Namespace: service.Namespace,
+		},
+		Spec: monitoringv1.ServiceMonitorSpec{
+			Endpoints: []monitoringv1.Endpoint{
+				{
+					Port:            "metrics",
+					Scheme:          "https",
+					BearerTokenFile: prometheusBearerTokenFile,
+					TLSConfig: &monitoringv1.TLSConfig{
+						SafeTLSConfig: monitoringv1.SafeTLSConfig{
+							ServerName: fmt.Sprintf("%s.%s.svc", service.Name, service.Namespace),
+						},
+						CAFile: prometheusServiceCAFile,
+					},
+				},
+			},
+			NamespaceSelector: monitoringv1.NamespaceSelector{
+				MatchNames: []string{service.Namespace},
+			},
+			Selector: *deployment.Spec.Selector.DeepCopy(),
+		},
+	}
+}
+
+// desiredPrometheusRule returns the PrometheusRule with the alerts on the reconcile errors, the AWS API errors, the
+// latency of the webhooks and the availability of the deployment. The metrics of the controller are selected by the
+// job of the ServiceMonitor, which is named after the service.
+func desiredPrometheusRule(service *corev1.Service, deployment *appsv1.Deployment) *monitoringv1.PrometheusRule {
+	job := fmt.Sprintf(`job=%q,namespace=%q`, service.Name, service.Namespace)
+	return &monitoringv1.PrometheusRule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      deployment.Name,
+			Namespace: deployment.Namespace,
+		},
+		Spec: monitoringv1.PrometheusRuleSpec{
+			Groups: []monitoringv1.RuleGroup{
+				{
+					Name: "aws-load-balancer-controller.rules",
+					Rules: []monitoringv1.Rule{
+						{
+							Alert: "AWSLoadBalancerControllerReconcileErrors",
+							Expr:  intstr.FromString(fmt.Sprintf(`sum by (controller) (rate(controller_runtime_reconcile_errors_total{%s}[5m])) > 0`, job)),
+							For:   "15m",
+							Labels: map[string]string{
+								alertSeverityLabel: "warning",
+							},
+							Annotations: map[string]string{
+								"summary":     "The AWS Load Balancer Controller fails to reconcile resources.",
+								"description": "The {{ $labels.controller }} controller of the AWS Load Balancer Controller has been failing to reconcile for 15 minutes, the load balancers may not match their Ingresses and Services.",
+							},
+						},
+						{
+							Alert: "AWSLoadBalancerControllerAWSAPIErrors",
+							Expr:  intstr.FromString(fmt.Sprintf(`sum by (service, operation, error_code) (rate(aws_api_calls_total{%s,error_code!=""}[5m])) > 0`, job)),
+							For:   "15m",
+							Labels: map[string]string{
+								alertSeverityLabel: "warning",
+							},
+							Annotations: map[string]string{
+								"summary":     "The AWS Load Balancer Controller gets errors from the AWS API.",
+								"description": "The {{ $labels.service }} {{ $labels.operation }} calls of the AWS Load Balancer Controller have been failing with {{ $labels.error_code }} for 15 minutes.",
+							},
+						},
+						{
+							Alert: "AWSLoadBalancerControllerWebhookLatencyHigh",
+							Expr:  intstr.FromString(fmt.Sprintf(`histogram_quantile(0.99, sum by (le, webhook) (rate(controller_runtime_webhook_latency_seconds_bucket{%s}[5m]))) > 1`, job)),
+							For:   "10m",
+							Labels: map[string]string{
+								alertSeverityLabel: "warning",
+							},
+							Annotations: map[string]string{
+								"summary":     "The webhooks of the AWS Load Balancer Controller are slow.",
+								"description": "The 99th percentile latency of the {{ $labels.webhook }} webhook of the AWS Load Balancer Controller has been above 1 second for 10 minutes, the admission of Ingresses, Services and Pods may time out.",
+							},
+						},
+						{
+							Alert: "AWSLoadBalancerControllerDeploymentUnavailable",
+							Expr:  intstr.FromString(fmt.Sprintf(`kube_deployment_status_replicas_available{namespace=%q,deployment=%q} == 0`, deployment.Namespace, deployment.Name)),
+							For:   "5m",
+							Labels: map[string]string{
+								alertSeverityLabel: "critical",
+							},
+							Annotations: map[string]string{
+								"summary":     "The AWS Load Balancer Controller is unavailable.",
+								"description": "The deployment {{ $labels.namespace }}/{{ $labels.deployment }} has had no available replica for 5 minutes, the load balancers are not reconciled and the webhooks reject the admission of the resources they serve.",
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,208 @@
+package awsloadbalancercontroller
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/google/go-cmp/cmp"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	albo "github.com/openshift/aws-load-balancer-operator/api/v1alpha1"
+	"github.com/openshift/aws-load-balancer-operator/pkg/controllers/utils/test"
+)
+
+func TestEnsureMonitoring(t *testing.T) {
+	name := controllerResourcePrefix + "-cluster"
+	selector := &metav1.LabelSelector{MatchLabels: map[string]string{appLabelName: appName, appInstanceName: "cluster"}}
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: test.OperatorNamespace},
+		Spec:       appsv1.DeploymentSpec{Selector: selector},
+	}
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: test.OperatorNamespace},
+	}
+	for _, tc := range []struct {
+		name                   string
+		monitoring             *albo.AWSLoadBalancerMonitoringConfig
+		existingObjects        []client.Object
+		expectedServiceMonitor bool
+		expectedPrometheusRule bool
+	}{
+		{
+			name:                   "default",
+			expectedServiceMonitor: true,
+			expectedPrometheusRule: true,
+		},
+		{
+			name:                   "enabled",
+			monitoring:             &albo.AWSLoadBalancerMonitoringConfig{ServiceMonitor: albo.MonitoringEnabled, AlertingRules: albo.MonitoringEnabled},
+			expectedServiceMonitor: true,
+			expectedPrometheusRule: true,
+		},
+		{
+			name:       "service monitor disabled",
+			monitoring: &albo.AWSLoadBalancerMonitoringConfig{ServiceMonitor: albo.MonitoringDisabled},
+			existingObjects: []client.Object{
+				&monitoringv1.ServiceMonitor{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: test.OperatorNamespace}},
+			},
+			expectedPrometheusRule: true,
+		},
+		{
+			name:       "alerting rules disabled",
+			monitoring: &albo.AWSLoadBalancerMonitoringConfig{AlertingRules: albo.MonitoringDisabled},
+			existingObjects: []client.Object{
+				&monitoringv1.PrometheusRule{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: test.OperatorNamespace}},
+			},
+			expectedServiceMonitor: true,
+		},
+		{
+			name:       "monitoring disabled",
+			monitoring: &albo.AWSLoadBalancerMonitoringConfig{ServiceMonitor: albo.MonitoringDisabled, AlertingRules: albo.MonitoringDisabled},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			testClient := test.NewApplyClient(fake.NewClientBuilder().WithScheme(test.Scheme).WithObjects(tc.existingObjects...).Build())
+			r := &AWSLoadBalancerControllerReconciler{
+				Client: testClient,
+				Scheme: test.Scheme,
+			}
+			controller := &albo.AWSLoadBalancerController{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Spec:       albo.AWSLoadBalancerControllerSpec{Monitoring: tc.monitoring},
+			}
+			if err := r.ensureServiceMonitor(context.Background(), controller, service, deployment); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if err := r.ensurePrometheusRule(context.Background(), controller, service, deployment); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var serviceMonitor monitoringv1.ServiceMonitor
+			err := testClient.Get(context.Background(), types.NamespacedName{Namespace: test.OperatorNamespace, Name: name}, &serviceMonitor)
+			if err != nil && !errors.IsNotFound(err) {
+				t.Fatalf("failed to get service monitor: %v", err)
+			}
+			if exists := err == nil; exists != tc.expectedServiceMonitor {
+				t.Fatalf("expected service monitor to exist %t, got %t", tc.expectedServiceMonitor, exists)
+			}
+			if tc.expectedServiceMonitor {
+				expectedEndpoints := []monitoringv1.Endpoint{
+					{
+						Port:            "metrics",
+						Scheme:          "https",
+						BearerTokenFile: "/var/run/secrets/kubernetes.io/serviceaccount/token",
+						TLSConfig: &monitoringv1.TLSConfig{
+							SafeTLSConfig: monitoringv1.SafeTLSConfig{ServerName: name + "." + test.OperatorNamespace + ".svc"},
+							CAFile:        "/etc/prometheus/configmaps/serving-certs-ca-bundle/service-ca.crt",
+						},
+					},
+				}
+				if diff := cmp.Diff(expectedEndpoints, serviceMonitor.Spec.Endpoints); diff != "" {
+					t.Errorf("unexpected service monitor endpoints\n%s", diff)
+				}
+				if diff := cmp.Diff(*selector, serviceMonitor.Spec.Selector); diff != "" {
+					t.Errorf("unexpected service monitor selector\n%s", diff)
+				}
+				if diff := cmp.Diff([]string{test.OperatorNamespace}, serviceMonitor.Spec.NamespaceSelector.MatchNames); diff != "" {
+					t.Errorf("unexpected service monitor namespaces\n%s", diff)
+				}
+				if diff := cmp.Diff(testControllerReferences("cluster"), serviceMonitor.OwnerReferences); diff != "" {
+					t.Errorf("unexpected service monitor owner references\n%s", diff)
+				}
+			}
+
+			var rule monitoringv1.PrometheusRule
+			err = testClient.Get(context.Background(), types.NamespacedName{Namespace: test.OperatorNamespace, Name: name}, &rule)
+			if err != nil && !errors.IsNotFound(err) {
+				t.Fatalf("failed to get prometheus rule: %v", err)
+			}
+			if exists := err == nil; exists != tc.expectedPrometheusRule {
+				t.Fatalf("expected prometheus rule to exist %t, got %t", tc.expectedPrometheusRule, exists)
+			}
+			if tc.expectedPrometheusRule {
+				var alerts []string
+				for _, group := range rule.Spec.Groups {
+					for _, r := range group.Rules {
+						alerts = append(alerts, r.Alert)
+					}
+				}
+				expectedAlerts := []string{
+					"AWSLoadBalancerControllerReconcileErrors",
+					"AWSLoadBalancerControllerAWSAPIErrors",
+					"AWSLoadBalancerControllerWebhookLatencyHigh",
+					"AWSLoadBalancerControllerDeploymentUnavailable",
+				}
+				if diff := cmp.Diff(expectedAlerts, alerts); diff != "" {
+					t.Errorf("unexpected alerts\n%s", diff)
+				}
+			}
+		})
+	}
+}
+
+func TestMonitoringAPIAvailable(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		kinds    []string
+		expected bool
+	}{
+		{
+			name:     "monitoring CRDs installed",
+			kinds:    []string{monitoringv1.ServiceMonitorsKind, monitoringv1.PrometheusRuleKind},
+			expected: true,
+		},
+		{
+			name:  "PrometheusRule CRD missing",
+			kinds: []string{monitoringv1.ServiceMonitorsKind},
+		},
+		{
+			name: "monitoring CRDs missing",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			mapper := meta.NewDefaultRESTMapper(nil)
+			for _, kind := range tc.kinds {
+				mapper.Add(monitoringv1.SchemeGroupVersion.WithKind(kind), meta.RESTScopeNamespace)
+			}
+			available, err := monitoringAPIAvailable(mapper)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if available != tc.expected {
+				t.Errorf("expected monitoring API available %t, got %t", tc.expected, available)
+			}
+		})
+	}
+}
+
+func TestDesiredPrometheusRuleExpressions(t *testing.T) {
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "test-deployment", Namespace: "test-namespace"}}
+	service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "test-service", Namespace: "test-namespace"}}
+	expected := map[string]string{
+		"AWSLoadBalancerControllerReconcileErrors":       `sum by (controller) (rate(controller_runtime_reconcile_errors_total{job="test-service",namespace="test-namespace"}[5m])) > 0`,
+		"AWSLoadBalancerControllerAWSAPIErrors":          `sum by (service, operation, error_code) (rate(aws_api_calls_total{job="test-service",namespace="test-namespace",error_code!=""}[5m])) > 0`,
+		"AWSLoadBalancerControllerWebhookLatencyHigh":    `histogram_quantile(0.99, sum by (le, webhook) (rate(controller_runtime_webhook_latency_seconds_bucket{job="test-service",namespace="test-namespace"}[5m]))) > 1`,
+		"AWSLoadBalancerControllerDeploymentUnavailable": `kube_deployment_status_replicas_available{namespace="test-namespace",deployment="test-deployment"} == 0`,
+	}
+	expressions := make(map[string]string)
+	for _, group := range desiredPrometheusRule(service, deployment).Spec.Groups {
+		for _, rule := range group.Rules {
+			expressions[rule.Alert] = rule.Expr.String()
+			if rule.Labels[alertSeverityLabel] == "" {
+				t.Errorf("alert %s has no severity", rule.Alert)
+			}
+		}
+	}
+	if diff := cmp.Diff(expected, expressions); diff != "" {
+		t.Errorf("unexpected alert expressions\n%s", diff)
+	}
+}
@@ -0,0 +1,207 @@
+package awsloadbalancercontroller
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	configv1 "github.com/openshift/api/config/v1"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	albo "github.com/openshift/aws-load-balancer-operator/api/v1alpha1"
+)
+
+const (
+	// monitoringNamespace is the namespace of the cluster monitoring which scrapes the metrics of the operand
+	monitoringNamespace = "openshift-monitoring"
+	// apiServerPort is the port of the endpoints of the kubernetes service, the network policies match the
+	// destination port after the service address is translated
+	apiServerPort = 6443
+	// httpsPort is the default port of the AWS API endpoints
+	httpsPort = 443
+	// dnsPort and dnsTargetPort are the port of the cluster DNS service and the port of its pods
+	dnsPort       = 53
+	dnsTargetPort = 5353
+)
+
+// ensureNetworkPolicy ensures the NetworkPolicy which allows the traffic of the operand pods, so that the webhook
+// keeps working in namespaces with a default deny policy.
+func (r *AWSLoadBalancerControllerReconciler) ensureNetworkPolicy(ctx context.Context, namespace string, controller *albo.AWSLoadBalancerController, deployment *appsv1.Deployment) error {
+	proxy, err := r.currentClusterProxy(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get cluster proxy for network policy %s: %w", deployment.Name, err)
+	}
+
+	desired, err := desiredNetworkPolicy(deployment.Name, namespace, deployment.Spec.Selector, proxy, r.AWSServiceEndpoints)
+	if err != nil {
+		return fmt.Errorf("failed to build network policy %s: %w", deployment.Name, err)
+	}
+	if err := controllerutil.SetControllerReference(controller, desired, r.Scheme); err != nil {
+		return fmt.Errorf("failed to set owner reference on network policy %s: %w", desired.Name, err)
+	}
+	if err := r.apply(ctx, desired); err != nil {
+		return fmt.Errorf("failed to apply network policy %s: %w", desired.Name, err)
+	}
+	return nil
+}
+
+// desiredNetworkPolicy returns the NetworkPolicy of the operand pods. The ingress is allowed to the webhook port from
+// any peer as the API server runs on the host network, or outside the cluster when the control plane is external,
+// and to the metrics port from the cluster monitoring. The egress is allowed to the cluster DNS, the API server and
+// the AWS API endpoints, the AWS API is reached through the proxy when one is configured.
+func desiredNetworkPolicy(name, namespace string, selector *metav1.LabelSelector, proxy *configv1.ProxyStatus, awsServiceEndpoints map[string]string) (*networkingv1.NetworkPolicy, error) {
+	awsRules, err := awsEgressRules(proxy, awsServiceEndpoints)
+	if err != nil {
+		return nil, err
+	}
+	return &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: *selector.DeepCopy(),
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{
+				{
+					Ports: []networkingv1.NetworkPolicyPort{tcpPort(controllerWebhookPort)},
+				},
+				{
+					Ports: []networkingv1.NetworkPolicyPort{tcpPort(metricsProxyPort)},
+					From: []networkingv1.NetworkPolicyPeer{
+						{
+							NamespaceSelector: &metav1.LabelSelector{
+								MatchLabels: map[string]string{corev1.LabelMetadataName: monitoringNamespace},
+							},
+						},
+					},
+				},
+			},
+			Egress: append([]networkingv1.NetworkPolicyEgressRule{
+				{
+					Ports: []networkingv1.NetworkPolicyPort{
+						{Protocol: protocolPtr(corev1.ProtocolUDP), Port: portPtr(dnsPort)},
+						{Protocol: protocolPtr(corev1.ProtocolTCP), Port: portPtr(dnsPort)},
+						{Protocol: protocolPtr(corev1.ProtocolUDP), Port: portPtr(dnsTargetPort)},
+						{Protocol: protocolPtr(corev1.ProtocolTCP), Port: portPtr(dnsTargetPort)},
+					},
+				},
+				{
+					Ports: []networkingv1.NetworkPolicyPort{tcpPort(apiServerPort)},
+				},
+			}, awsRules...),
+		},
+	}, nil
+}
+
+// awsEgressRules returns the egress rules to the AWS API. Without a proxy the AWS API endpoints are allowed by their
+// ports as their addresses are not known. With a proxy only the proxy is allowed, by its address when it's an IP. The
+// custom service endpoints, e.g. the VPC endpoints, are excluded from the proxy with the no proxy list so their ports
+// are always allowed.
+func awsEgressRules(proxy *configv1.ProxyStatus, awsServiceEndpoints map[string]string) ([]networkingv1.NetworkPolicyEgressRule, error) {
+	var rules []networkingv1.NetworkPolicyEgressRule
+	endpointPorts := make(map[int]struct{})
+	for name, endpoint := range awsServiceEndpoints {
+		_, port, err := urlHostPort(endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid endpoint of AWS service %s: %w", name, err)
+		}
+		endpointPorts[port] = struct{}{}
+	}
+
+	var proxies []string
+	if proxy != nil {
+		for _, proxyURL := range []string{proxy.HTTPProxy, proxy.HTTPSProxy} {
+			if proxyURL != "" {
+				proxies = append(proxies, proxyURL)
+			}
+		}
+	}
+	if len(proxies) == 0 {
+		endpointPorts[httpsPort] = struct{}{}
+	}
+	seen := make(map[string]struct{})
+	for _, proxyURL := range proxies {
+		host, port, err := urlHostPort(proxyURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cluster proxy: %w", err)
+		}
+		// the HTTP and HTTPS proxies are usually the same
+		key := net.JoinHostPort(host, strconv.Itoa(port))
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		rule := networkingv1.NetworkPolicyEgressRule{
+			Ports: []networkingv1.NetworkPolicyPort{tcpPort(port)},
+		}
+		if ip := net.ParseIP(host); ip != nil {
+			bits := 32
+			if ip.To4() == nil {
+				bits = 128
+			}
+			rule.To = []networkingv1.NetworkPolicyPeer{
+				{IPBlock: &networkingv1.IPBlock{CIDR: fmt.Sprintf("%s/%d", ip.String(), bits)}},
+			}
+		}
+		rules = append(rules, rule)
+	}
+
+	if len(endpointPorts) > 0 {
+		ports := make([]int, 0, len(endpointPorts))
+		for port := range endpointPorts {
+			ports = append(ports, port)
+		}
+		sort.Ints(ports)
+		rule := networkingv1.NetworkPolicyEgressRule{}
+		for _, port := range ports {
+			rule.Ports = append(rule.Ports, tcpPort(port))
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// urlHostPort returns the host and the port of the given URL, the port defaults to the one of the scheme.
+func urlHostPort(rawURL string) (string, int, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", 0, err
+	}
+	if u.Hostname() == "" {
+		return "", 0, fmt.Errorf("URL %q has no host", rawURL)
+	}
+	if u.Port() == "" {
+		if u.Scheme == "http" {
+			return u.Hostname(), 80, nil
+		}
+		return u.Hostname(), httpsPort, nil
+	}
+	port, err := strconv.Atoi(u.Port())
+	if err != nil {
+		return "", 0, fmt.Errorf("URL %q has an invalid port: %w", rawURL, err)
+	}
+	return u.Hostname(), port, nil
+}
+
+func tcpPort(port int) networkingv1.NetworkPolicyPort {
+	return networkingv1.NetworkPolicyPort{Protocol: protocolPtr(corev1.ProtocolTCP), Port: portPtr(port)}
+}
+
+func protocolPtr(protocol corev1.Protocol) *corev1.Protocol {
+	return &protocol
+}
+
+func portPtr(port int) *intstr.IntOrString {
+	p := intstr.FromInt(port)
+	return &p
+}
@@ -0,0 +1,223 @@
+package awsloadbalancercontroller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	arv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	configv1 "github.com/openshift/api/config/v1"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	albo "github.com/openshift/aws-load-balancer-operator/api/v1alpha1"
+	"github.com/openshift/aws-load-balancer-operator/pkg/aws"
+)
+
+const (
+	// operandControllerName is the name of the controller which ensures the operand workload
+	operandControllerName = "operand"
+)
+
+// operandReconciler ensures the workload of the operand: its service account, RBAC, trusted CA bundle, deployment,
+// service, network policy, webhook certificates, pod disruption budget, monitoring resources and webhooks. It reports
+// the DeploymentAvailable, DeploymentUpgrading, WebhooksReady and ResourceTagsValid conditions. The workload waits
+// for the platform, the VPC and the credentials secret, whose failures are reported by the other controllers, and for
+// the injection of the trusted CA bundle.
+type operandReconciler struct {
+	*AWSLoadBalancerControllerReconciler
+}
+
+func (r *operandReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	ctx = aws.WithCallBudget(ctx, awsCallBudget)
+
+	lbController, err := r.reconciledController(ctx, r.Client, req.Name)
+	if err != nil || lbController == nil {
+		return ctrl.Result{}, err
+	}
+
+	if err := r.ensurePlatform(ctx); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to discover platform: %w", err)
+	}
+
+	vpcID, _, err := r.discoverVPC(ctx, lbController)
+	if err != nil {
+		logger.Info("(Retrying) failed to discover VPC", "error", err.Error())
+		requeueAfter := vpcDiscoveryReEnqueueDuration
+		if awsRequeueAfter, ok := awsErrorRequeueDuration(err); ok {
+			requeueAfter = awsRequeueAfter
+		}
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+
+	// the credentials secret is minted from the CredentialsRequest of the credentials controller
+	credentialsSecretRef := createCredentialsSecretRef(credentialsSecretPrefix+lbController.Name, r.Namespace)
+	secretProvisioned, err := r.credentialsSecretProvisioned(ctx, credentialsSecretRef)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to verify credentials secret %q for AWSLoadBalancerController %q has been provisioned: %w", credentialsSecretRef.Name, req.Name, err)
+	}
+	if !secretProvisioned {
+		logger.Info("(Retrying) credentials secret is not yet provisioned", "secret", credentialsSecretRef.Name)
+		return ctrl.Result{RequeueAfter: secretMissingReEnqueueDuration}, nil
+	}
+
+	// the merged resource tags over the AWS limit are reported, the deployment keeps the last valid tags until the
+	// spec or the Infrastructure changes
+	_, resourceTagsErr := r.resourceTags(ctx, lbController)
+	if resourceTagsErr != nil && !errors.Is(resourceTagsErr, errTooManyResourceTags) {
+		return ctrl.Result{}, fmt.Errorf("failed to get resource tags for AWSLoadBalancerController %q: %w", req.Name, resourceTagsErr)
+	}
+
+	servingSecretName := servingSecretPrefix + lbController.Name
+
+	sa, err := r.ensureControllerServiceAccount(ctx, r.Namespace, lbController)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to ensure AWSLoadBalancerController %q service account: %w", req.Name, err)
+	}
+
+	err = r.ensureClusterRoleAndBinding(ctx, sa, lbController)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to ensure ClusterRole and Binding for AWSLoadBalancerController %q: %w", req.Name, err)
+	}
+
+	trustedCABundle, err := r.ensureTrustedCABundleConfigMap(ctx, r.Namespace, lbController)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to ensure trusted CA bundle ConfigMap for AWSLoadBalancerController %q: %w", req.Name, err)
+	}
+	// the bundle replaces the system trust bundle of the pods, which can't call AWS until it's injected
+	if _, ok := trustedCABundle.Data[trustedCABundleKey]; !ok {
+		logger.Info("(Retrying) trusted CA bundle is not yet injected", "configmap", trustedCABundle.Name)
+		return ctrl.Result{RequeueAfter: trustedCABundleMissingReEnqueueDuration}, nil
+	}
+
+	// the replicas, the leader election and the placement of the operand are defaulted from the cluster topology
+	config := desiredOperandConfig(lbController, r.Topology)
+
+	// the webhook certificates are ensured before the deployment so that the pod template has the hash of the serving
+	// secret which is mounted, the secret issued by a previous provider is deleted when the provider changes
+	certs, err := r.ensureWebhookCertificates(ctx, r.Namespace, lbController, controllerServiceName(r.Namespace, lbController), servingSecretName)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to ensure webhook certificates for AWSLoadBalancerController %q: %w", req.Name, err)
+	}
+
+	deployment, err := r.ensureDeployment(ctx, r.Namespace, r.Image, vpcID, sa, credentialsSecretRef.Name, servingSecretName, trustedCABundle, lbController, config)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to ensure Deployment for AWSLoadbalancerController %q: %w", req.Name, err)
+	}
+
+	// the service CA operator issues the serving certificate from the annotation of the service
+	var serviceCASecretName string
+	if webhookCertificatesProvider(lbController) == albo.ServiceCAWebhookCertificatesProvider {
+		serviceCASecretName = servingSecretName
+	}
+	service, err := r.ensureService(ctx, r.Namespace, lbController, serviceCASecretName, deployment)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to ensure service for AWSLoadBalancerController %q: %w", req.Name, err)
+	}
+
+	if err := r.ensureNetworkPolicy(ctx, r.Namespace, lbController, deployment); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to ensure network policy for AWSLoadBalancerController %q: %w", req.Name, err)
+	}
+
+	if err := r.ensurePodDisruptionBudget(ctx, lbController, config, deployment); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to ensure pod disruption budget for AWSLoadBalancerController %q: %w", req.Name, err)
+	}
+
+	// the monitoring resources can't be created on the clusters without the monitoring CRDs
+	if !r.monitoringUnavailable {
+		if err := r.ensureServiceMonitor(ctx, lbController, service, deployment); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to ensure service monitor for AWSLoadBalancerController %q: %w", req.Name, err)
+		}
+
+		if err := r.ensurePrometheusRule(ctx, lbController, service, deployment); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to ensure prometheus rule for AWSLoadBalancerController %q: %w", req.Name, err)
+		}
+	}
+
+	err = r.ensureWebhooks(ctx, lbController, service, certs)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to ensure webhooks for AWSLoadBalancerController %q: %w", req.Name, err)
+	}
+
+	readyEndpoints, err := r.readyServiceEndpoints(ctx, service)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get endpoints of service for AWSLoadBalancerController %q: %w", req.Name, err)
+	}
+
+	webhooksReady, err := r.webhooksReadyCondition(ctx, lbController, service, servingSecretName, readyEndpoints)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to check webhooks for AWSLoadBalancerController %q: %w", req.Name, err)
+	}
+
+	status := &controllerStatus{}
+	status.addConditions(deploymentConditions(deployment, service, readyEndpoints, lbController.Generation)...)
+	status.addConditions(webhooksReady, resourceTagsValidCondition(resourceTagsErr, lbController.Generation))
+	status.setDeployment(config.statusDeployment())
+	if err := r.writeStatus(ctx, operandControllerName, lbController, status); err != nil {
+		return ctrl.Result{}, err
+	}
+	// the serving certificate issued by the operator is renewed before it expires
+	requeueAfter := certs.renewAfter
+	// the dry-run admission isn't triggered by the watched resources, it's retried until it succeeds
+	if r.WebhookDryRun && webhooksReady.Status != metav1.ConditionTrue && (requeueAfter == 0 || requeueAfter > webhooksNotReadyReEnqueueDuration) {
+		requeueAfter = webhooksNotReadyReEnqueueDuration
+	}
+	return ctrl.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// SetupWithManager sets up the operand controller with the Manager. The secrets mounted by the operand, the cluster
+// proxy and the Infrastructure are watched to roll out the deployment when they change. The endpoint slices of the
+// webhook service are watched to report the deployment available once the webhook is served. The monitoring resources
+// are only watched and reconciled when their CRDs are installed, the operator must be restarted to reconcile them
+// once the CRDs are installed.
+func (r *operandReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	monitoringAvailable, err := monitoringAPIAvailable(mgr.GetRESTMapper())
+	if err != nil {
+		return err
+	}
+	r.monitoringUnavailable = !monitoringAvailable
+	if !monitoringAvailable {
+		mgr.GetLogger().Info("monitoring.coreos.com CRDs are not installed, the monitoring resources of the operand are not reconciled")
+	}
+
+	bldr := ctrl.NewControllerManagedBy(mgr).
+		Named(operandControllerName).
+		For(&albo.AWSLoadBalancerController{}, builder.WithPredicates(reconcileClusterNamedResource(), predicate.GenerationChangedPredicate{})).
+		Owns(&corev1.ServiceAccount{}).
+		Owns(&rbacv1.ClusterRoleBinding{}).
+		Owns(&rbacv1.Role{}).
+		Owns(&rbacv1.RoleBinding{}).
+		Owns(&appsv1.Deployment{}).
+		Owns(&corev1.Service{}).
+		Owns(&networkingv1.NetworkPolicy{}).
+		Owns(&policyv1.PodDisruptionBudget{}).
+		Owns(&corev1.ConfigMap{}).
+		Owns(&arv1.ValidatingWebhookConfiguration{}).
+		Owns(&arv1.MutatingWebhookConfiguration{})
+	if monitoringAvailable {
+		bldr = bldr.
+			Owns(&monitoringv1.ServiceMonitor{}).
+			Owns(&monitoringv1.PrometheusRule{})
+	}
+	return bldr.
+		Watches(&source.Kind{Type: &discoveryv1.EndpointSlice{}}, handler.EnqueueRequestsFromMapFunc(r.endpointSliceToControllerRequests)).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, handler.EnqueueRequestsFromMapFunc(r.secretToControllerRequests)).
+		Watches(&source.Kind{Type: &configv1.Proxy{}}, handler.EnqueueRequestsFromMapFunc(proxyToControllerRequests)).
+		Watches(&source.Kind{Type: &configv1.Infrastructure{}}, handler.EnqueueRequestsFromMapFunc(infrastructureToControllerRequests)).
+		Complete(r)
+}
@@ -0,0 +1,258 @@
+package awsloadbalancercontroller
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/pointer"
+
+	configv1 "github.com/openshift/api/config/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	albo "github.com/openshift/aws-load-balancer-operator/api/v1alpha1"
+	"github.com/openshift/aws-load-balancer-operator/pkg/controllers/utils/test"
+)
+
+func TestOperandReconcile(t *testing.T) {
+	for _, tc := range []struct {
+		name                             string
+		config                           *albo.AWSLoadBalancerDeploymentConfig
+		topology                         ClusterTopology
+		existingObjects                  []client.Object
+		expectedResult                   ctrl.Result
+		expectedDeployment               bool
+		expectedPodDisruptionBudget      bool
+		expectedAvailableConditionReason string
+		expectedReplicas                 int32
+	}{
+		{
+			name:           "credentials secret not provisioned",
+			expectedResult: ctrl.Result{RequeueAfter: secretMissingReEnqueueDuration},
+		},
+		{
+			name: "trusted CA bundle not injected",
+			existingObjects: []client.Object{
+				&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: credentialsSecretPrefix + controllerName, Namespace: test.OperatorNamespace}},
+				testPreExistingClusterRole(),
+			},
+			expectedResult: ctrl.Result{RequeueAfter: trustedCABundleMissingReEnqueueDuration},
+		},
+		{
+			// the subnets are not tagged yet, the operand doesn't wait for them
+			name: "credentials secret provisioned",
+			existingObjects: []client.Object{
+				&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: credentialsSecretPrefix + controllerName, Namespace: test.OperatorNamespace}},
+				testPreExistingClusterRole(),
+				testInjectedTrustedCABundle(),
+			},
+			expectedDeployment:               true,
+			expectedPodDisruptionBudget:      true,
+			expectedAvailableConditionReason: "AllDeploymentReplicasNotAvailable",
+			expectedReplicas:                 2,
+		},
+		{
+			name:     "single node cluster",
+			topology: ClusterTopology{ControlPlane: configv1.SingleReplicaTopologyMode, Infrastructure: configv1.SingleReplicaTopologyMode},
+			existingObjects: []client.Object{
+				&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: credentialsSecretPrefix + controllerName, Namespace: test.OperatorNamespace}},
+				testPreExistingClusterRole(),
+				testInjectedTrustedCABundle(),
+			},
+			expectedDeployment:               true,
+			expectedAvailableConditionReason: "AllDeploymentReplicasNotAvailable",
+			expectedReplicas:                 1,
+		},
+		{
+			name:   "high availability",
+			config: &albo.AWSLoadBalancerDeploymentConfig{Replicas: 2},
+			existingObjects: []client.Object{
+				&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: credentialsSecretPrefix + controllerName, Namespace: test.OperatorNamespace}},
+				testPreExistingClusterRole(),
+				testInjectedTrustedCABundle(),
+			},
+			expectedDeployment:               true,
+			expectedPodDisruptionBudget:      true,
+			expectedAvailableConditionReason: "AllDeploymentReplicasNotAvailable",
+			expectedReplicas:                 2,
+		},
+		{
+			name:   "deployment available without webhook endpoints",
+			config: &albo.AWSLoadBalancerDeploymentConfig{Replicas: 1},
+			existingObjects: []client.Object{
+				&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: credentialsSecretPrefix + controllerName, Namespace: test.OperatorNamespace}},
+				testPreExistingClusterRole(),
+				testInjectedTrustedCABundle(),
+				&appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{Name: controllerResourcePrefix + "-" + controllerName, Namespace: test.OperatorNamespace},
+					Status:     appsv1.DeploymentStatus{AvailableReplicas: 1, UpdatedReplicas: 1},
+				},
+			},
+			expectedDeployment:               true,
+			expectedAvailableConditionReason: "WebhookEndpointsNotReady",
+			expectedReplicas:                 1,
+		},
+		{
+			name:   "deployment available with webhook endpoints",
+			config: &albo.AWSLoadBalancerDeploymentConfig{Replicas: 1},
+			existingObjects: []client.Object{
+				&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: credentialsSecretPrefix + controllerName, Namespace: test.OperatorNamespace}},
+				testPreExistingClusterRole(),
+				testInjectedTrustedCABundle(),
+				&appsv1.Deployment{
+					ObjectMeta: metav1.ObjectMeta{Name: controllerResourcePrefix + "-" + controllerName, Namespace: test.OperatorNamespace},
+					Status:     appsv1.DeploymentStatus{AvailableReplicas: 1, UpdatedReplicas: 1},
+				},
+				&discoveryv1.EndpointSlice{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      controllerResourcePrefix + "-" + controllerName + "-abcde",
+						Namespace: test.OperatorNamespace,
+						Labels:    map[string]string{discoveryv1.LabelServiceName: controllerResourcePrefix + "-" + controllerName},
+					},
+					AddressType: discoveryv1.AddressTypeIPv4,
+					Endpoints:   []discoveryv1.Endpoint{{Addresses: []string{"10.0.0.1"}, Conditions: discoveryv1.EndpointConditions{Ready: pointer.Bool(true)}}},
+				},
+			},
+			expectedDeployment:               true,
+			expectedAvailableConditionReason: "AllDeploymentReplicasAvailable",
+			expectedReplicas:                 1,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			controller := &albo.AWSLoadBalancerController{
+				ObjectMeta: metav1.ObjectMeta{Name: controllerName},
+				Spec:       albo.AWSLoadBalancerControllerSpec{IngressClass: "alb", Config: tc.config},
+			}
+			testClient := test.NewApplyClient(fake.NewClientBuilder().WithScheme(test.Scheme).WithObjects(append(tc.existingObjects, controller)...).Build())
+			r := &operandReconciler{&AWSLoadBalancerControllerReconciler{
+				Client:      testClient,
+				Scheme:      test.Scheme,
+				Namespace:   test.OperatorNamespace,
+				Image:       test.OperandImage,
+				ClusterName: "test-cluster",
+				AWSRegion:   "us-east-1",
+				VPCID:       "test-vpc",
+				Topology:    tc.topology,
+			}}
+
+			result, err := r.Reconcile(context.Background(), ctrl.Request{NamespacedName: types.NamespacedName{Name: controllerName}})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tc.expectedResult {
+				t.Errorf("expected result %v, got %v", tc.expectedResult, result)
+			}
+
+			var deployment appsv1.Deployment
+			err = testClient.Get(context.Background(), types.NamespacedName{Namespace: test.OperatorNamespace, Name: controllerResourcePrefix + "-" + controllerName}, &deployment)
+			if err != nil && !errors.IsNotFound(err) {
+				t.Fatalf("failed to get deployment: %v", err)
+			}
+			if exists := err == nil; exists != tc.expectedDeployment {
+				t.Errorf("expected deployment to exist %t, got %t", tc.expectedDeployment, exists)
+			}
+
+			var pdb policyv1.PodDisruptionBudget
+			err = testClient.Get(context.Background(), types.NamespacedName{Namespace: test.OperatorNamespace, Name: controllerResourcePrefix + "-" + controllerName}, &pdb)
+			if err != nil && !errors.IsNotFound(err) {
+				t.Fatalf("failed to get pod disruption budget: %v", err)
+			}
+			if exists := err == nil; exists != tc.expectedPodDisruptionBudget {
+				t.Errorf("expected pod disruption budget to exist %t, got %t", tc.expectedPodDisruptionBudget, exists)
+			}
+
+			var updated albo.AWSLoadBalancerController
+			if err := testClient.Get(context.Background(), types.NamespacedName{Name: controllerName}, &updated); err != nil {
+				t.Fatalf("failed to get controller: %v", err)
+			}
+			for _, conditionType := range []string{SubnetsTaggedCondition, CredentialsValidCondition} {
+				if condition := meta.FindStatusCondition(updated.Status.Conditions, conditionType); condition != nil {
+					t.Errorf("unexpected condition %s reported by the operand controller", conditionType)
+				}
+			}
+			condition := meta.FindStatusCondition(updated.Status.Conditions, DeploymentAvailableCondition)
+			if (condition != nil) != tc.expectedDeployment {
+				t.Errorf("expected %s condition to be reported %t, got %v", DeploymentAvailableCondition, tc.expectedDeployment, condition)
+			}
+			if condition != nil && condition.Reason != tc.expectedAvailableConditionReason {
+				t.Errorf("expected %s condition reason %q, got %q", DeploymentAvailableCondition, tc.expectedAvailableConditionReason, condition.Reason)
+			}
+			// the CA bundle of the webhooks is never injected without the service CA operator
+			condition = meta.FindStatusCondition(updated.Status.Conditions, WebhooksReadyCondition)
+			if (condition != nil) != tc.expectedDeployment {
+				t.Errorf("expected %s condition to be reported %t, got %v", WebhooksReadyCondition, tc.expectedDeployment, condition)
+			}
+			if condition != nil && condition.Reason != "CABundleNotInjected" {
+				t.Errorf("expected %s condition reason %q, got %q", WebhooksReadyCondition, "CABundleNotInjected", condition.Reason)
+			}
+			if tc.expectedDeployment && (updated.Status.Deployment == nil || updated.Status.Deployment.Replicas != tc.expectedReplicas) {
+				t.Errorf("expected %d replicas in status, got %+v", tc.expectedReplicas, updated.Status.Deployment)
+			}
+		})
+	}
+}
+
+// TestOperandReconcileServingSecretReissued checks that the deployment rolls out with the serving secret issued by the
+// new webhook certificates provider rather than with the secret of the previous provider.
+func TestOperandReconcileServingSecretReissued(t *testing.T) {
+	ctx := context.Background()
+	controller := &albo.AWSLoadBalancerController{
+		ObjectMeta: metav1.ObjectMeta{Name: controllerName},
+		Spec: albo.AWSLoadBalancerControllerSpec{
+			IngressClass:        "alb",
+			WebhookCertificates: &albo.AWSLoadBalancerWebhookCertificatesConfig{Provider: albo.SelfWebhookCertificatesProvider},
+		},
+	}
+	serviceCASecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        servingSecretPrefix + controllerName,
+			Namespace:   test.OperatorNamespace,
+			Annotations: map[string]string{serviceCAOriginatingServiceAnnotation: controllerResourcePrefix + "-" + controllerName},
+		},
+		Data: map[string][]byte{corev1.TLSCertKey: []byte("service-ca-cert"), corev1.TLSPrivateKeyKey: []byte("service-ca-key")},
+	}
+	testClient := test.NewApplyClient(fake.NewClientBuilder().WithScheme(test.Scheme).WithObjects(
+		controller,
+		serviceCASecret,
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: credentialsSecretPrefix + controllerName, Namespace: test.OperatorNamespace}},
+		testPreExistingClusterRole(),
+		testInjectedTrustedCABundle(),
+	).Build())
+	r := &operandReconciler{&AWSLoadBalancerControllerReconciler{
+		Client:      testClient,
+		Scheme:      test.Scheme,
+		Namespace:   test.OperatorNamespace,
+		Image:       test.OperandImage,
+		ClusterName: "test-cluster",
+		AWSRegion:   "us-east-1",
+		VPCID:       "test-vpc",
+	}}
+
+	if _, err := r.Reconcile(ctx, ctrl.Request{NamespacedName: types.NamespacedName{Name: controllerName}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var secret corev1.Secret
+	if err := testClient.Get(ctx, types.NamespacedName{Namespace: test.OperatorNamespace, Name: servingSecretPrefix + controllerName}, &secret); err != nil {
+		t.Fatalf("failed to get serving secret: %v", err)
+	}
+	if provider, _ := servingSecretProvider(&secret); provider != albo.SelfWebhookCertificatesProvider {
+		t.Fatalf("expected serving secret issued by %q, got %q", albo.SelfWebhookCertificatesProvider, provider)
+	}
+	var deployment appsv1.Deployment
+	if err := testClient.Get(ctx, types.NamespacedName{Namespace: test.OperatorNamespace, Name: controllerResourcePrefix + "-" + controllerName}, &deployment); err != nil {
+		t.Fatalf("failed to get deployment: %v", err)
+	}
+	if hash := deployment.Spec.Template.Annotations[servingSecretHashAnnotation]; hash != dataHash(secret.Data) {
+		t.Errorf("expected the hash of the reissued serving secret %q in the pod template, got %q", dataHash(secret.Data), hash)
+	}
+}
@@ -0,0 +1,60 @@
+package awsloadbalancercontroller
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	albo "github.com/openshift/aws-load-balancer-operator/api/v1alpha1"
+)
+
+// ensurePodDisruptionBudget ensures the PodDisruptionBudget of the deployment in the high availability mode so that
+// a node drain evicts the replicas one at a time and the webhook keeps an endpoint. The PodDisruptionBudget is
+// deleted when the mode is turned off.
+func (r *AWSLoadBalancerControllerReconciler) ensurePodDisruptionBudget(ctx context.Context, controller *albo.AWSLoadBalancerController, config operandConfig, deployment *appsv1.Deployment) error {
+	if !config.highAvailability {
+		var current policyv1.PodDisruptionBudget
+		err := r.Get(ctx, types.NamespacedName{Namespace: deployment.Namespace, Name: deployment.Name}, &current)
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return nil
+			}
+			return fmt.Errorf("failed to get pod disruption budget %s/%s: %w", deployment.Namespace, deployment.Name, err)
+		}
+		if err := r.Delete(ctx, &current); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete pod disruption budget %s/%s: %w", deployment.Namespace, deployment.Name, err)
+		}
+		return nil
+	}
+
+	desired := desiredPodDisruptionBudget(deployment)
+	if err := controllerutil.SetControllerReference(controller, desired, r.Scheme); err != nil {
+		return fmt.Errorf("failed to set owner reference on pod disruption budget %s: %w", desired.Name, err)
+	}
+	if err := r.apply(ctx, desired); err != nil {
+		return fmt.Errorf("failed to apply pod disruption budget %s: %w", desired.Name, err)
+	}
+	return nil
+}
+
+func desiredPodDisruptionBudget(deployment *appsv1.Deployment) *policyv1.PodDisruptionBudget {
+	maxUnavailable := intstr.FromInt(1)
+	return &policyv1.PodDisruptionBudget{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      deployment.Name,
+			Namespace: deployment.Namespace,
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MaxUnavailable: &maxUnavailable,
+			Selector:       deployment.Spec.Selector,
+		},
+	}
+}
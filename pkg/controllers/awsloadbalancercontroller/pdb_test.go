@@ -0,0 +1,107 @@
+package awsloadbalancercontroller
+
+import (
+	"context"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	configv1 "github.com/openshift/api/config/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	albo "github.com/openshift/aws-load-balancer-operator/api/v1alpha1"
+	"github.com/openshift/aws-load-balancer-operator/pkg/controllers/utils/test"
+)
+
+func TestEnsurePodDisruptionBudget(t *testing.T) {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: controllerResourcePrefix + "-cluster", Namespace: test.OperatorNamespace},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{appLabelName: appName, appInstanceName: "cluster"}},
+		},
+	}
+	maxUnavailable := intstr.FromInt(1)
+	for _, tc := range []struct {
+		name            string
+		config          *albo.AWSLoadBalancerDeploymentConfig
+		topology        ClusterTopology
+		existingObjects []client.Object
+		expectedPDB     *policyv1.PodDisruptionBudget
+	}{
+		{
+			name:   "single replica",
+			config: &albo.AWSLoadBalancerDeploymentConfig{Replicas: 1},
+		},
+		{
+			name:     "single node cluster",
+			config:   &albo.AWSLoadBalancerDeploymentConfig{Replicas: 2},
+			topology: ClusterTopology{ControlPlane: configv1.SingleReplicaTopologyMode, Infrastructure: configv1.SingleReplicaTopologyMode},
+		},
+		{
+			name:   "high availability",
+			config: &albo.AWSLoadBalancerDeploymentConfig{Replicas: 2},
+			expectedPDB: &policyv1.PodDisruptionBudget{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:            controllerResourcePrefix + "-cluster",
+					Namespace:       test.OperatorNamespace,
+					Labels:          map[string]string{managedByLabelKey: managedByLabelValue},
+					OwnerReferences: testControllerReferences("cluster"),
+				},
+				Spec: policyv1.PodDisruptionBudgetSpec{
+					MaxUnavailable: &maxUnavailable,
+					Selector:       deployment.Spec.Selector,
+				},
+			},
+		},
+		{
+			name:   "high availability turned off",
+			config: &albo.AWSLoadBalancerDeploymentConfig{Replicas: 1},
+			existingObjects: []client.Object{
+				&policyv1.PodDisruptionBudget{
+					ObjectMeta: metav1.ObjectMeta{Name: controllerResourcePrefix + "-cluster", Namespace: test.OperatorNamespace},
+				},
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			testClient := test.NewApplyClient(fake.NewClientBuilder().WithScheme(test.Scheme).WithObjects(tc.existingObjects...).Build())
+			r := &AWSLoadBalancerControllerReconciler{
+				Client: testClient,
+				Scheme: test.Scheme,
+			}
+			controller := &albo.AWSLoadBalancerController{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Spec:       albo.AWSLoadBalancerControllerSpec{Config: tc.config},
+			}
+			if err := r.ensurePodDisruptionBudget(context.Background(), controller, desiredOperandConfig(controller, tc.topology), deployment); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var pdb policyv1.PodDisruptionBudget
+			err := testClient.Get(context.Background(), types.NamespacedName{Namespace: test.OperatorNamespace, Name: controllerResourcePrefix + "-cluster"}, &pdb)
+			if tc.expectedPDB == nil {
+				if !errors.IsNotFound(err) {
+					t.Fatalf("expected no pod disruption budget, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to get pod disruption budget: %v", err)
+			}
+			if diff := cmp.Diff(tc.expectedPDB.ObjectMeta, pdb.ObjectMeta, cmpopts.IgnoreFields(metav1.ObjectMeta{}, "ResourceVersion")); diff != "" {
+				t.Errorf("unexpected pod disruption budget metadata\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedPDB.Spec, pdb.Spec); diff != "" {
+				t.Errorf("unexpected pod disruption budget spec\n%s", diff)
+			}
+		})
+	}
+}
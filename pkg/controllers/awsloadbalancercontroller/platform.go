@@ -0,0 +1,290 @@
+package awsloadbalancercontroller
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+
+	"golang.org/x/net/http/httpproxy"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	configv1 "github.com/openshift/api/config/v1"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/openshift/aws-load-balancer-operator/pkg/aws"
+)
+
+// PlatformDiscoveredCondition reports whether the cluster details were found and the AWS clients were made
+const PlatformDiscoveredCondition = "PlatformDiscovered"
+
+// Platform holds the details of the cluster and the AWS clients of the operator.
+type Platform struct {
+	ClusterName string
+	AWSRegion   string
+	// AWSServiceEndpoints maps the AWS service names to the URLs which override the default endpoints
+	AWSServiceEndpoints map[string]string
+	Topology            ClusterTopology
+	EC2Client           aws.EC2Client
+	IAMClient           aws.IAMClient
+	NewSTSClient        func(ctx context.Context, accessKeyID, secretAccessKey string) (aws.STSClient, error)
+}
+
+// PlatformDiscoveryFunc discovers the platform of the cluster.
+type PlatformDiscoveryFunc func(ctx context.Context) (*Platform, error)
+
+// PlatformDiscoveryOptions are the options of the platform discovery of the operator.
+type PlatformDiscoveryOptions struct {
+	// Namespace is the namespace of the operator
+	Namespace string
+	// TrustedCAConfigMap is the name of the ConfigMap in the operator namespace with the trusted CA bundle used
+	// for the AWS API calls. The system roots are used when it's empty.
+	TrustedCAConfigMap string
+	// CredentialsFile is the shared credentials file of the operator which is mounted from the operator secret.
+	// The discovery waits for the file when it's not empty.
+	CredentialsFile string
+}
+
+// NewPlatformDiscovery returns the discovery of the platform from the cluster-wide Infrastructure and Proxy. The AWS
+// clients use the cluster-wide proxy and the trusted CA bundle. The platform is discovered on every call, the
+// previous platform and its AWS clients are returned as long as the cluster details, the proxy and the trusted CA
+// bundle don't change. The returned function must not be called concurrently.
+func NewPlatformDiscovery(c client.Client, opts PlatformDiscoveryOptions) PlatformDiscoveryFunc {
+	var (
+		discovered     *Platform
+		discoveredHash string
+	)
+	return func(ctx context.Context) (*Platform, error) {
+		if opts.CredentialsFile != "" {
+			if _, err := os.Stat(opts.CredentialsFile); err != nil {
+				return nil, fmt.Errorf("operator credentials file is not available, the operator credentials secret may not be provisioned yet: %w", err)
+			}
+		}
+
+		clusterName, awsRegion, serviceEndpoints, topology, err := clusterInfo(ctx, c)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get cluster details: %w", err)
+		}
+
+		proxyConfig, err := clusterHTTPProxy(ctx, c)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get cluster proxy: %w", err)
+		}
+
+		var caBundle []byte
+		if opts.TrustedCAConfigMap != "" {
+			caBundle, err = operatorTrustedCABundle(ctx, c, opts.Namespace, opts.TrustedCAConfigMap)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get trusted CA bundle: %w", err)
+			}
+		}
+
+		// the AWS clients are only made again when their configuration changed
+		hash := platformHash(clusterName, awsRegion, serviceEndpoints, topology, proxyConfig, caBundle)
+		if discovered != nil && hash == discoveredHash {
+			return discovered, nil
+		}
+
+		httpClient, err := aws.NewHTTPClient(proxyConfig, caBundle)
+		if err != nil {
+			return nil, fmt.Errorf("failed to make http client for aws: %w", err)
+		}
+
+		ec2Client, err := aws.NewClient(ctx, awsRegion, httpClient, serviceEndpoints)
+		if err != nil {
+			return nil, fmt.Errorf("failed to make EC2 client: %w", err)
+		}
+
+		// the IAM client simulates the policies of the operand credentials in the pre-flight checks
+		iamClient, err := aws.NewIAMClient(ctx, awsRegion, httpClient, serviceEndpoints)
+		if err != nil {
+			return nil, fmt.Errorf("failed to make IAM client: %w", err)
+		}
+
+		discovered = &Platform{
+			ClusterName:         clusterName,
+			AWSRegion:           awsRegion,
+			AWSServiceEndpoints: serviceEndpoints,
+			Topology:            topology,
+			EC2Client:           ec2Client,
+			IAMClient:           iamClient,
+			NewSTSClient: func(ctx context.Context, accessKeyID, secretAccessKey string) (aws.STSClient, error) {
+				return aws.NewSTSClient(ctx, awsRegion, httpClient, serviceEndpoints, accessKeyID, secretAccessKey)
+			},
+		}
+		discoveredHash = hash
+		return discovered, nil
+	}
+}
+
+// platformHash returns a hash of the cluster details and of the configuration of the AWS clients.
+func platformHash(clusterName, awsRegion string, serviceEndpoints map[string]string, topology ClusterTopology, proxyConfig *httpproxy.Config, caBundle []byte) string {
+	data := map[string][]byte{
+		"clusterName":            []byte(clusterName),
+		"awsRegion":              []byte(awsRegion),
+		"controlPlaneTopology":   []byte(topology.ControlPlane),
+		"infrastructureTopology": []byte(topology.Infrastructure),
+		"caBundle":               caBundle,
+	}
+	for name, url := range serviceEndpoints {
+		data["serviceEndpoint/"+name] = []byte(url)
+	}
+	if proxyConfig != nil {
+		data["httpProxy"] = []byte(proxyConfig.HTTPProxy)
+		data["httpsProxy"] = []byte(proxyConfig.HTTPSProxy)
+		data["noProxy"] = []byte(proxyConfig.NoProxy)
+	}
+	return dataHash(data)
+}
+
+// ensurePlatform discovers the platform and sets the reconciler fields when the discovered platform changed. Nothing
+// is discovered if no discovery is set, in which case the fields must be set already. The sub-controllers which use
+// the platform call it first, so that their AWS clients follow the changes of the cluster proxy and of the trusted
+// CA bundle.
+func (r *AWSLoadBalancerControllerReconciler) ensurePlatform(ctx context.Context) error {
+	r.platformLock.Lock()
+	defer r.platformLock.Unlock()
+	if r.DiscoverPlatform == nil {
+		return nil
+	}
+
+	platform, err := r.DiscoverPlatform(ctx)
+	r.setDiscoveryError(err)
+	if err != nil {
+		return err
+	}
+	if platform == r.platform {
+		return nil
+	}
+	log.FromContext(ctx).Info("discovered platform", "cluster", platform.ClusterName, "region", platform.AWSRegion,
+		"controlPlaneTopology", platform.Topology.ControlPlane, "infrastructureTopology", platform.Topology.Infrastructure)
+
+	r.ClusterName = platform.ClusterName
+	r.AWSRegion = platform.AWSRegion
+	r.AWSServiceEndpoints = platform.AWSServiceEndpoints
+	r.Topology = platform.Topology
+	r.EC2Client = platform.EC2Client
+	r.IAMClient = platform.IAMClient
+	r.NewSTSClient = platform.NewSTSClient
+	r.platform = platform
+	return nil
+}
+
+// setDiscoveryError records the error of the last discovery of the platform for the readiness check.
+func (r *AWSLoadBalancerControllerReconciler) setDiscoveryError(err error) {
+	r.discoveryLock.Lock()
+	defer r.discoveryLock.Unlock()
+	r.discoveryErr = err
+}
+
+// DiscoveryReadyCheck is a readiness check which fails while the platform can't be discovered. The VPC discovery
+// failures don't fail it: they are solved by selecting the VPC in the spec, which must be admitted by the webhook
+// served behind the readiness of the operator.
+func (r *AWSLoadBalancerControllerReconciler) DiscoveryReadyCheck(_ *http.Request) error {
+	r.discoveryLock.Lock()
+	defer r.discoveryLock.Unlock()
+	if r.discoveryErr != nil {
+		return fmt.Errorf("platform discovery failed: %w", r.discoveryErr)
+	}
+	return nil
+}
+
+// platformDiscoveredCondition returns the PlatformDiscovered condition with the result of the platform discovery.
+func platformDiscoveredCondition(err error, generation int64) metav1.Condition {
+	if err != nil {
+		return metav1.Condition{
+			Type:               PlatformDiscoveredCondition,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: generation,
+			Reason:             "PlatformDiscoveryFailed",
+			Message:            err.Error(),
+		}
+	}
+	return metav1.Condition{
+		Type:               PlatformDiscoveredCondition,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             "PlatformDiscovered",
+		Message:            "The cluster details were found and the AWS clients were made",
+	}
+}
+
+// clusterInfo returns the name, the AWS region, the custom AWS service endpoints and the topology of the cluster
+// from the cluster-wide Infrastructure.
+func clusterInfo(ctx context.Context, c client.Client) (clusterName, awsRegion string, serviceEndpoints map[string]string, topology ClusterTopology, err error) {
+	var infra configv1.Infrastructure
+	infraKey := types.NamespacedName{
+		Name: clusterInfrastructureName,
+	}
+	err = c.Get(ctx, infraKey, &infra)
+	if err != nil {
+		err = fmt.Errorf("failed to get Infrastructure %q: %w", clusterInfrastructureName, err)
+		return
+	}
+
+	if infra.Status.InfrastructureName == "" {
+		err = fmt.Errorf("could not get infrastructure name from Infrastructure %q status", clusterInfrastructureName)
+		return
+	}
+	clusterName = infra.Status.InfrastructureName
+	topology = ClusterTopology{
+		ControlPlane:   infra.Status.ControlPlaneTopology,
+		Infrastructure: infra.Status.InfrastructureTopology,
+	}
+
+	if infra.Status.PlatformStatus == nil || infra.Status.PlatformStatus.AWS == nil || infra.Status.PlatformStatus.AWS.Region == "" {
+		err = fmt.Errorf("could not get AWS region from Infrastructure %q status", clusterInfrastructureName)
+		return
+	}
+	awsRegion = infra.Status.PlatformStatus.AWS.Region
+
+	// custom endpoints are used in GovCloud, China, C2S/SC2S regions and with VPC interface endpoints
+	if len(infra.Status.PlatformStatus.AWS.ServiceEndpoints) > 0 {
+		serviceEndpoints = make(map[string]string)
+		for _, endpoint := range infra.Status.PlatformStatus.AWS.ServiceEndpoints {
+			serviceEndpoints[endpoint.Name] = endpoint.URL
+		}
+	}
+	return
+}
+
+// clusterHTTPProxy returns the proxy configuration from the cluster-wide Proxy. If the Proxy doesn't exist or has no
+// proxy set then nil is returned and the proxy from the environment is used.
+func clusterHTTPProxy(ctx context.Context, c client.Client) (*httpproxy.Config, error) {
+	var proxy configv1.Proxy
+	err := c.Get(ctx, types.NamespacedName{Name: clusterProxyName}, &proxy)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get Proxy %q: %w", clusterProxyName, err)
+	}
+	if proxy.Status.HTTPProxy == "" && proxy.Status.HTTPSProxy == "" {
+		return nil, nil
+	}
+	return &httpproxy.Config{
+		HTTPProxy:  proxy.Status.HTTPProxy,
+		HTTPSProxy: proxy.Status.HTTPSProxy,
+		NoProxy:    proxy.Status.NoProxy,
+	}, nil
+}
+
+// operatorTrustedCABundle returns the CA bundle injected into the given ConfigMap. An empty bundle is returned if the
+// ConfigMap doesn't exist or the bundle hasn't been injected yet.
+func operatorTrustedCABundle(ctx context.Context, c client.Client, namespace, name string) ([]byte, error) {
+	var configMap corev1.ConfigMap
+	err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &configMap)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			log.FromContext(ctx).Info("trusted CA bundle ConfigMap not found, using system roots", "configmap", name)
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get ConfigMap %s/%s: %w", namespace, name, err)
+	}
+	return []byte(configMap.Data[trustedCABundleKey]), nil
+}
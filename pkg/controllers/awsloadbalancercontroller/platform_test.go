@@ -0,0 +1,268 @@
+package awsloadbalancercontroller
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	configv1 "github.com/openshift/api/config/v1"
+
+	"github.com/openshift/aws-load-balancer-operator/pkg/controllers/utils/test"
+)
+
+func TestClusterInfo(t *testing.T) {
+	for _, tc := range []struct {
+		name                     string
+		infra                    *configv1.Infrastructure
+		expectedClusterName      string
+		expectedRegion           string
+		expectedServiceEndpoints map[string]string
+		expectedTopology         ClusterTopology
+		expectedError            string
+	}{
+		{
+			name:          "missing infrastructure",
+			expectedError: `failed to get Infrastructure "cluster": infrastructures.config.openshift.io "cluster" not found`,
+		},
+		{
+			name: "missing infrastructure name",
+			infra: &configv1.Infrastructure{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+			},
+			expectedError: `could not get infrastructure name from Infrastructure "cluster" status`,
+		},
+		{
+			name: "missing region",
+			infra: &configv1.Infrastructure{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Status: configv1.InfrastructureStatus{
+					InfrastructureName: "test-cluster",
+				},
+			},
+			expectedError: `could not get AWS region from Infrastructure "cluster" status`,
+		},
+		{
+			name: "region and service endpoints",
+			infra: &configv1.Infrastructure{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Status: configv1.InfrastructureStatus{
+					InfrastructureName: "test-cluster",
+					PlatformStatus: &configv1.PlatformStatus{
+						AWS: &configv1.AWSPlatformStatus{
+							Region: "us-gov-west-1",
+							ServiceEndpoints: []configv1.AWSServiceEndpoint{
+								{Name: "ec2", URL: "https://ec2.us-gov-west-1.amazonaws.com"},
+							},
+						},
+					},
+				},
+			},
+			expectedClusterName:      "test-cluster",
+			expectedRegion:           "us-gov-west-1",
+			expectedServiceEndpoints: map[string]string{"ec2": "https://ec2.us-gov-west-1.amazonaws.com"},
+		},
+		{
+			name: "hosted control plane topology",
+			infra: &configv1.Infrastructure{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Status: configv1.InfrastructureStatus{
+					InfrastructureName:     "test-cluster",
+					ControlPlaneTopology:   configv1.ExternalTopologyMode,
+					InfrastructureTopology: configv1.HighlyAvailableTopologyMode,
+					PlatformStatus: &configv1.PlatformStatus{
+						AWS: &configv1.AWSPlatformStatus{Region: "us-east-1"},
+					},
+				},
+			},
+			expectedClusterName: "test-cluster",
+			expectedRegion:      "us-east-1",
+			expectedTopology:    ClusterTopology{ControlPlane: configv1.ExternalTopologyMode, Infrastructure: configv1.HighlyAvailableTopologyMode},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var objs []client.Object
+			if tc.infra != nil {
+				objs = append(objs, tc.infra)
+			}
+			c := fake.NewClientBuilder().WithScheme(test.Scheme).WithObjects(objs...).Build()
+
+			clusterName, region, serviceEndpoints, topology, err := clusterInfo(context.Background(), c)
+			if tc.expectedError != "" {
+				if err == nil || err.Error() != tc.expectedError {
+					t.Fatalf("expected error %q, got %v", tc.expectedError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if clusterName != tc.expectedClusterName {
+				t.Errorf("expected cluster name %q, got %q", tc.expectedClusterName, clusterName)
+			}
+			if region != tc.expectedRegion {
+				t.Errorf("expected region %q, got %q", tc.expectedRegion, region)
+			}
+			if diff := cmp.Diff(tc.expectedServiceEndpoints, serviceEndpoints); diff != "" {
+				t.Errorf("unexpected service endpoints (-want +got):\n%s", diff)
+			}
+			if topology != tc.expectedTopology {
+				t.Errorf("expected topology %+v, got %+v", tc.expectedTopology, topology)
+			}
+		})
+	}
+}
+
+func TestPlatformDiscoveryCredentialsFile(t *testing.T) {
+	c := fake.NewClientBuilder().WithScheme(test.Scheme).Build()
+	credentialsFile := filepath.Join(t.TempDir(), "credentials")
+
+	_, err := NewPlatformDiscovery(c, PlatformDiscoveryOptions{CredentialsFile: credentialsFile})(context.Background())
+	if err == nil {
+		t.Fatalf("expected error when the credentials file doesn't exist")
+	}
+}
+
+func TestPlatformDiscoveryClientsChange(t *testing.T) {
+	ctx := context.Background()
+	infra := &configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: clusterInfrastructureName},
+		Status: configv1.InfrastructureStatus{
+			InfrastructureName: "test-cluster",
+			PlatformStatus: &configv1.PlatformStatus{
+				Type: configv1.AWSPlatformType,
+				AWS:  &configv1.AWSPlatformStatus{Region: "us-east-1"},
+			},
+		},
+	}
+	proxy := &configv1.Proxy{ObjectMeta: metav1.ObjectMeta{Name: clusterProxyName}}
+	c := fake.NewClientBuilder().WithScheme(test.Scheme).WithObjects(infra, proxy).Build()
+	discover := NewPlatformDiscovery(c, PlatformDiscoveryOptions{})
+
+	first, err := discover(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	unchanged, err := discover(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if unchanged != first {
+		t.Errorf("expected the same platform when nothing changed")
+	}
+
+	proxy.Status.HTTPSProxy = "http://proxy.example.com:3128"
+	if err := c.Status().Update(ctx, proxy); err != nil {
+		t.Fatalf("failed to update proxy: %v", err)
+	}
+	changed, err := discover(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if changed == first || changed.EC2Client == first.EC2Client {
+		t.Errorf("expected new AWS clients when the proxy changed")
+	}
+}
+
+func TestEnsurePlatform(t *testing.T) {
+	discoveryErr := fmt.Errorf("could not get AWS region from Infrastructure \"cluster\" status")
+	platform := &Platform{
+		ClusterName:         "test-cluster",
+		AWSRegion:           "us-east-1",
+		AWSServiceEndpoints: map[string]string{"ec2": "https://ec2.example.com"},
+	}
+	calls := 0
+	r := &AWSLoadBalancerControllerReconciler{
+		DiscoverPlatform: func(_ context.Context) (*Platform, error) {
+			calls++
+			switch calls {
+			case 1:
+				return nil, discoveryErr
+			case 4:
+				// the proxy or the trusted CA bundle changed
+				return &Platform{ClusterName: "test-cluster", AWSRegion: "us-east-1"}, nil
+			}
+			return platform, nil
+		},
+	}
+
+	// the first discovery fails and is reported by the readiness check
+	err := r.ensurePlatform(context.Background())
+	if err != discoveryErr {
+		t.Fatalf("expected error %v, got %v", discoveryErr, err)
+	}
+	if err := r.DiscoveryReadyCheck(nil); err == nil {
+		t.Errorf("expected readiness check to fail after the failed discovery")
+	}
+	if r.ClusterName != "" {
+		t.Errorf("expected no cluster name after the failed discovery, got %q", r.ClusterName)
+	}
+
+	// the retry succeeds and the same platform is discovered again
+	for i := 0; i < 2; i++ {
+		if err := r.ensurePlatform(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := r.DiscoveryReadyCheck(nil); err != nil {
+		t.Errorf("unexpected readiness check error: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("expected 3 discoveries, got %d", calls)
+	}
+	if r.ClusterName != "test-cluster" || r.AWSRegion != "us-east-1" {
+		t.Errorf("unexpected cluster name %q and region %q", r.ClusterName, r.AWSRegion)
+	}
+	if diff := cmp.Diff(map[string]string{"ec2": "https://ec2.example.com"}, r.AWSServiceEndpoints); diff != "" {
+		t.Errorf("unexpected service endpoints (-want +got):\n%s", diff)
+	}
+
+	// the changed platform replaces the previous one
+	if err := r.ensurePlatform(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if r.AWSServiceEndpoints != nil {
+		t.Errorf("expected the service endpoints of the changed platform, got %v", r.AWSServiceEndpoints)
+	}
+}
+
+func TestPlatformDiscoveredCondition(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		err      error
+		expected metav1.Condition
+	}{
+		{
+			name: "discovered",
+			expected: metav1.Condition{
+				Type:               PlatformDiscoveredCondition,
+				Status:             metav1.ConditionTrue,
+				ObservedGeneration: 2,
+				Reason:             "PlatformDiscovered",
+				Message:            "The cluster details were found and the AWS clients were made",
+			},
+		},
+		{
+			name: "discovery failed",
+			err:  fmt.Errorf("failed to get cluster details"),
+			expected: metav1.Condition{
+				Type:               PlatformDiscoveredCondition,
+				Status:             metav1.ConditionFalse,
+				ObservedGeneration: 2,
+				Reason:             "PlatformDiscoveryFailed",
+				Message:            "failed to get cluster details",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, platformDiscoveredCondition(tc.err, 2)); diff != "" {
+				t.Errorf("unexpected condition (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
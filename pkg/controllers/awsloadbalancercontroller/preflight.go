@@ -0,0 +1,236 @@
+package awsloadbalancercontroller
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	cco "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+
+	albo "github.com/openshift/aws-load-balancer-operator/api/v1alpha1"
+	"github.com/openshift/aws-load-balancer-operator/pkg/aws"
+)
+
+const (
+	// CredentialsValidCondition reports whether the credentials of the operator and the operand allow the AWS calls they make
+	CredentialsValidCondition = "CredentialsValid"
+
+	// keys of the secret minted by the cloud credential operator
+	awsAccessKeyIDKey     = "aws_access_key_id"
+	awsSecretAccessKeyKey = "aws_secret_access_key"
+	awsCredentialsKey     = "credentials"
+	// roleARNKey is the key of the role in the shared credentials file of the secrets of STS clusters
+	roleARNKey = "role_arn"
+)
+
+// credentialsCheck holds the result of the pre-flight check of the credentials.
+type credentialsCheck struct {
+	// operatorMissing are the actions which the operator credentials don't allow
+	operatorMissing []string
+	// operandMissing are the actions which the operand credentials don't allow
+	operandMissing []string
+	// skipped are the checks which couldn't be done
+	skipped []string
+	// err is the error which prevented the check
+	err error
+	// errCredentials names the credentials of the AWS call which failed with err
+	errCredentials string
+}
+
+// credentialsCheckKey identifies the inputs of the pre-flight check of the credentials: the AWSLoadBalancerController,
+// the subnet on which the operator credentials are checked and the credentials secret of the operand.
+type credentialsCheckKey struct {
+	generation int64
+	subnetID   string
+	// secretResourceVersion is the resource version of the credentials secret, empty when it's not provisioned
+	secretResourceVersion string
+}
+
+// cachedCredentialsCheck is the result of a check and the time at which it was done.
+type cachedCredentialsCheck struct {
+	key       credentialsCheckKey
+	check     credentialsCheck
+	checkedAt time.Time
+}
+
+// cachedCheckCredentials returns the last result of checkCredentials while its inputs don't change and it's not
+// older than the recheck interval, so that the AWS calls of the check are not made on every reconcile. The checks
+// which failed with an error are not cached, they are retried on the next reconcile.
+func (r *AWSLoadBalancerControllerReconciler) cachedCheckCredentials(ctx context.Context, controller *albo.AWSLoadBalancerController, credentialsRequest *cco.CredentialsRequest, secretProvisioned bool) (credentialsCheck, error) {
+	key := credentialsCheckKey{
+		generation: controller.Generation,
+		subnetID:   clusterSubnetID(controller),
+	}
+	if secretProvisioned {
+		secretRef := credentialsRequest.Spec.SecretRef
+		var secret corev1.Secret
+		if err := r.Get(ctx, types.NamespacedName{Namespace: secretRef.Namespace, Name: secretRef.Name}, &secret); err != nil {
+			return credentialsCheck{}, fmt.Errorf("failed to get credentials secret %s/%s: %w", secretRef.Namespace, secretRef.Name, err)
+		}
+		key.secretResourceVersion = secret.ResourceVersion
+	}
+
+	r.credentialsCheckLock.Lock()
+	defer r.credentialsCheckLock.Unlock()
+
+	if last := r.credentialsCheck; last != nil && last.key == key && time.Since(last.checkedAt) < credentialsRecheckInterval {
+		return last.check, nil
+	}
+	check := r.checkCredentials(ctx, controller, credentialsRequest, secretProvisioned)
+	if check.err != nil {
+		r.credentialsCheck = nil
+		return check, nil
+	}
+	r.credentialsCheck = &cachedCredentialsCheck{key: key, check: check, checkedAt: time.Now()}
+	return check, nil
+}
+
+// checkCredentials checks that the operator credentials allow to tag the subnets and that the operand credentials
+// allow the actions of the operand IAM policy. The operator credentials are checked with EC2 DryRun calls on one of
+// the cluster subnets. The operand credentials are checked by simulating the policies of their IAM principal.
+func (r *AWSLoadBalancerControllerReconciler) checkCredentials(ctx context.Context, controller *albo.AWSLoadBalancerController, credentialsRequest *cco.CredentialsRequest, secretProvisioned bool) credentialsCheck {
+	check := credentialsCheck{errCredentials: operatorCredentials}
+
+	if subnetID := clusterSubnetID(controller); subnetID != "" {
+		check.operatorMissing, check.err = aws.MissingTaggingPermissions(ctx, r.EC2Client, subnetID, tagKeyALBOTagged)
+		if check.err != nil {
+			check.err = fmt.Errorf("failed to check operator credentials: %w", check.err)
+			return check
+		}
+	} else {
+		check.skipped = append(check.skipped, "operator credentials: no cluster subnets found yet")
+	}
+
+	if !secretProvisioned {
+		check.skipped = append(check.skipped, "operand credentials: the credentials secret has not yet been provisioned")
+		return check
+	}
+	if r.IAMClient == nil {
+		check.skipped = append(check.skipped, "operand credentials: IAM policy simulation is disabled")
+		return check
+	}
+
+	principalARN, err := r.operandPrincipalARN(ctx, credentialsRequest.Spec.SecretRef)
+	if err != nil {
+		check.err = fmt.Errorf("failed to get the principal of the operand credentials: %w", err)
+		// the principal is returned by STS with the operand credentials
+		check.errCredentials = operandCredentials
+		return check
+	}
+	if principalARN == "" {
+		check.skipped = append(check.skipped, "operand credentials: the principal of the credentials can't be determined")
+		return check
+	}
+
+	check.operandMissing, err = aws.MissingActions(ctx, r.IAMClient, principalARN, operandActions())
+	if err != nil {
+		check.err = fmt.Errorf("failed to check operand credentials: %w", err)
+	}
+	return check
+}
+
+// operandPrincipalARN returns the ARN of the IAM principal of the credentials in the given secret. The role of the
+// secrets of STS clusters is returned as is, the principal of the access keys is returned by STS. An empty ARN is
+// returned if the principal can't be determined.
+func (r *AWSLoadBalancerControllerReconciler) operandPrincipalARN(ctx context.Context, secretRef corev1.ObjectReference) (string, error) {
+	var secret corev1.Secret
+	if err := r.Get(ctx, types.NamespacedName{Namespace: secretRef.Namespace, Name: secretRef.Name}, &secret); err != nil {
+		return "", fmt.Errorf("failed to get credentials secret %s/%s: %w", secretRef.Namespace, secretRef.Name, err)
+	}
+
+	if roleARN := sharedCredentialsValue(secret.Data[awsCredentialsKey], roleARNKey); roleARN != "" {
+		return roleARN, nil
+	}
+
+	accessKeyID, secretAccessKey := string(secret.Data[awsAccessKeyIDKey]), string(secret.Data[awsSecretAccessKeyKey])
+	if accessKeyID == "" || secretAccessKey == "" || r.NewSTSClient == nil {
+		return "", nil
+	}
+	stsClient, err := r.NewSTSClient(ctx, accessKeyID, secretAccessKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to make STS client: %w", err)
+	}
+	return aws.GetCallerPrincipalARN(ctx, stsClient)
+}
+
+// sharedCredentialsValue returns the value of the key in the AWS shared credentials file.
+func sharedCredentialsValue(file []byte, key string) string {
+	scanner := bufio.NewScanner(strings.NewReader(string(file)))
+	for scanner.Scan() {
+		k, v, found := strings.Cut(scanner.Text(), "=")
+		if found && strings.TrimSpace(k) == key {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
+
+// clusterSubnetID returns one of the cluster subnets found by the subnet tagging, or an empty string if the subnets
+// are not yet in the status.
+func clusterSubnetID(controller *albo.AWSLoadBalancerController) string {
+	subnets := controller.Status.Subnets
+	if subnets == nil {
+		return ""
+	}
+	for _, ids := range [][]string{subnets.Tagged, subnets.Public, subnets.Internal, subnets.Untagged} {
+		if len(ids) > 0 {
+			return ids[0]
+		}
+	}
+	return ""
+}
+
+// operandActions returns the sorted actions of the operand IAM policy.
+func operandActions() []string {
+	unique := make(map[string]struct{})
+	for _, statement := range GetIAMPolicy().Statement {
+		for _, action := range statement.Action {
+			unique[action] = struct{}{}
+		}
+	}
+	actions := make([]string, 0, len(unique))
+	for action := range unique {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+	return actions
+}
+
+// credentialsValidCondition returns the CredentialsValid condition with the result of the check.
+func credentialsValidCondition(check credentialsCheck, generation int64) metav1.Condition {
+	condition := metav1.Condition{
+		Type:               CredentialsValidCondition,
+		ObservedGeneration: generation,
+	}
+	switch {
+	case check.err != nil:
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason, condition.Message = awsErrorReasonAndMessage(check.err, "CredentialsCheckFailed", check.errCredentials)
+	case len(check.operatorMissing) > 0 || len(check.operandMissing) > 0:
+		var missing []string
+		if len(check.operatorMissing) > 0 {
+			missing = append(missing, fmt.Sprintf("operator credentials are missing %s", strings.Join(check.operatorMissing, ", ")))
+		}
+		if len(check.operandMissing) > 0 {
+			missing = append(missing, fmt.Sprintf("operand credentials are missing %s", strings.Join(check.operandMissing, ", ")))
+		}
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "MissingPermissions"
+		condition.Message = strings.Join(missing, "; ")
+	default:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "CredentialsValid"
+		condition.Message = "The credentials allow the AWS calls of the operator and the operand"
+		if len(check.skipped) > 0 {
+			condition.Message += fmt.Sprintf(", skipped checks: %s", strings.Join(check.skipped, "; "))
+		}
+	}
+	return condition
+}
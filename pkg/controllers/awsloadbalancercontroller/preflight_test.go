@@ -0,0 +1,346 @@
+package awsloadbalancercontroller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	awstypes "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	"github.com/aws/aws-sdk-go-v2/service/iam"
+	iamtypes "github.com/aws/aws-sdk-go-v2/service/iam/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/aws/smithy-go"
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	cco "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	albo "github.com/openshift/aws-load-balancer-operator/api/v1alpha1"
+	"github.com/openshift/aws-load-balancer-operator/pkg/aws"
+	"github.com/openshift/aws-load-balancer-operator/pkg/controllers/utils/test"
+)
+
+// testDryRunEC2Client answers the DryRun tagging calls with the given error codes.
+type testDryRunEC2Client struct {
+	aws.EC2Client
+	createTagsCode string
+	deleteTagsCode string
+	// createTagsCalls counts the CreateTags calls
+	createTagsCalls int
+}
+
+func (c *testDryRunEC2Client) CreateTags(_ context.Context, _ *ec2.CreateTagsInput, _ ...func(*ec2.Options)) (*ec2.CreateTagsOutput, error) {
+	c.createTagsCalls++
+	return nil, &smithy.GenericAPIError{Code: c.createTagsCode}
+}
+
+func (c *testDryRunEC2Client) DeleteTags(_ context.Context, _ *ec2.DeleteTagsInput, _ ...func(*ec2.Options)) (*ec2.DeleteTagsOutput, error) {
+	return nil, &smithy.GenericAPIError{Code: c.deleteTagsCode}
+}
+
+type testSTSClient struct {
+	arn string
+}
+
+func (c *testSTSClient) GetCallerIdentity(_ context.Context, _ *sts.GetCallerIdentityInput, _ ...func(*sts.Options)) (*sts.GetCallerIdentityOutput, error) {
+	return &sts.GetCallerIdentityOutput{Arn: awstypes.String(c.arn)}, nil
+}
+
+// testIAMClient denies the given actions to the given principal.
+type testIAMClient struct {
+	t         *testing.T
+	principal string
+	denied    map[string]bool
+}
+
+func (c *testIAMClient) SimulatePrincipalPolicy(_ context.Context, input *iam.SimulatePrincipalPolicyInput, _ ...func(*iam.Options)) (*iam.SimulatePrincipalPolicyOutput, error) {
+	c.t.Helper()
+	if principal := awstypes.ToString(input.PolicySourceArn); principal != c.principal {
+		c.t.Errorf("unexpected principal, expected %q, got %q", c.principal, principal)
+	}
+	output := &iam.SimulatePrincipalPolicyOutput{}
+	for _, action := range input.ActionNames {
+		decision := iamtypes.PolicyEvaluationDecisionTypeAllowed
+		if c.denied[action] {
+			decision = iamtypes.PolicyEvaluationDecisionTypeImplicitDeny
+		}
+		output.EvaluationResults = append(output.EvaluationResults, iamtypes.EvaluationResult{EvalActionName: awstypes.String(action), EvalDecision: decision})
+	}
+	return output, nil
+}
+
+func TestCheckCredentials(t *testing.T) {
+	const (
+		userARN = "arn:aws:iam::123456789012:user/test-user"
+		roleARN = "arn:aws:iam::123456789012:role/test-role"
+	)
+	subnets := &albo.AWSLoadBalancerControllerStatusSubnets{Tagged: []string{"subnet-1"}}
+	accessKeys := map[string][]byte{awsAccessKeyIDKey: []byte("key-id"), awsSecretAccessKeyKey: []byte("secret")}
+	for _, tc := range []struct {
+		name              string
+		subnets           *albo.AWSLoadBalancerControllerStatusSubnets
+		secretData        map[string][]byte
+		secretProvisioned bool
+		ec2Client         *testDryRunEC2Client
+		principal         string
+		operandDenied     map[string]bool
+		expectedCondition metav1.Condition
+	}{
+		{
+			name:              "all permissions allowed",
+			subnets:           subnets,
+			secretData:        accessKeys,
+			secretProvisioned: true,
+			ec2Client:         &testDryRunEC2Client{createTagsCode: "DryRunOperation", deleteTagsCode: "DryRunOperation"},
+			principal:         userARN,
+			expectedCondition: metav1.Condition{
+				Type:    CredentialsValidCondition,
+				Status:  metav1.ConditionTrue,
+				Reason:  "CredentialsValid",
+				Message: "The credentials allow the AWS calls of the operator and the operand",
+			},
+		},
+		{
+			name:              "missing operator and operand permissions",
+			subnets:           subnets,
+			secretData:        accessKeys,
+			secretProvisioned: true,
+			ec2Client:         &testDryRunEC2Client{createTagsCode: "DryRunOperation", deleteTagsCode: "UnauthorizedOperation"},
+			principal:         userARN,
+			operandDenied:     map[string]bool{"elasticloadbalancing:CreateLoadBalancer": true, "ec2:CreateSecurityGroup": true},
+			expectedCondition: metav1.Condition{
+				Type:    CredentialsValidCondition,
+				Status:  metav1.ConditionFalse,
+				Reason:  "MissingPermissions",
+				Message: "operator credentials are missing ec2:DeleteTags; operand credentials are missing ec2:CreateSecurityGroup, elasticloadbalancing:CreateLoadBalancer",
+			},
+		},
+		{
+			name:              "role of STS credentials",
+			subnets:           subnets,
+			secretData:        map[string][]byte{awsCredentialsKey: []byte("[default]\nrole_arn = " + roleARN + "\nweb_identity_token_file = /var/run/secrets/token\n")},
+			secretProvisioned: true,
+			ec2Client:         &testDryRunEC2Client{createTagsCode: "DryRunOperation", deleteTagsCode: "DryRunOperation"},
+			principal:         roleARN,
+			operandDenied:     map[string]bool{"elasticloadbalancing:CreateLoadBalancer": true},
+			expectedCondition: metav1.Condition{
+				Type:    CredentialsValidCondition,
+				Status:  metav1.ConditionFalse,
+				Reason:  "MissingPermissions",
+				Message: "operand credentials are missing elasticloadbalancing:CreateLoadBalancer",
+			},
+		},
+		{
+			name:      "subnets and secret not available",
+			ec2Client: &testDryRunEC2Client{},
+			expectedCondition: metav1.Condition{
+				Type:    CredentialsValidCondition,
+				Status:  metav1.ConditionTrue,
+				Reason:  "CredentialsValid",
+				Message: "The credentials allow the AWS calls of the operator and the operand, skipped checks: operator credentials: no cluster subnets found yet; operand credentials: the credentials secret has not yet been provisioned",
+			},
+		},
+		{
+			name:      "dry run failed",
+			subnets:   subnets,
+			ec2Client: &testDryRunEC2Client{createTagsCode: "InternalError"},
+			expectedCondition: metav1.Condition{
+				Type:    CredentialsValidCondition,
+				Status:  metav1.ConditionUnknown,
+				Reason:  "CredentialsCheckFailed",
+				Message: "failed to check operator credentials: failed to check the permission to tag subnet subnet-1: api error InternalError: ",
+			},
+		},
+		{
+			name:      "dry run throttled",
+			subnets:   subnets,
+			ec2Client: &testDryRunEC2Client{createTagsCode: "RequestLimitExceeded"},
+			expectedCondition: metav1.Condition{
+				Type:    CredentialsValidCondition,
+				Status:  metav1.ConditionUnknown,
+				Reason:  "AWSThrottled",
+				Message: "AWS throttled the AWS API, the calls are retried later, reduce the API calls made by other clients of the account: failed to check operator credentials: failed to check the permission to tag subnet subnet-1: api error RequestLimitExceeded: ",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			controller := &albo.AWSLoadBalancerController{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Status:     albo.AWSLoadBalancerControllerStatus{Subnets: tc.subnets},
+			}
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-secret", Namespace: "test-namespace"},
+				Data:       tc.secretData,
+			}
+			credentialsRequest := &cco.CredentialsRequest{
+				Spec: cco.CredentialsRequestSpec{
+					SecretRef: corev1.ObjectReference{Name: secret.Name, Namespace: secret.Namespace},
+				},
+			}
+			r := &AWSLoadBalancerControllerReconciler{
+				Client:    fake.NewClientBuilder().WithScheme(test.Scheme).WithObjects(secret).Build(),
+				EC2Client: tc.ec2Client,
+				IAMClient: &testIAMClient{t: t, principal: tc.principal, denied: tc.operandDenied},
+				NewSTSClient: func(_ context.Context, accessKeyID, secretAccessKey string) (aws.STSClient, error) {
+					if accessKeyID != "key-id" || secretAccessKey != "secret" {
+						t.Errorf("unexpected access keys %q/%q", accessKeyID, secretAccessKey)
+					}
+					return &testSTSClient{arn: userARN}, nil
+				},
+			}
+			check := r.checkCredentials(context.Background(), controller, credentialsRequest, tc.secretProvisioned)
+			condition := credentialsValidCondition(check, controller.Generation)
+			if diff := cmp.Diff(tc.expectedCondition, condition, cmpopts.IgnoreFields(metav1.Condition{}, "LastTransitionTime")); diff != "" {
+				t.Errorf("unexpected condition:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestCachedCheckCredentials(t *testing.T) {
+	const userARN = "arn:aws:iam::123456789012:user/test-user"
+	ctx := context.Background()
+	controller := &albo.AWSLoadBalancerController{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Generation: 1},
+		Status: albo.AWSLoadBalancerControllerStatus{
+			Subnets: &albo.AWSLoadBalancerControllerStatusSubnets{Tagged: []string{"subnet-1"}},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-secret", Namespace: "test-namespace"},
+		Data:       map[string][]byte{awsAccessKeyIDKey: []byte("key-id"), awsSecretAccessKeyKey: []byte("secret")},
+	}
+	credentialsRequest := &cco.CredentialsRequest{
+		Spec: cco.CredentialsRequestSpec{
+			SecretRef: corev1.ObjectReference{Name: secret.Name, Namespace: secret.Namespace},
+		},
+	}
+	ec2Client := &testDryRunEC2Client{createTagsCode: "DryRunOperation", deleteTagsCode: "DryRunOperation"}
+	testClient := fake.NewClientBuilder().WithScheme(test.Scheme).WithObjects(secret).Build()
+	r := &AWSLoadBalancerControllerReconciler{
+		Client:    testClient,
+		EC2Client: ec2Client,
+		IAMClient: &testIAMClient{t: t, principal: userARN},
+		NewSTSClient: func(_ context.Context, _, _ string) (aws.STSClient, error) {
+			return &testSTSClient{arn: userARN}, nil
+		},
+	}
+
+	for _, step := range []struct {
+		name string
+		// change is made before the check
+		change        func()
+		expectedCalls int
+	}{
+		{
+			name:          "first check",
+			expectedCalls: 1,
+		},
+		{
+			name:          "unchanged inputs",
+			expectedCalls: 1,
+		},
+		{
+			name: "rotated credentials",
+			change: func() {
+				secret.Data[awsSecretAccessKeyKey] = []byte("rotated")
+				if err := testClient.Update(ctx, secret); err != nil {
+					t.Fatalf("failed to update secret: %v", err)
+				}
+			},
+			expectedCalls: 2,
+		},
+		{
+			name:          "new generation",
+			change:        func() { controller.Generation++ },
+			expectedCalls: 3,
+		},
+		{
+			name:          "recheck interval elapsed",
+			change:        func() { r.credentialsCheck.checkedAt = time.Now().Add(-credentialsRecheckInterval) },
+			expectedCalls: 4,
+		},
+		{
+			name: "failed check is not cached",
+			change: func() {
+				controller.Generation++
+				ec2Client.createTagsCode = "InternalError"
+			},
+			expectedCalls: 5,
+		},
+		{
+			name:          "failed check is retried",
+			expectedCalls: 6,
+		},
+	} {
+		if step.change != nil {
+			step.change()
+		}
+		if _, err := r.cachedCheckCredentials(ctx, controller, credentialsRequest, true); err != nil {
+			t.Fatalf("%s: unexpected error: %v", step.name, err)
+		}
+		if ec2Client.createTagsCalls != step.expectedCalls {
+			t.Errorf("%s: expected %d checks, got %d", step.name, step.expectedCalls, ec2Client.createTagsCalls)
+		}
+	}
+}
+
+func TestClusterSubnetChanged(t *testing.T) {
+	withSubnets := func(subnets *albo.AWSLoadBalancerControllerStatusSubnets) *albo.AWSLoadBalancerController {
+		return &albo.AWSLoadBalancerController{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+			Status:     albo.AWSLoadBalancerControllerStatus{Subnets: subnets},
+		}
+	}
+	for _, tc := range []struct {
+		name     string
+		old      *albo.AWSLoadBalancerController
+		new      *albo.AWSLoadBalancerController
+		expected bool
+	}{
+		{
+			name:     "subnets found",
+			old:      withSubnets(nil),
+			new:      withSubnets(&albo.AWSLoadBalancerControllerStatusSubnets{Tagged: []string{"subnet-1"}}),
+			expected: true,
+		},
+		{
+			name:     "subnet changed",
+			old:      withSubnets(&albo.AWSLoadBalancerControllerStatusSubnets{Tagged: []string{"subnet-1"}}),
+			new:      withSubnets(&albo.AWSLoadBalancerControllerStatusSubnets{Tagged: []string{"subnet-2"}}),
+			expected: true,
+		},
+		{
+			name: "other status changed",
+			old:  withSubnets(&albo.AWSLoadBalancerControllerStatusSubnets{Tagged: []string{"subnet-1"}}),
+			new: func() *albo.AWSLoadBalancerController {
+				controller := withSubnets(&albo.AWSLoadBalancerControllerStatusSubnets{Tagged: []string{"subnet-1"}})
+				controller.Status.IngressClass = "alb"
+				return controller
+			}(),
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if changed := clusterSubnetChanged().Update(event.UpdateEvent{ObjectOld: tc.old, ObjectNew: tc.new}); changed != tc.expected {
+				t.Errorf("expected %t, got %t", tc.expected, changed)
+			}
+		})
+	}
+}
+
+func TestOperandActions(t *testing.T) {
+	actions := operandActions()
+	if len(actions) == 0 {
+		t.Fatalf("expected the actions of the operand IAM policy")
+	}
+	for i := 1; i < len(actions); i++ {
+		if actions[i-1] >= actions[i] {
+			t.Errorf("actions are not sorted and unique: %q before %q", actions[i-1], actions[i])
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package awsloadbalancercontroller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	configv1 "github.com/openshift/api/config/v1"
+)
+
+const (
+	// clusterProxyName is the name of the cluster-wide Proxy
+	clusterProxyName = "cluster"
+	// proxy environment variables of the controller
+	httpProxyEnvVarName  = "HTTP_PROXY"
+	httpsProxyEnvVarName = "HTTPS_PROXY"
+	noProxyEnvVarName    = "NO_PROXY"
+)
+
+// currentClusterProxy returns the status of the cluster-wide Proxy. If the Proxy doesn't exist an empty status is returned.
+func (r *AWSLoadBalancerControllerReconciler) currentClusterProxy(ctx context.Context) (*configv1.ProxyStatus, error) {
+	var proxy configv1.Proxy
+	err := r.Get(ctx, types.NamespacedName{Name: clusterProxyName}, &proxy)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return &configv1.ProxyStatus{}, nil
+		}
+		return nil, fmt.Errorf("failed to get Proxy %q: %w", clusterProxyName, err)
+	}
+	return &proxy.Status, nil
+}
+
+// desiredProxyEnvVars returns the environment variables which configure the controller to use the cluster-wide proxy.
+// Only the variables which have values in the Proxy status are returned.
+func desiredProxyEnvVars(proxy *configv1.ProxyStatus) []corev1.EnvVar {
+	var envs []corev1.EnvVar
+	if proxy == nil {
+		return envs
+	}
+	if proxy.HTTPProxy != "" {
+		envs = append(envs, corev1.EnvVar{Name: httpProxyEnvVarName, Value: proxy.HTTPProxy})
+	}
+	if proxy.HTTPSProxy != "" {
+		envs = append(envs, corev1.EnvVar{Name: httpsProxyEnvVarName, Value: proxy.HTTPSProxy})
+	}
+	if proxy.NoProxy != "" {
+		envs = append(envs, corev1.EnvVar{Name: noProxyEnvVarName, Value: proxy.NoProxy})
+	}
+	return envs
+}
@@ -136,9 +136,9 @@ func TestEnsureClusterRolesAndBinding(t *testing.T) {
 
 	for _, tc := range testcases {
 		t.Run(tc.name, func(t *testing.T) {
-			cl := fake.NewClientBuilder().WithScheme(test.Scheme).
+			cl := test.NewApplyClient(fake.NewClientBuilder().WithScheme(test.Scheme).
 				WithRuntimeObjects(tc.existingObjects...).
-				Build()
+				Build())
 
 			r := &AWSLoadBalancerControllerReconciler{
 				Client:    cl,
@@ -189,7 +189,10 @@ func testPreExistingClusterRole() *rbacv1.ClusterRole {
 }
 
 func testPreExistingRole() *rbacv1.Role {
-	return buildRole(testResourceName, test.OperatorNamespace, getLeaderElectionRules())
+	role := buildRole(testResourceName, test.OperatorNamespace, getLeaderElectionRules())
+	role.Labels = map[string]string{managedByLabelKey: managedByLabelValue}
+	role.OwnerReferences = testControllerReferences(controllerName)
+	return role
 }
 
 func testOutDatedPreExistingRole() *rbacv1.Role {
@@ -3,17 +3,15 @@ package awsloadbalancercontroller
 import (
 	"context"
 	"fmt"
-	"sort"
-	"strings"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/equality"
-	"k8s.io/apimachinery/pkg/api/errors"
+	discoveryv1 "k8s.io/api/discovery/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/intstr"
 
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	"github.com/openshift/aws-load-balancer-operator/api/v1alpha1"
@@ -24,10 +22,7 @@ const (
 )
 
 func (r *AWSLoadBalancerControllerReconciler) ensureService(ctx context.Context, namespace string, controller *v1alpha1.AWSLoadBalancerController, servingSecretName string, deployment *appsv1.Deployment) (*corev1.Service, error) {
-	serviceName := types.NamespacedName{
-		Name:      fmt.Sprintf("aws-load-balancer-controller-%s", controller.Name),
-		Namespace: namespace,
-	}
+	serviceName := controllerServiceName(namespace, controller)
 
 	desired := desiredService(serviceName.Name, serviceName.Namespace, servingSecretName, deployment.Spec.Selector.MatchLabels)
 	err := controllerutil.SetControllerReference(controller, desired, r.Scheme)
@@ -35,25 +30,41 @@ func (r *AWSLoadBalancerControllerReconciler) ensureService(ctx context.Context,
 		return nil, fmt.Errorf("failed to set owner reference on desired service: %w", err)
 	}
 
-	var service corev1.Service
-	err = r.Get(ctx, serviceName, &service)
-	if err != nil && !errors.IsNotFound(err) {
-		return nil, fmt.Errorf("failed to get existing service %q: %w", serviceName, err)
+	if err := r.apply(ctx, desired); err != nil {
+		return nil, fmt.Errorf("failed to apply service %q: %w", serviceName, err)
 	}
-	if err != nil && errors.IsNotFound(err) {
-		return desired, r.Create(ctx, desired)
+	return desired, nil
+}
+
+// controllerServiceName returns the name of the service of the webhooks and the metrics.
+func controllerServiceName(namespace string, controller *v1alpha1.AWSLoadBalancerController) types.NamespacedName {
+	return types.NamespacedName{
+		Name:      fmt.Sprintf("aws-load-balancer-controller-%s", controller.Name),
+		Namespace: namespace,
 	}
-	return r.updateService(ctx, &service, desired)
 }
 
+// desiredService returns the service of the webhooks and the metrics. The service CA operator issues the serving
+// certificate in the serving secret of the annotation, the annotation is omitted when the serving secret name is empty
+// as the certificate is issued by another provider.
 func desiredService(name, namespace string, servingSecretName string, selector map[string]string) *corev1.Service {
+	labels := make(map[string]string, len(selector))
+	for k, v := range selector {
+		labels[k] = v
+	}
+	var annotations map[string]string
+	if servingSecretName != "" {
+		annotations = map[string]string{
+			servingSecretAnnotationName: servingSecretName,
+		}
+	}
 	return &corev1.Service{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name,
 			Namespace: namespace,
-			Annotations: map[string]string{
-				servingSecretAnnotationName: servingSecretName,
-			},
+			// the labels of the pods select the service in the ServiceMonitor
+			Labels:      labels,
+			Annotations: annotations,
 		},
 		Spec: corev1.ServiceSpec{
 			Ports: []corev1.ServicePort{
@@ -64,8 +75,8 @@ func desiredService(name, namespace string, servingSecretName string, selector m
 				},
 				{
 					Name:       "metrics",
-					Port:       controllerMetricsPort,
-					TargetPort: intstr.FromInt(controllerMetricsPort),
+					Port:       metricsProxyPort,
+					TargetPort: intstr.FromInt(metricsProxyPort),
 				},
 			},
 			Selector: selector,
@@ -74,71 +85,20 @@ func desiredService(name, namespace string, servingSecretName string, selector m
 	}
 }
 
-func (r *AWSLoadBalancerControllerReconciler) updateService(ctx context.Context, current, desired *corev1.Service) (*corev1.Service, error) {
-	updatedService := current.DeepCopy()
-	var updated bool
-
-	if !portsMatch(updatedService.Spec.Ports, desired.Spec.Ports) {
-		updatedService.Spec.Ports = desired.Spec.Ports
-		updated = true
-	}
-	if !equality.Semantic.DeepEqual(updatedService.Spec.Selector, desired.Spec.Selector) {
-		updatedService.Spec.Selector = desired.Spec.Selector
-		updated = true
-	}
-
-	if updatedService.Spec.Type != desired.Spec.Type {
-		updatedService.Spec.Type = desired.Spec.Type
-		updated = true
-	}
-
-	if updatedService.Annotations == nil {
-		updatedService.Annotations = make(map[string]string)
-	}
-	for annotationKey, annotationValue := range desired.Annotations {
-		if currentAnnotationValue, ok := updatedService.Annotations[annotationKey]; !ok || currentAnnotationValue != annotationValue {
-			updatedService.Annotations[annotationKey] = annotationValue
-			updated = true
-		}
-	}
-
-	if updated {
-		return updatedService, r.Update(ctx, updatedService)
-	}
-	return updatedService, nil
-}
-
-type SortableServicePort []corev1.ServicePort
-
-func (s SortableServicePort) Len() int {
-	return len(s)
-}
-
-func (s SortableServicePort) Less(i, j int) bool {
-	return strings.Compare(s[i].Name, s[j].Name) < 0
-}
-
-func (s SortableServicePort) Swap(i, j int) {
-	s[i], s[j] = s[j], s[i]
-}
-
-func portsMatch(current, desired SortableServicePort) bool {
-	if len(current) != len(desired) {
-		return false
+// readyServiceEndpoints returns the number of ready endpoints of the given service. The endpoints are read from the
+// EndpointSlices of the service, an endpoint whose readiness is unknown is considered ready.
+func (r *AWSLoadBalancerControllerReconciler) readyServiceEndpoints(ctx context.Context, service *corev1.Service) (int, error) {
+	var slices discoveryv1.EndpointSliceList
+	if err := r.List(ctx, &slices, client.InNamespace(service.Namespace), client.MatchingLabels{discoveryv1.LabelServiceName: service.Name}); err != nil {
+		return 0, fmt.Errorf("failed to list endpoint slices of service %s/%s: %w", service.Namespace, service.Name, err)
 	}
-	currentCopy := make(SortableServicePort, len(current))
-	copy(currentCopy, current)
-	sort.Sort(currentCopy)
-	desiredCopy := make(SortableServicePort, len(desired))
-	copy(desiredCopy, desired)
-	sort.Sort(desiredCopy)
-
-	for i := 0; i < len(currentCopy); i++ {
-		c := currentCopy[i]
-		d := desiredCopy[i]
-		if c.Name != d.Name || c.Port != d.Port || c.TargetPort.IntVal != d.TargetPort.IntVal {
-			return false
+	var ready int
+	for _, slice := range slices.Items {
+		for _, endpoint := range slice.Endpoints {
+			if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
+				ready++
+			}
 		}
 	}
-	return true
+	return ready, nil
 }
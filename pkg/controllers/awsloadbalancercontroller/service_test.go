@@ -36,8 +36,8 @@ func testControllerService(name, namespace string, selector, annotations map[str
 				},
 				{
 					Name:       "metrics",
-					Port:       controllerMetricsPort,
-					TargetPort: intstr.FromInt(controllerMetricsPort),
+					Port:       metricsProxyPort,
+					TargetPort: intstr.FromInt(metricsProxyPort),
 				},
 			},
 			Selector: selector,
@@ -49,9 +49,13 @@ func TestEnsureService(t *testing.T) {
 	for _, tc := range []struct {
 		name            string
 		existingObjects []client.Object
-		controller      *v1alpha1.AWSLoadBalancerController
-		deployment      *appsv1.Deployment
-		expectedService *corev1.Service
+		// appliedObjects are applied by the operator before the service is ensured
+		appliedObjects []client.Object
+		// otherCertificatesProvider is set when the serving certificate is not issued by the service CA
+		otherCertificatesProvider bool
+		controller                *v1alpha1.AWSLoadBalancerController
+		deployment                *appsv1.Deployment
+		expectedService           *corev1.Service
 	}{
 		{
 			name: "new service",
@@ -110,7 +114,7 @@ func TestEnsureService(t *testing.T) {
 					Name: "test",
 				},
 			},
-			existingObjects: []client.Object{
+			appliedObjects: []client.Object{
 				&corev1.Service{
 					ObjectMeta: metav1.ObjectMeta{Name: "aws-load-balancer-controller-test", Namespace: "test-namespace"},
 					Spec: corev1.ServiceSpec{
@@ -168,8 +172,8 @@ func TestEnsureService(t *testing.T) {
 							},
 							{
 								Name:       "metrics",
-								Port:       controllerMetricsPort,
-								TargetPort: intstr.FromInt(controllerMetricsPort),
+								Port:       metricsProxyPort,
+								TargetPort: intstr.FromInt(metricsProxyPort),
 							},
 						},
 						Selector: map[string]string{"app": "controller"},
@@ -222,14 +226,53 @@ func TestEnsureService(t *testing.T) {
 				},
 			),
 		},
+		{
+			name: "existing service, serving certificate not issued by the service CA",
+			controller: &v1alpha1.AWSLoadBalancerController{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+			},
+			appliedObjects: []client.Object{
+				testControllerService(
+					"aws-load-balancer-controller-test",
+					"test-namespace",
+					map[string]string{"app": "controller"},
+					map[string]string{servingSecretAnnotationName: "serving-secret"},
+				),
+			},
+			otherCertificatesProvider: true,
+			deployment: &appsv1.Deployment{
+				Spec: appsv1.DeploymentSpec{
+					Selector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{"app": "controller"},
+					},
+				},
+			},
+			expectedService: testControllerService(
+				"aws-load-balancer-controller-test",
+				"test-namespace",
+				map[string]string{"app": "controller"},
+				nil,
+			),
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
-			testClient := fake.NewClientBuilder().WithObjects(tc.existingObjects...).WithScheme(test.Scheme).Build()
+			testClient := test.NewApplyClient(fake.NewClientBuilder().WithObjects(tc.existingObjects...).WithScheme(test.Scheme).Build())
 			r := &AWSLoadBalancerControllerReconciler{
 				Client: testClient,
 				Scheme: test.Scheme,
 			}
-			_, err := r.ensureService(context.Background(), "test-namespace", tc.controller, "serving-secret", tc.deployment)
+			for _, obj := range tc.appliedObjects {
+				if err := r.apply(context.Background(), obj); err != nil {
+					t.Fatalf("failed to apply %s: %v", obj.GetName(), err)
+				}
+			}
+			servingSecretName := "serving-secret"
+			if tc.otherCertificatesProvider {
+				servingSecretName = ""
+			}
+			_, err := r.ensureService(context.Background(), "test-namespace", tc.controller, servingSecretName, tc.deployment)
 			if err != nil {
 				t.Errorf("unexpected error: %v", err)
 				return
@@ -245,6 +288,13 @@ func TestEnsureService(t *testing.T) {
 				t.Errorf("unexpected annotations\n%s", diff)
 			}
 
+			// the service is selected by its pod labels in the ServiceMonitor
+			for k, v := range tc.deployment.Spec.Selector.MatchLabels {
+				if s.Labels[k] != v {
+					t.Errorf("expected service label %s=%s, got labels %v", k, v, s.Labels)
+				}
+			}
+
 			if !equality.Semantic.DeepEqual(s.Spec, tc.expectedService.Spec) {
 				t.Errorf("service has unexpected configuration:\n%s", cmp.Diff(s.Spec, tc.expectedService.Spec))
 			}
@@ -99,9 +99,9 @@ func TestEnsureServiceAccount(t *testing.T) {
 
 	for _, tc := range testcases {
 		t.Run(tc.name, func(t *testing.T) {
-			cl := fake.NewClientBuilder().WithScheme(test.Scheme).
+			cl := test.NewApplyClient(fake.NewClientBuilder().WithScheme(test.Scheme).
 				WithRuntimeObjects(tc.existingObjects...).
-				Build()
+				Build())
 
 			r := &AWSLoadBalancerControllerReconciler{
 				Client:    cl,
@@ -146,8 +146,10 @@ func TestEnsureServiceAccount(t *testing.T) {
 func testServiceAccount() *corev1.ServiceAccount {
 	return &corev1.ServiceAccount{
 		ObjectMeta: v1.ObjectMeta{
-			Name:      "aws-load-balancer-controller-cluster",
-			Namespace: test.OperatorNamespace,
+			Name:            "aws-load-balancer-controller-cluster",
+			Namespace:       test.OperatorNamespace,
+			Labels:          map[string]string{managedByLabelKey: managedByLabelValue},
+			OwnerReferences: testControllerReferences(controllerName),
 		},
 		AutomountServiceAccountToken: pointer.Bool(true),
 	}
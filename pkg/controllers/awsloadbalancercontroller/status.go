@@ -6,11 +6,15 @@ import (
 	"sort"
 
 	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
-	cco "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	albo "github.com/openshift/aws-load-balancer-operator/api/v1alpha1"
 )
@@ -21,24 +25,137 @@ const (
 	CredentialsSecretAvailableCondition = "CredentialsSecretAvailable"
 )
 
-func (r *AWSLoadBalancerControllerReconciler) updateControllerStatus(ctx context.Context, controller *albo.AWSLoadBalancerController, deployment *appsv1.Deployment, cr *cco.CredentialsRequest, secretProvisioned bool) error {
-	status := controller.Status.DeepCopy()
+// controllerStatus is the part of the status computed by a reconcile of a sub-controller. It's written once at the
+// end of the reconcile by writeStatus.
+type controllerStatus struct {
+	// conditions are merged into the conditions of the status
+	conditions []metav1.Condition
+	// subnets replace the subnets of the status when set
+	subnets *albo.AWSLoadBalancerControllerStatusSubnets
+	// ingressClass replaces the IngressClass of the status when set
+	ingressClass *string
+	// deployment replaces the effective configuration of the deployment of the status when set
+	deployment *albo.AWSLoadBalancerControllerStatusDeployment
+}
+
+// addConditions adds the given conditions to the computed status.
+func (s *controllerStatus) addConditions(conditions ...metav1.Condition) {
+	s.conditions = append(s.conditions, conditions...)
+}
 
-	if cr != nil {
-		status.Conditions = mergeConditions(status.Conditions, credentialRequestsConditions(cr.Spec.SecretRef.Name, secretProvisioned, controller.Generation)...)
+// setSubnets sets the subnets processed with the given tagging policy in the given VPC and the resource tags added
+// to the tagged subnets.
+func (s *controllerStatus) setSubnets(internal, public, untagged, tagged []string, policy albo.SubnetTaggingPolicy, vpcID string, resourceTags map[string]string) {
+	s.subnets = &albo.AWSLoadBalancerControllerStatusSubnets{
+		SubnetTagging:    policy,
+		VPCID:            vpcID,
+		Internal:         internal,
+		Public:           public,
+		Untagged:         untagged,
+		Tagged:           tagged,
+		ResourceTagKeys:  subnetResourceTagKeys(resourceTags),
+		ResourceTagsHash: subnetResourceTagsHash(resourceTags),
 	}
+}
+
+// setIngressClass sets the IngressClass handled by the controller.
+func (s *controllerStatus) setIngressClass(ingressClass string) {
+	s.ingressClass = &ingressClass
+}
+
+// setDeployment sets the effective configuration of the deployment of the operand.
+func (s *controllerStatus) setDeployment(deployment *albo.AWSLoadBalancerControllerStatusDeployment) {
+	s.deployment = deployment
+}
 
-	if deployment != nil {
-		status.Conditions = mergeConditions(status.Conditions, deploymentConditions(deployment, controller.Generation)...)
+// applyTo applies the computed status on the given status. The ObservedGeneration of the status is the oldest
+// generation observed by the conditions: all the sub-controllers have processed at least this generation.
+func (s *controllerStatus) applyTo(status *albo.AWSLoadBalancerControllerStatus) {
+	status.Conditions = mergeConditions(status.Conditions, s.conditions...)
+	status.ObservedGeneration = observedGeneration(status.Conditions)
+	if s.subnets != nil {
+		if status.Subnets == nil {
+			status.Subnets = &albo.AWSLoadBalancerControllerStatusSubnets{}
+		}
+		status.Subnets.SubnetTagging = s.subnets.SubnetTagging
+		status.Subnets.VPCID = s.subnets.VPCID
+		if !equalStrings(status.Subnets.Internal, s.subnets.Internal) {
+			status.Subnets.Internal = s.subnets.Internal
+		}
+		if !equalStrings(status.Subnets.Public, s.subnets.Public) {
+			status.Subnets.Public = s.subnets.Public
+		}
+		if !equalStrings(status.Subnets.Tagged, s.subnets.Tagged) {
+			status.Subnets.Tagged = s.subnets.Tagged
+		}
+		if !equalStrings(status.Subnets.Untagged, s.subnets.Untagged) {
+			status.Subnets.Untagged = s.subnets.Untagged
+		}
+		if !equalStrings(status.Subnets.ResourceTagKeys, s.subnets.ResourceTagKeys) {
+			status.Subnets.ResourceTagKeys = s.subnets.ResourceTagKeys
+		}
+		status.Subnets.ResourceTagsHash = s.subnets.ResourceTagsHash
+	}
+	if s.ingressClass != nil {
+		status.IngressClass = *s.ingressClass
+	}
+	if s.deployment != nil {
+		status.Deployment = s.deployment
 	}
+}
 
-	if haveConditionsChanged(controller.Status.Conditions, status.Conditions) {
-		controller.Status.Conditions = status.Conditions
-		return r.Status().Update(ctx, controller)
+// writeStatus writes the status computed by a reconcile of the given sub-controller with a merge patch, the
+// conditions are reported in the metrics of the sub-controller. The sub-controllers write their own parts of the
+// status concurrently: the patch is made with an optimistic lock and, on conflicts, the computed status is applied
+// again on the latest resource. The status is not written if it doesn't change. The given controller is updated
+// with the written resource.
+func (r *AWSLoadBalancerControllerReconciler) writeStatus(ctx context.Context, controllerName string, controller *albo.AWSLoadBalancerController, status *controllerStatus) error {
+	recordConditions(controllerName, status.conditions...)
+	current := controller.DeepCopy()
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		updated := current.DeepCopy()
+		status.applyTo(&updated.Status)
+		if !hasStatusChanged(current.Status, updated.Status) {
+			return nil
+		}
+		if err := r.Status().Patch(ctx, updated, client.MergeFromWithOptions(current, client.MergeFromWithOptimisticLock{})); err != nil {
+			if errors.IsConflict(err) {
+				// the cache may not have the latest resource yet
+				if err := r.apiReader().Get(ctx, client.ObjectKeyFromObject(current), current); err != nil {
+					return fmt.Errorf("failed to get AWSLoadBalancerController %q: %w", current.Name, err)
+				}
+			}
+			return err
+		}
+		updated.DeepCopyInto(controller)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update status of AWSLoadBalancerController %q: %w", controller.Name, err)
 	}
 	return nil
 }
 
+// hasStatusChanged returns true if the statuses differ, the order of the conditions is not considered.
+func hasStatusChanged(current, updated albo.AWSLoadBalancerControllerStatus) bool {
+	if haveConditionsChanged(current.Conditions, updated.Conditions) {
+		return true
+	}
+	current.Conditions, updated.Conditions = nil, nil
+	return !equality.Semantic.DeepEqual(current, updated)
+}
+
+// observedGeneration returns the oldest generation observed by the given conditions.
+func observedGeneration(conditions []metav1.Condition) int64 {
+	var generation int64
+	for i, cond := range conditions {
+		if i == 0 || cond.ObservedGeneration < generation {
+			generation = cond.ObservedGeneration
+		}
+	}
+	return generation
+}
+
 func credentialRequestsConditions(secretName string, secretProvisioned bool, generation int64) []metav1.Condition {
 	var conditions []metav1.Condition
 	if secretProvisioned {
@@ -61,7 +178,9 @@ func credentialRequestsConditions(secretName string, secretProvisioned bool, gen
 	return conditions
 }
 
-func deploymentConditions(deployment *appsv1.Deployment, generation int64) []metav1.Condition {
+// deploymentConditions returns the conditions of the deployment of the operand. The deployment is only available
+// once the webhook service has ready endpoints, otherwise the admission of the ingresses and services fails.
+func deploymentConditions(deployment *appsv1.Deployment, webhookService *corev1.Service, readyEndpoints int, generation int64) []metav1.Condition {
 	var conditions []metav1.Condition
 
 	var replicas int32 = 1
@@ -69,21 +188,30 @@ func deploymentConditions(deployment *appsv1.Deployment, generation int64) []met
 		replicas = *deployment.Spec.Replicas
 	}
 
-	if deployment.Status.AvailableReplicas == replicas {
+	switch {
+	case deployment.Status.AvailableReplicas != replicas:
 		conditions = append(conditions, metav1.Condition{
 			Type:               DeploymentAvailableCondition,
-			Status:             metav1.ConditionTrue,
+			Status:             metav1.ConditionFalse,
 			ObservedGeneration: generation,
-			Reason:             "AllDeploymentReplicasAvailable",
-			Message:            fmt.Sprintf("Number of desired and available replicas of deployment %q are equal", deployment.Name),
+			Reason:             "AllDeploymentReplicasNotAvailable",
+			Message:            fmt.Sprintf("Number of desired and available replicas of deployment %q are not equal", deployment.Name),
 		})
-	} else {
+	case readyEndpoints == 0:
 		conditions = append(conditions, metav1.Condition{
 			Type:               DeploymentAvailableCondition,
 			Status:             metav1.ConditionFalse,
 			ObservedGeneration: generation,
-			Reason:             "AllDeploymentReplicasNotAvailable",
-			Message:            fmt.Sprintf("Number of desired and available replicas of deployment %q are not equal", deployment.Name),
+			Reason:             "WebhookEndpointsNotReady",
+			Message:            fmt.Sprintf("Service %q of the webhook has no ready endpoints", webhookService.Name),
+		})
+	default:
+		conditions = append(conditions, metav1.Condition{
+			Type:               DeploymentAvailableCondition,
+			Status:             metav1.ConditionTrue,
+			ObservedGeneration: generation,
+			Reason:             "AllDeploymentReplicasAvailable",
+			Message:            fmt.Sprintf("Number of desired and available replicas of deployment %q are equal", deployment.Name),
 		})
 	}
 
@@ -111,6 +239,7 @@ func deploymentConditions(deployment *appsv1.Deployment, generation int64) []met
 // mergeConditions updates the conditions list with new conditions.
 // Each condition is added if no condition of the same type already exists.
 // Otherwise, the condition is merged with the existing condition of the same type.
+// The transition time of the existing condition is kept if its status, reason and message don't change.
 func mergeConditions(conditions []metav1.Condition, updates ...metav1.Condition) []metav1.Condition {
 	now := metav1.Now()
 	for i, update := range updates {
@@ -121,8 +250,11 @@ func mergeConditions(conditions []metav1.Condition, updates ...metav1.Condition)
 				if conditionChanged(cond, update) {
 					conditions[j] = update
 					conditions[j].LastTransitionTime = now
-					break
+				} else {
+					// the transition time is kept, the condition is only observed for a newer generation
+					conditions[j].ObservedGeneration = update.ObservedGeneration
 				}
+				break
 			}
 		}
 		if add {
@@ -145,43 +277,6 @@ func haveConditionsChanged(current []metav1.Condition, desired []metav1.Conditio
 	return !cmp.Equal(current, desired, opts)
 }
 
-func (r *AWSLoadBalancerControllerReconciler) updateStatusSubnets(ctx context.Context, controller *albo.AWSLoadBalancerController, internal []string, public []string, untagged []string, tagged []string, policy albo.SubnetTaggingPolicy) error {
-	updatedALBC := controller.DeepCopy()
-	var updated bool
-
-	if updatedALBC.Status.Subnets == nil {
-		updatedALBC.Status.Subnets = &albo.AWSLoadBalancerControllerStatusSubnets{}
-		updated = true
-	}
-
-	if updatedALBC.Status.Subnets.SubnetTagging != policy {
-		updatedALBC.Status.Subnets.SubnetTagging = policy
-		updated = true
-	}
-
-	if !equalStrings(updatedALBC.Status.Subnets.Internal, internal) {
-		updatedALBC.Status.Subnets.Internal = internal
-		updated = true
-	}
-	if !equalStrings(updatedALBC.Status.Subnets.Public, public) {
-		updatedALBC.Status.Subnets.Public = public
-		updated = true
-	}
-	if !equalStrings(updatedALBC.Status.Subnets.Tagged, tagged) {
-		updatedALBC.Status.Subnets.Tagged = tagged
-		updated = true
-	}
-	if !equalStrings(updatedALBC.Status.Subnets.Untagged, untagged) {
-		updatedALBC.Status.Subnets.Untagged = untagged
-		updated = true
-	}
-
-	if updated {
-		return r.Status().Update(ctx, updatedALBC)
-	}
-	return nil
-}
-
 func equalStrings(x1, x2 []string) bool {
 	if len(x1) != len(x2) {
 		return false
@@ -195,13 +290,3 @@ func equalStrings(x1, x2 []string) bool {
 	sort.Strings(x2c)
 	return cmp.Equal(x1c, x2c)
 }
-
-func (r *AWSLoadBalancerControllerReconciler) updateStatusIngressClass(ctx context.Context, controller *albo.AWSLoadBalancerController, ingressClass string) error {
-	if controller.Status.IngressClass == ingressClass {
-		return nil
-	}
-
-	updated := controller.DeepCopy()
-	updated.Status.IngressClass = ingressClass
-	return r.Status().Update(ctx, updated)
-}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"strings"
 	"testing"
+	"time"
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/pointer"
 
@@ -45,11 +47,13 @@ func TestUpdateIngressClassStatus(t *testing.T) {
 			r := &AWSLoadBalancerControllerReconciler{
 				Client: fake.NewClientBuilder().WithScheme(test.Scheme).WithObjects(tc.controller).Build(),
 			}
-			err := r.updateStatusIngressClass(context.Background(), tc.controller, tc.inputIngressClass)
+			status := &controllerStatus{}
+			status.setIngressClass(tc.inputIngressClass)
+			err := r.writeStatus(context.Background(), ingressClassControllerName, tc.controller, status)
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
-			controller, _, err := r.getAWSLoadBalancerController(context.Background(), tc.controller.Name)
+			controller, err := r.reconciledController(context.Background(), r.Client, tc.controller.Name)
 			if err != nil {
 				t.Fatalf("failed to get controller %q: %v", tc.controller.Name, err)
 			}
@@ -60,6 +64,46 @@ func TestUpdateIngressClassStatus(t *testing.T) {
 	}
 }
 
+func TestUpdateStatusConflict(t *testing.T) {
+	controller := &albo.AWSLoadBalancerController{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+	}
+	r := &AWSLoadBalancerControllerReconciler{
+		Client: fake.NewClientBuilder().WithScheme(test.Scheme).WithObjects(controller).Build(),
+	}
+	stale, err := r.reconciledController(context.Background(), r.Client, controller.Name)
+	if err != nil {
+		t.Fatalf("failed to get controller %q: %v", controller.Name, err)
+	}
+
+	// another sub-controller writes its part of the status
+	ingressClass := &controllerStatus{}
+	ingressClass.setIngressClass("alb")
+	if err := r.writeStatus(context.Background(), ingressClassControllerName, controller, ingressClass); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// the patch of the stale resource is retried on the latest status
+	conditions := &controllerStatus{}
+	conditions.addConditions(ingressClassAvailableCondition("alb", nil, 1))
+	if err := r.writeStatus(context.Background(), ingressClassControllerName, stale, conditions); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	updated, err := r.reconciledController(context.Background(), r.Client, controller.Name)
+	if err != nil {
+		t.Fatalf("failed to get controller %q: %v", controller.Name, err)
+	}
+	if updated.Status.IngressClass != "alb" {
+		t.Errorf("expected ingress class %q in status, got %q", "alb", updated.Status.IngressClass)
+	}
+	if len(updated.Status.Conditions) != 1 || updated.Status.Conditions[0].Type != IngressClassAvailableCondition {
+		t.Errorf("expected %s condition in status, got %v", IngressClassAvailableCondition, updated.Status.Conditions)
+	}
+	if stale.ResourceVersion != updated.ResourceVersion {
+		t.Errorf("expected the updated resource version %q, got %q", updated.ResourceVersion, stale.ResourceVersion)
+	}
+}
+
 func TestUpdateSubnets(t *testing.T) {
 	for _, tc := range []struct {
 		name                               string
@@ -122,11 +166,13 @@ func TestUpdateSubnets(t *testing.T) {
 			r := &AWSLoadBalancerControllerReconciler{
 				Client: fake.NewClientBuilder().WithScheme(test.Scheme).WithObjects(tc.controller).Build(),
 			}
-			err := r.updateStatusSubnets(context.Background(), tc.controller, tc.internal, tc.public, tc.untagged, tc.tagged, tc.taggingPolicy)
+			status := &controllerStatus{}
+			status.setSubnets(tc.internal, tc.public, tc.untagged, tc.tagged, tc.taggingPolicy, "test-vpc", nil)
+			err := r.writeStatus(context.Background(), subnetTaggingControllerName, tc.controller, status)
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
-			controller, _, err := r.getAWSLoadBalancerController(context.Background(), tc.controller.Name)
+			controller, err := r.reconciledController(context.Background(), r.Client, tc.controller.Name)
 			if err != nil {
 				t.Fatalf("failed to get controller %q: %v", tc.controller.Name, err)
 			}
@@ -145,6 +191,9 @@ func TestUpdateSubnets(t *testing.T) {
 			if tc.taggingPolicy != controller.Status.Subnets.SubnetTagging {
 				t.Errorf("unexpected tagging policy, expected %q, got %q", tc.taggingPolicy, controller.Status.Subnets.SubnetTagging)
 			}
+			if controller.Status.Subnets.VPCID != "test-vpc" {
+				t.Errorf("unexpected VPC, expected %q, got %q", "test-vpc", controller.Status.Subnets.VPCID)
+			}
 		})
 	}
 }
@@ -163,7 +212,9 @@ func TestUpdateStatus(t *testing.T) {
 		deployment         *appsv1.Deployment
 		credentialsRequest *cco.CredentialsRequest
 		secretProvisioned  bool
-		conditions         []metav1.Condition
+		// webhookNotReady is true if the webhook service has no ready endpoints
+		webhookNotReady bool
+		conditions      []metav1.Condition
 	}{
 		{
 			name: "deployment and credentials secret available and up-to-date",
@@ -300,6 +351,52 @@ func TestUpdateStatus(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "webhook service has no ready endpoints",
+			deployment: &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Spec:       appsv1.DeploymentSpec{Replicas: pointer.Int32(2)},
+				Status:     appsv1.DeploymentStatus{AvailableReplicas: 2, UpdatedReplicas: 2},
+			},
+			credentialsRequest: &cco.CredentialsRequest{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Generation: 1},
+				Spec: cco.CredentialsRequestSpec{
+					SecretRef: corev1.ObjectReference{
+						Name:      "test",
+						Namespace: test.OperatorNamespace,
+					},
+				},
+				Status: cco.CredentialsRequestStatus{LastSyncGeneration: 1, Provisioned: true},
+			},
+			secretProvisioned: true,
+			webhookNotReady:   true,
+			controller: &albo.AWSLoadBalancerController{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Generation: 5},
+			},
+			conditions: []metav1.Condition{
+				{
+					Type:               CredentialsSecretAvailableCondition,
+					Status:             metav1.ConditionTrue,
+					Reason:             "CredentialsSecretsProvisioned",
+					Message:            `CredentialsSecret "test" has been provisioned`,
+					ObservedGeneration: 5,
+				},
+				{
+					Type:               DeploymentAvailableCondition,
+					Reason:             "WebhookEndpointsNotReady",
+					Message:            `Service "test" of the webhook has no ready endpoints`,
+					ObservedGeneration: 5,
+					Status:             metav1.ConditionFalse,
+				},
+				{
+					Type:               DeploymentUpgradingCondition,
+					Reason:             "AllDeploymentReplicasUpdated",
+					Message:            `Number of desired and updated replicas of deployment "test" are equal`,
+					ObservedGeneration: 5,
+					Status:             metav1.ConditionFalse,
+				},
+			},
+		},
 		{
 			name: "credentials secret is not available",
 			deployment: &appsv1.Deployment{
@@ -350,11 +447,19 @@ func TestUpdateStatus(t *testing.T) {
 			r := AWSLoadBalancerControllerReconciler{
 				Client: fake.NewClientBuilder().WithScheme(test.Scheme).WithObjects(tc.controller).Build(),
 			}
-			err := r.updateControllerStatus(context.Background(), tc.controller, tc.deployment, tc.credentialsRequest, tc.secretProvisioned)
+			status := &controllerStatus{}
+			status.addConditions(credentialRequestsConditions(tc.credentialsRequest.Spec.SecretRef.Name, tc.secretProvisioned, tc.controller.Generation)...)
+			readyEndpoints := 1
+			if tc.webhookNotReady {
+				readyEndpoints = 0
+			}
+			webhookService := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "test"}}
+			status.addConditions(deploymentConditions(tc.deployment, webhookService, readyEndpoints, tc.controller.Generation)...)
+			err := r.writeStatus(context.Background(), operandControllerName, tc.controller, status)
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
-			controller, _, err := r.getAWSLoadBalancerController(context.Background(), tc.controller.Name)
+			controller, err := r.reconciledController(context.Background(), r.Client, tc.controller.Name)
 			if err != nil {
 				t.Fatalf("unexpected error: %v", err)
 			}
@@ -364,6 +469,83 @@ func TestUpdateStatus(t *testing.T) {
 			if diff := cmp.Diff(tc.conditions, controller.Status.Conditions, stompTime); diff != "" {
 				t.Errorf("expected controller status conditions are different:\n%s", diff)
 			}
+			if controller.Status.ObservedGeneration != tc.controller.Generation {
+				t.Errorf("expected observed generation %d, got %d", tc.controller.Generation, controller.Status.ObservedGeneration)
+			}
 		})
 	}
 }
+
+func TestStatusObservedGeneration(t *testing.T) {
+	transitionTime := metav1.NewTime(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	controller := &albo.AWSLoadBalancerController{
+		ObjectMeta: metav1.ObjectMeta{Name: "test", Generation: 6},
+		Status: albo.AWSLoadBalancerControllerStatus{
+			ObservedGeneration: 5,
+			IngressClass:       "alb",
+			Conditions: []metav1.Condition{
+				{
+					Type:               IngressClassAvailableCondition,
+					Status:             metav1.ConditionTrue,
+					ObservedGeneration: 5,
+					LastTransitionTime: transitionTime,
+					Reason:             "IngressClassAvailable",
+					Message:            `IngressClass "alb" is handled by the controller`,
+				},
+				{
+					Type:               CredentialsSecretAvailableCondition,
+					Status:             metav1.ConditionTrue,
+					ObservedGeneration: 5,
+					LastTransitionTime: transitionTime,
+					Reason:             "CredentialsSecretsProvisioned",
+					Message:            `CredentialsSecret "test" has been provisioned`,
+				},
+			},
+		},
+	}
+	r := &AWSLoadBalancerControllerReconciler{
+		Client: fake.NewClientBuilder().WithScheme(test.Scheme).WithObjects(controller).Build(),
+	}
+
+	// the generation is observed only once all the sub-controllers have processed it
+	for _, step := range []struct {
+		controllerName     string
+		condition          metav1.Condition
+		observedGeneration int64
+	}{
+		{
+			controllerName:     ingressClassControllerName,
+			condition:          ingressClassAvailableCondition("alb", nil, 6),
+			observedGeneration: 5,
+		},
+		{
+			controllerName:     credentialsControllerName,
+			condition:          credentialRequestsConditions("test", true, 6)[0],
+			observedGeneration: 6,
+		},
+	} {
+		status := &controllerStatus{}
+		status.addConditions(step.condition)
+		if err := r.writeStatus(context.Background(), step.controllerName, controller, status); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		updated, err := r.reconciledController(context.Background(), r.Client, controller.Name)
+		if err != nil {
+			t.Fatalf("failed to get controller %q: %v", controller.Name, err)
+		}
+		if updated.Status.ObservedGeneration != step.observedGeneration {
+			t.Errorf("expected observed generation %d after the %s controller, got %d", step.observedGeneration, step.controllerName, updated.Status.ObservedGeneration)
+		}
+		cond := meta.FindStatusCondition(updated.Status.Conditions, step.condition.Type)
+		if cond == nil {
+			t.Fatalf("expected %s condition in status, got %v", step.condition.Type, updated.Status.Conditions)
+		}
+		if cond.ObservedGeneration != 6 {
+			t.Errorf("expected %s condition observed for generation 6, got %d", cond.Type, cond.ObservedGeneration)
+		}
+		// the condition didn't change, only its generation
+		if !cond.LastTransitionTime.Equal(&transitionTime) {
+			t.Errorf("expected %s condition transition time %v to be kept, got %v", cond.Type, transitionTime, cond.LastTransitionTime)
+		}
+	}
+}
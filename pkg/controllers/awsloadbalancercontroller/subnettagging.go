@@ -3,34 +3,47 @@ package awsloadbalancercontroller
 import (
 	"context"
 	"fmt"
+	"sort"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 
-	"github.com/aws/aws-sdk-go-v2/aws"
+	awstypes "github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
 
 	albo "github.com/openshift/aws-load-balancer-operator/api/v1alpha1"
+	"github.com/openshift/aws-load-balancer-operator/pkg/aws"
 )
 
 const (
+	// SubnetsTaggedCondition reports whether the subnets of the cluster were found and tagged with the subnet tagging policy
+	SubnetsTaggedCondition = "SubnetsTagged"
+
 	clusterOwnedTagKey = "kubernetes.io/cluster/%s"
 	internalELBTagKey  = "kubernetes.io/role/internal-elb"
 	publicELBTagKey    = "kubernetes.io/role/elb"
 	tagKeyFilterName   = "tag-key"
+	vpcIDFilterName    = "vpc-id"
 	tagKeyALBOTagged   = "networking.olm.openshift.io/albo/tagged"
 )
 
 // tagSubnets will add detect the subnets of the cluster and then tag them appropriately. It then writes the detected
-// subnet IDs into the status along with their tagged roles.
-func (r *AWSLoadBalancerControllerReconciler) tagSubnets(ctx context.Context, controller *albo.AWSLoadBalancerController) (internalSubnets, publicSubnets, untaggedSubnets, taggedSubnets []string, err error) {
-	// list the subnets which are tagged as owned by the cluster
+// subnet IDs into the status along with their tagged roles. Only the subnets of the given VPC are considered. The
+// given resource tags are added to the subnets tagged by the operator, the subnets which were already tagged are
+// tagged again when the resource tags differ from the ones of the status.
+func (r *AWSLoadBalancerControllerReconciler) tagSubnets(ctx context.Context, controller *albo.AWSLoadBalancerController, vpcID string, resourceTags map[string]string) (internalSubnets, publicSubnets, untaggedSubnets, taggedSubnets []string, err error) {
+	// list the subnets of the VPC which are tagged as owned by the cluster
 	subnetsPaginator := ec2.NewDescribeSubnetsPaginator(r.EC2Client, &ec2.DescribeSubnetsInput{
 		Filters: []ec2types.Filter{
 			{
-				Name:   aws.String(tagKeyFilterName),
+				Name:   awstypes.String(tagKeyFilterName),
 				Values: []string{fmt.Sprintf(clusterOwnedTagKey, r.ClusterName)},
 			},
+			{
+				Name:   awstypes.String(vpcIDFilterName),
+				Values: []string{vpcID},
+			},
 		},
 	})
 
@@ -48,7 +61,7 @@ func (r *AWSLoadBalancerControllerReconciler) tagSubnets(ctx context.Context, co
 	}
 
 	if len(subnets) == 0 {
-		err = fmt.Errorf("no subnets with tag %s found", fmt.Sprintf(clusterOwnedTagKey, r.ClusterName))
+		err = fmt.Errorf("no subnets with tag %s found in VPC %s", fmt.Sprintf(clusterOwnedTagKey, r.ClusterName), vpcID)
 		return
 	}
 
@@ -69,25 +82,30 @@ func (r *AWSLoadBalancerControllerReconciler) tagSubnets(ctx context.Context, co
 	case albo.AutoSubnetTaggingPolicy:
 		// in OpenShift all private subnets are tagged. So assume any untagged subnets are public
 		// TODO: process the subnets based on whether they have attached internet gateways
+		desiredTags := desiredSubnetTags(resourceTags)
 		if untagged.Len() > 0 {
-			_, err = r.EC2Client.CreateTags(ctx, &ec2.CreateTagsInput{
-				Resources: untagged.List(),
-				Tags: []ec2types.Tag{
-					{
-						Key:   aws.String(publicELBTagKey),
-						Value: aws.String("1"),
-					},
-					{
-						Key:   aws.String(tagKeyALBOTagged),
-						Value: aws.String("1"),
-					},
-				},
-			})
+			// the subnets are tagged in batches when there are more of them than a call allows
+			err = aws.CreateTags(ctx, r.EC2Client, untagged.List(), desiredTags)
 			if err != nil {
-				err = fmt.Errorf("failed to tag subnets %v: %w", untaggedSubnets, err)
+				err = fmt.Errorf("failed to tag subnets %v: %w", untagged.List(), err)
 				return
 			}
 		}
+		// the subnets tagged by a previous reconcile get the resource tags which changed since then
+		if tagged.Len() > 0 && subnetResourceTagsChanged(controller.Status.Subnets, resourceTags) {
+			err = aws.CreateTags(ctx, r.EC2Client, tagged.List(), desiredTags)
+			if err != nil {
+				err = fmt.Errorf("failed to update tags of subnets %v: %w", tagged.List(), err)
+				return
+			}
+			if removedTags := removedSubnetResourceTags(controller.Status.Subnets, resourceTags); len(removedTags) > 0 {
+				err = aws.DeleteTags(ctx, r.EC2Client, tagged.List(), removedTags)
+				if err != nil {
+					err = fmt.Errorf("failed to remove resource tags from subnets %v: %w", tagged.List(), err)
+					return
+				}
+			}
+		}
 		// the untagged subnets are now public subnets
 		public = public.Union(untagged)
 		// marked the untagged subnets as now tagged
@@ -98,19 +116,16 @@ func (r *AWSLoadBalancerControllerReconciler) tagSubnets(ctx context.Context, co
 		// if the tagging policy was changed to Manual then remove tags from previously tagged subnets
 		if tagged.Len() > 0 {
 			// when values are not specified with the tag name the tag value is not considered during tag removal
-			_, err = r.EC2Client.DeleteTags(ctx, &ec2.DeleteTagsInput{
-				Resources: tagged.List(),
-				Tags: []ec2types.Tag{
-					{
-						Key: aws.String(publicELBTagKey),
-					},
-					{
-						Key: aws.String(tagKeyALBOTagged),
-					},
+			err = aws.DeleteTags(ctx, r.EC2Client, tagged.List(), []ec2types.Tag{
+				{
+					Key: awstypes.String(publicELBTagKey),
+				},
+				{
+					Key: awstypes.String(tagKeyALBOTagged),
 				},
 			})
 			if err != nil {
-				err = fmt.Errorf("failed to remove tags from currently tagged subnets %v: %w", taggedSubnets, err)
+				err = fmt.Errorf("failed to remove tags from currently tagged subnets %v: %w", tagged.List(), err)
 				return
 			}
 		}
@@ -131,6 +146,102 @@ func (r *AWSLoadBalancerControllerReconciler) tagSubnets(ctx context.Context, co
 	return
 }
 
+// subnetsTaggedCondition returns the SubnetsTagged condition with the result of the tagging of the subnets.
+func subnetsTaggedCondition(vpcID string, policy albo.SubnetTaggingPolicy, err error, generation int64) metav1.Condition {
+	if err != nil {
+		reason, message := awsErrorReasonAndMessage(err, "SubnetTaggingFailed", operatorCredentials)
+		return metav1.Condition{
+			Type:               SubnetsTaggedCondition,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: generation,
+			Reason:             reason,
+			Message:            message,
+		}
+	}
+	return metav1.Condition{
+		Type:               SubnetsTaggedCondition,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             "SubnetsTagged",
+		Message:            fmt.Sprintf("The subnets of VPC %s were processed with the %s subnet tagging policy", vpcID, policy),
+	}
+}
+
+// desiredSubnetTags returns the tags which are added to the subnets tagged by the operator. Along with the role
+// and operator tags the resource tags are added so that the subnets carry the same tags as the other resources
+// managed by the controller. The role and operator tags cannot be overridden by the resource tags.
+func desiredSubnetTags(resourceTags map[string]string) []ec2types.Tag {
+	tags := []ec2types.Tag{
+		{
+			Key:   awstypes.String(publicELBTagKey),
+			Value: awstypes.String("1"),
+		},
+		{
+			Key:   awstypes.String(tagKeyALBOTagged),
+			Value: awstypes.String("1"),
+		},
+	}
+	for _, k := range subnetResourceTagKeys(resourceTags) {
+		tags = append(tags, ec2types.Tag{
+			Key:   awstypes.String(k),
+			Value: awstypes.String(resourceTags[k]),
+		})
+	}
+	return tags
+}
+
+// subnetResourceTagKeys returns the sorted keys of the resource tags which are added to the subnets, without the keys
+// of the role and operator tags.
+func subnetResourceTagKeys(resourceTags map[string]string) []string {
+	var keys []string
+	for k := range resourceTags {
+		if k != publicELBTagKey && k != tagKeyALBOTagged {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// subnetResourceTagsHash returns the hash of the resource tags which are added to the subnets, it's empty when there
+// are no resource tags.
+func subnetResourceTagsHash(resourceTags map[string]string) string {
+	keys := subnetResourceTagKeys(resourceTags)
+	if len(keys) == 0 {
+		return ""
+	}
+	data := make(map[string][]byte, len(keys))
+	for _, k := range keys {
+		data[k] = []byte(resourceTags[k])
+	}
+	return dataHash(data)
+}
+
+// subnetResourceTagsChanged returns whether the resource tags differ from the ones added to the subnets of the status.
+func subnetResourceTagsChanged(subnets *albo.AWSLoadBalancerControllerStatusSubnets, resourceTags map[string]string) bool {
+	var previousHash string
+	if subnets != nil {
+		previousHash = subnets.ResourceTagsHash
+	}
+	return previousHash != subnetResourceTagsHash(resourceTags)
+}
+
+// removedSubnetResourceTags returns the resource tags added to the subnets of the status which are no longer in the
+// resource tags. Only their keys are set so that they are removed whatever their value.
+func removedSubnetResourceTags(subnets *albo.AWSLoadBalancerControllerStatusSubnets, resourceTags map[string]string) []ec2types.Tag {
+	if subnets == nil {
+		return nil
+	}
+	current := sets.NewString(subnetResourceTagKeys(resourceTags)...)
+	var removed []ec2types.Tag
+	for _, k := range subnets.ResourceTagKeys {
+		if !current.Has(k) {
+			removed = append(removed, ec2types.Tag{Key: awstypes.String(k)})
+		}
+	}
+	return removed
+}
+
 func classifySubnets(subnets []ec2types.Subnet) (sets.String, sets.String, sets.String, sets.String, error) {
 	var (
 		internal = sets.NewString()
@@ -140,7 +251,7 @@ func classifySubnets(subnets []ec2types.Subnet) (sets.String, sets.String, sets.
 	)
 
 	for _, s := range subnets {
-		subnetID := aws.ToString(s.SubnetId)
+		subnetID := awstypes.ToString(s.SubnetId)
 		if hasTag(s.Tags, internalELBTagKey) {
 			internal.Insert(subnetID)
 		}
@@ -164,7 +275,7 @@ func classifySubnets(subnets []ec2types.Subnet) (sets.String, sets.String, sets.
 
 func hasTag(tags []ec2types.Tag, key string) bool {
 	for _, t := range tags {
-		if aws.ToString(t.Key) == key {
+		if awstypes.ToString(t.Key) == key {
 			return true
 		}
 	}
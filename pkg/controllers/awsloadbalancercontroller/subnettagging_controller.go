@@ -0,0 +1,118 @@
+package awsloadbalancercontroller
+
+import (
+	"context"
+	"fmt"
+
+	configv1 "github.com/openshift/api/config/v1"
+
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	albo "github.com/openshift/aws-load-balancer-operator/api/v1alpha1"
+	"github.com/openshift/aws-load-balancer-operator/pkg/aws"
+)
+
+const (
+	// subnetTaggingControllerName is the name of the controller which tags the subnets
+	subnetTaggingControllerName = "subnettagging"
+)
+
+// subnetTaggingReconciler discovers the platform and the VPC of the AWSLoadBalancerController and tags the subnets
+// of the VPC. It reports the PlatformDiscovered, VPCDiscovered and SubnetsTagged conditions.
+type subnetTaggingReconciler struct {
+	*AWSLoadBalancerControllerReconciler
+}
+
+func (r *subnetTaggingReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	// a throttled account fails the reconcile once the budget is used, the reconcile is then retried with backoff
+	ctx = aws.WithCallBudget(ctx, awsCallBudget)
+
+	// the subnets are tagged when the status doesn't have the subnets of the policy and the VPC yet, the status is
+	// read from the API server as the cache may not have the subnets just written by the previous reconcile
+	lbController, err := r.reconciledController(ctx, r.apiReader(), req.Name)
+	if err != nil || lbController == nil {
+		return ctrl.Result{}, err
+	}
+
+	// the conditions and the subnets are written once whatever the result of the reconcile
+	status := &controllerStatus{}
+	result, err := r.reconcileSubnets(ctx, lbController, status)
+	if statusErr := r.writeStatus(ctx, subnetTaggingControllerName, lbController, status); statusErr != nil {
+		return ctrl.Result{}, statusErr
+	}
+	return result, err
+}
+
+// reconcileSubnets discovers the platform and the VPC and tags the subnets of the VPC, the conditions and the
+// processed subnets are added to the given status.
+func (r *subnetTaggingReconciler) reconcileSubnets(ctx context.Context, lbController *albo.AWSLoadBalancerController, status *controllerStatus) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	// the platform discovery failures are reported in the status and retried with backoff by returning the error
+	err := r.ensurePlatform(ctx)
+	status.addConditions(platformDiscoveredCondition(err, lbController.Generation))
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to discover platform: %w", err)
+	}
+
+	// the VPC discovery failures are reported in the status and retried, as they can be solved by selecting the VPC in the spec
+	vpcID, vpcSource, err := r.discoverVPC(ctx, lbController)
+	status.addConditions(vpcDiscoveredCondition(vpcID, vpcSource, err, lbController.Generation))
+	if err != nil {
+		logger.Error(err, "failed to discover VPC")
+		requeueAfter := vpcDiscoveryReEnqueueDuration
+		if awsRequeueAfter, ok := awsErrorRequeueDuration(err); ok {
+			requeueAfter = awsRequeueAfter
+		}
+		return ctrl.Result{RequeueAfter: requeueAfter}, nil
+	}
+
+	// the resource tags of the spec and of the Infrastructure are added to the subnets tagged with the Auto policy
+	var resourceTags map[string]string
+	if lbController.Spec.SubnetTagging == albo.AutoSubnetTaggingPolicy {
+		resourceTags, err = r.resourceTags(ctx, lbController)
+		if err != nil {
+			err = fmt.Errorf("failed to get resource tags for subnets: %w", err)
+			status.addConditions(subnetsTaggedCondition(vpcID, lbController.Spec.SubnetTagging, err, lbController.Generation))
+			return ctrl.Result{}, fmt.Errorf("failed to update subnets: %w", err)
+		}
+	}
+
+	// the subnets already processed with the tagging policy and the resource tags in the VPC are only reported for
+	// the current generation
+	if lbController.Status.Subnets != nil && lbController.Spec.SubnetTagging == lbController.Status.Subnets.SubnetTagging && vpcID == lbController.Status.Subnets.VPCID && !subnetResourceTagsChanged(lbController.Status.Subnets, resourceTags) {
+		status.addConditions(subnetsTaggedCondition(vpcID, lbController.Spec.SubnetTagging, nil, lbController.Generation))
+		return ctrl.Result{}, nil
+	}
+
+	// the processed subnets have not yet been written into the status or the tagging policy, the VPC or the resource
+	// tags have changed
+	internalSubnets, publicSubnets, untaggedSubnets, taggedSubnets, err := r.tagSubnets(ctx, lbController, vpcID, resourceTags)
+	subnetsTagged := subnetsTaggedCondition(vpcID, lbController.Spec.SubnetTagging, err, lbController.Generation)
+	status.addConditions(subnetsTagged)
+	if err != nil {
+		// the AWS errors are reported in the status with a remediation hint and retried after a delay given by their class
+		if requeueAfter, ok := awsErrorRequeueDuration(err); ok {
+			logger.Error(err, "failed to update subnets", "reason", subnetsTagged.Reason, "requeueAfter", requeueAfter)
+			return ctrl.Result{RequeueAfter: requeueAfter}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("failed to update subnets: %w", err)
+	}
+	status.setSubnets(internalSubnets, publicSubnets, untaggedSubnets, taggedSubnets, lbController.Spec.SubnetTagging, vpcID, resourceTags)
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the subnet tagging controller with the Manager. The Infrastructure is watched to retry
+// the platform discovery when its status is filled.
+func (r *subnetTaggingReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(subnetTaggingControllerName).
+		For(&albo.AWSLoadBalancerController{}, builder.WithPredicates(reconcileClusterNamedResource(), predicate.GenerationChangedPredicate{})).
+		Watches(&source.Kind{Type: &configv1.Infrastructure{}}, handler.EnqueueRequestsFromMapFunc(infrastructureToControllerRequests)).
+		Complete(r)
+}
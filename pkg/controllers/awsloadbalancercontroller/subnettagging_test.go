@@ -8,11 +8,14 @@ import (
 	"testing"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/sets"
 
 	awstypes "github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/ec2"
 	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+	"github.com/google/go-cmp/cmp"
+	configv1 "github.com/openshift/api/config/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 
 	albo "github.com/openshift/aws-load-balancer-operator/api/v1alpha1"
@@ -128,6 +131,12 @@ func TestTagSubnets(t *testing.T) {
 		expectedInternalSubnets     []string
 		expectedCreateTagOperations []string
 		expectedRemoveTagOperations []string
+		infraResourceTags           []configv1.AWSResourceTag
+		additionalResourceTags      map[string]string
+		// statusResourceTags are the resource tags added to the tagged subnets by a previous reconcile
+		statusResourceTags          map[string]string
+		expectedResourceTags        map[string]string
+		expectedRemovedResourceTags []string
 	}{
 		{
 			name: "auto tagging, no preexisting tagged subnets",
@@ -142,6 +151,21 @@ func TestTagSubnets(t *testing.T) {
 			expectedInternalSubnets:     []string{"subnet-2"},
 			expectedCreateTagOperations: []string{"subnet-1"},
 		},
+		{
+			name: "auto tagging, with infrastructure and spec resource tags",
+			currentSubnets: []ec2types.Subnet{
+				testSubnet("subnet-1"),
+				testSubnet("subnet-2", internalELBTagKey),
+			},
+			taggingPolicy:               albo.AutoSubnetTaggingPolicy,
+			infraResourceTags:           []configv1.AWSResourceTag{{Key: "cost-center", Value: "1234"}, {Key: "owner", Value: "team-a"}},
+			additionalResourceTags:      map[string]string{"owner": "team-b", publicELBTagKey: "0"},
+			expectedTaggedSubnets:       []string{"subnet-1"},
+			expectedPublicSubnets:       []string{"subnet-1"},
+			expectedInternalSubnets:     []string{"subnet-2"},
+			expectedCreateTagOperations: []string{"subnet-1"},
+			expectedResourceTags:        map[string]string{"cost-center": "1234", "owner": "team-b"},
+		},
 		{
 			name: "auto tagging, with preexisting tagged subnets",
 			currentSubnets: []ec2types.Subnet{
@@ -154,6 +178,34 @@ func TestTagSubnets(t *testing.T) {
 			expectedPublicSubnets:   []string{"subnet-1", "subnet-3"},
 			expectedInternalSubnets: []string{"subnet-2"},
 		},
+		{
+			name: "auto tagging, resource tags changed since the subnets were tagged",
+			currentSubnets: []ec2types.Subnet{
+				testSubnet("subnet-1", publicELBTagKey, tagKeyALBOTagged),
+				testSubnet("subnet-2"),
+				testSubnet("subnet-3", publicELBTagKey),
+			},
+			taggingPolicy:               albo.AutoSubnetTaggingPolicy,
+			statusResourceTags:          map[string]string{"owner": "team-a", "cost-center": "1234"},
+			additionalResourceTags:      map[string]string{"owner": "team-b"},
+			expectedTaggedSubnets:       []string{"subnet-1", "subnet-2"},
+			expectedPublicSubnets:       []string{"subnet-1", "subnet-2", "subnet-3"},
+			expectedCreateTagOperations: []string{"subnet-1", "subnet-2"},
+			expectedResourceTags:        map[string]string{"owner": "team-b"},
+			expectedRemovedResourceTags: []string{"cost-center"},
+		},
+		{
+			name: "auto tagging, resource tags unchanged since the subnets were tagged",
+			currentSubnets: []ec2types.Subnet{
+				testSubnet("subnet-1", publicELBTagKey, tagKeyALBOTagged),
+				testSubnet("subnet-3", publicELBTagKey),
+			},
+			taggingPolicy:          albo.AutoSubnetTaggingPolicy,
+			statusResourceTags:     map[string]string{"owner": "team-a"},
+			additionalResourceTags: map[string]string{"owner": "team-a"},
+			expectedTaggedSubnets:  []string{"subnet-1"},
+			expectedPublicSubnets:  []string{"subnet-1", "subnet-3"},
+		},
 		{
 			name: "manual tagging, with no preexisting tagged subnets",
 			currentSubnets: []ec2types.Subnet{
@@ -182,8 +234,21 @@ func TestTagSubnets(t *testing.T) {
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			controller := testALBC(tc.taggingPolicy)
+			controller.Spec.AdditionalResourceTags = tc.additionalResourceTags
+			controller.Status.Subnets.ResourceTagKeys = subnetResourceTagKeys(tc.statusResourceTags)
+			controller.Status.Subnets.ResourceTagsHash = subnetResourceTagsHash(tc.statusResourceTags)
+			infra := &configv1.Infrastructure{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+				Status: configv1.InfrastructureStatus{
+					PlatformStatus: &configv1.PlatformStatus{
+						Type: configv1.AWSPlatformType,
+						AWS:  &configv1.AWSPlatformStatus{ResourceTags: tc.infraResourceTags},
+					},
+				},
+			}
 			client := fake.NewClientBuilder().WithScheme(test.Scheme).WithObjects(
 				controller,
+				infra,
 			).Build()
 			ec2Client := &testEC2Client{
 				t:         t,
@@ -196,7 +261,16 @@ func TestTagSubnets(t *testing.T) {
 				ClusterName: "test-cluster",
 			}
 
-			internal, public, untagged, tagged, err := r.tagSubnets(context.Background(), controller)
+			var resourceTags map[string]string
+			if tc.taggingPolicy == albo.AutoSubnetTaggingPolicy {
+				var err error
+				resourceTags, err = r.resourceTags(context.Background(), controller)
+				if err != nil {
+					t.Fatalf("failed to get resource tags: %v", err)
+				}
+			}
+
+			internal, public, untagged, tagged, err := r.tagSubnets(context.Background(), controller, "test-vpc", resourceTags)
 			if err != nil {
 				t.Errorf("got unexpected error: %v", err)
 				return
@@ -206,6 +280,14 @@ func TestTagSubnets(t *testing.T) {
 				t.Errorf("expected subnets %v to be tagged, instead got %v", tc.expectedCreateTagOperations, ec2Client.taggedResources)
 			}
 
+			if diff := cmp.Diff(tc.expectedResourceTags, ec2Client.resourceTags); diff != "" {
+				t.Errorf("unexpected resource tags on subnets:\n%s", diff)
+			}
+
+			if !equalStrings(tc.expectedRemovedResourceTags, ec2Client.removedResourceTags) {
+				t.Errorf("expected resource tags %v to have been removed, instead got %v", tc.expectedRemovedResourceTags, ec2Client.removedResourceTags)
+			}
+
 			if !equalStrings(tc.expectedRemoveTagOperations, ec2Client.untaggedResources) {
 				t.Errorf("expected subnets %v to have been untagged, instead got %v", tc.expectedRemoveTagOperations, ec2Client.untaggedResources)
 			}
@@ -226,6 +308,94 @@ func TestTagSubnets(t *testing.T) {
 	}
 }
 
+// TestReconcileSubnetsResourceTags checks that the resource tags written in the status by a reconcile are used by
+// the next reconciles to update the tags of the subnets only when they change.
+func TestReconcileSubnetsResourceTags(t *testing.T) {
+	controller := &albo.AWSLoadBalancerController{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Generation: 1},
+		Spec: albo.AWSLoadBalancerControllerSpec{
+			SubnetTagging:          albo.AutoSubnetTaggingPolicy,
+			AdditionalResourceTags: map[string]string{"owner": "team-a", "cost-center": "1234"},
+		},
+	}
+	infra := &configv1.Infrastructure{
+		ObjectMeta: metav1.ObjectMeta{Name: clusterInfrastructureName},
+		Status: configv1.InfrastructureStatus{
+			PlatformStatus: &configv1.PlatformStatus{
+				Type: configv1.AWSPlatformType,
+				AWS:  &configv1.AWSPlatformStatus{Region: testAWSRegion},
+			},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(test.Scheme).WithObjects(controller, infra).Build()
+	ec2Client := &testEC2Client{
+		t: t,
+		subnets: []ec2types.Subnet{
+			testSubnet("subnet-1", publicELBTagKey, tagKeyALBOTagged),
+			testSubnet("subnet-2", publicELBTagKey),
+		},
+		clusterID: "test-cluster",
+	}
+	r := &subnetTaggingReconciler{&AWSLoadBalancerControllerReconciler{
+		Client:      fakeClient,
+		EC2Client:   ec2Client,
+		ClusterName: "test-cluster",
+		VPCID:       "test-vpc",
+	}}
+
+	for _, step := range []struct {
+		name                        string
+		additionalResourceTags      map[string]string
+		expectedCreateTagOperations []string
+		expectedRemovedResourceTags []string
+	}{
+		{
+			name:                        "resource tags added",
+			additionalResourceTags:      map[string]string{"owner": "team-a", "cost-center": "1234"},
+			expectedCreateTagOperations: []string{"subnet-1"},
+		},
+		{
+			name:                   "resource tags unchanged",
+			additionalResourceTags: map[string]string{"owner": "team-a", "cost-center": "1234"},
+		},
+		{
+			name:                        "resource tag removed",
+			additionalResourceTags:      map[string]string{"owner": "team-a"},
+			expectedCreateTagOperations: []string{"subnet-1"},
+			expectedRemovedResourceTags: []string{"cost-center"},
+		},
+	} {
+		ec2Client.taggedResources, ec2Client.removedResourceTags = nil, nil
+
+		current := &albo.AWSLoadBalancerController{}
+		if err := fakeClient.Get(context.Background(), types.NamespacedName{Name: "cluster"}, current); err != nil {
+			t.Fatalf("%s: failed to get controller: %v", step.name, err)
+		}
+		if !cmp.Equal(current.Spec.AdditionalResourceTags, step.additionalResourceTags) {
+			current.Spec.AdditionalResourceTags = step.additionalResourceTags
+			current.Generation++
+			if err := fakeClient.Update(context.Background(), current); err != nil {
+				t.Fatalf("%s: failed to update controller: %v", step.name, err)
+			}
+		}
+
+		status := &controllerStatus{}
+		if _, err := r.reconcileSubnets(context.Background(), current, status); err != nil {
+			t.Fatalf("%s: unexpected error: %v", step.name, err)
+		}
+		if err := r.writeStatus(context.Background(), subnetTaggingControllerName, current, status); err != nil {
+			t.Fatalf("%s: failed to write status: %v", step.name, err)
+		}
+
+		if !equalStrings(step.expectedCreateTagOperations, ec2Client.taggedResources) {
+			t.Errorf("%s: expected subnets %v to be tagged, instead got %v", step.name, step.expectedCreateTagOperations, ec2Client.taggedResources)
+		}
+		if !equalStrings(step.expectedRemovedResourceTags, ec2Client.removedResourceTags) {
+			t.Errorf("%s: expected resource tags %v to have been removed, instead got %v", step.name, step.expectedRemovedResourceTags, ec2Client.removedResourceTags)
+		}
+	}
+}
+
 func testALBC(taggingPolicy albo.SubnetTaggingPolicy) *albo.AWSLoadBalancerController {
 	return &albo.AWSLoadBalancerController{
 		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
@@ -244,6 +414,9 @@ type testEC2Client struct {
 	clusterID         string
 	taggedResources   []string
 	untaggedResources []string
+	resourceTags      map[string]string
+	// removedResourceTags are the keys of the resource tags removed from the subnets
+	removedResourceTags []string
 	aws.VPCClient
 }
 
@@ -251,10 +424,14 @@ var badQueryError = errors.New("bad query")
 
 func (t *testEC2Client) DescribeSubnets(_ context.Context, input *ec2.DescribeSubnetsInput, _ ...func(*ec2.Options)) (*ec2.DescribeSubnetsOutput, error) {
 	t.t.Helper()
-	if len(input.Filters) != 1 {
+	if len(input.Filters) != 2 {
 		t.t.Errorf("query does not have correct number of filters")
 		return nil, badQueryError
 	}
+	if awstypes.ToString(input.Filters[1].Name) != vpcIDFilterName || len(input.Filters[1].Values) != 1 || input.Filters[1].Values[0] != "test-vpc" {
+		t.t.Errorf("unexpected VPC filter %s=%v", awstypes.ToString(input.Filters[1].Name), input.Filters[1].Values)
+		return nil, badQueryError
+	}
 	if awstypes.ToString(input.Filters[0].Name) != tagKeyFilterName {
 		t.t.Errorf("unexpected filter name %s", awstypes.ToString(input.Filters[0].Name))
 		return nil, badQueryError
@@ -272,7 +449,7 @@ func (t *testEC2Client) DescribeSubnets(_ context.Context, input *ec2.DescribeSu
 
 func (t *testEC2Client) CreateTags(_ context.Context, input *ec2.CreateTagsInput, _ ...func(*ec2.Options)) (*ec2.CreateTagsOutput, error) {
 	t.t.Helper()
-	if len(input.Tags) != 2 {
+	if len(input.Tags) < 2 {
 		t.t.Errorf("unexpected number of tags: %d", len(input.Tags))
 		return nil, badQueryError
 	}
@@ -284,12 +461,29 @@ func (t *testEC2Client) CreateTags(_ context.Context, input *ec2.CreateTagsInput
 		t.t.Errorf("input %v does not have tag key %s", input.Tags, tagKeyALBOTagged)
 		return nil, badQueryError
 	}
+	for _, tag := range input.Tags {
+		key := awstypes.ToString(tag.Key)
+		if key == publicELBTagKey || key == tagKeyALBOTagged {
+			continue
+		}
+		if t.resourceTags == nil {
+			t.resourceTags = make(map[string]string)
+		}
+		t.resourceTags[key] = awstypes.ToString(tag.Value)
+	}
 	t.taggedResources = append(t.taggedResources, input.Resources...)
 	return nil, nil
 }
 
 func (t *testEC2Client) DeleteTags(ctx context.Context, input *ec2.DeleteTagsInput, _ ...func(*ec2.Options)) (*ec2.DeleteTagsOutput, error) {
 	t.t.Helper()
+	// the resource tags are removed without the role and operator tags
+	if !hasTag(input.Tags, publicELBTagKey) && !hasTag(input.Tags, tagKeyALBOTagged) {
+		for _, tag := range input.Tags {
+			t.removedResourceTags = append(t.removedResourceTags, awstypes.ToString(tag.Key))
+		}
+		return nil, nil
+	}
 	if len(input.Tags) != 2 {
 		t.t.Errorf("unexpected number of tags: %d", len(input.Tags))
 		return nil, badQueryError
@@ -305,3 +499,56 @@ func (t *testEC2Client) DeleteTags(ctx context.Context, input *ec2.DeleteTagsInp
 	t.untaggedResources = append(t.untaggedResources, input.Resources...)
 	return nil, nil
 }
+
+func TestSubnetsTaggedCondition(t *testing.T) {
+	unauthorizedErr := &aws.Error{
+		Class:     aws.UnauthorizedErrorClass,
+		Action:    "ec2:CreateTags",
+		Resources: []string{"subnet-xyz"},
+		Err:       errors.New("api error UnauthorizedOperation: You are not authorized to perform this operation."),
+	}
+	for _, tc := range []struct {
+		name     string
+		err      error
+		expected metav1.Condition
+	}{
+		{
+			name: "tagged",
+			expected: metav1.Condition{
+				Type:               SubnetsTaggedCondition,
+				Status:             metav1.ConditionTrue,
+				ObservedGeneration: 3,
+				Reason:             "SubnetsTagged",
+				Message:            "The subnets of VPC vpc-1 were processed with the Auto subnet tagging policy",
+			},
+		},
+		{
+			name: "unauthorized",
+			err:  fmt.Errorf("failed to tag subnets [subnet-xyz]: %w", unauthorizedErr),
+			expected: metav1.Condition{
+				Type:               SubnetsTaggedCondition,
+				Status:             metav1.ConditionFalse,
+				ObservedGeneration: 3,
+				Reason:             "AWSUnauthorized",
+				Message:            "operator credentials lack ec2:CreateTags on subnet-xyz, allow it in the IAM policy of the credentials or check that they have not expired: failed to tag subnets [subnet-xyz]: api error UnauthorizedOperation: You are not authorized to perform this operation.",
+			},
+		},
+		{
+			name: "unclassified error",
+			err:  errors.New("subnet subnet-1 has both tags with keys kubernetes.io/role/internal-elb and kubernetes.io/role/elb"),
+			expected: metav1.Condition{
+				Type:               SubnetsTaggedCondition,
+				Status:             metav1.ConditionFalse,
+				ObservedGeneration: 3,
+				Reason:             "SubnetTaggingFailed",
+				Message:            "subnet subnet-1 has both tags with keys kubernetes.io/role/internal-elb and kubernetes.io/role/elb",
+			},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.expected, subnetsTaggedCondition("vpc-1", albo.AutoSubnetTaggingPolicy, tc.err, 3)); diff != "" {
+				t.Errorf("unexpected condition (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
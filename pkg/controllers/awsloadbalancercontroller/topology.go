@@ -0,0 +1,71 @@
+package awsloadbalancercontroller
+
+import (
+	configv1 "github.com/openshift/api/config/v1"
+
+	albo "github.com/openshift/aws-load-balancer-operator/api/v1alpha1"
+)
+
+const (
+	// defaultReplicas is the default number of replicas of the operand on highly available topologies
+	defaultReplicas = 2
+	// controlPlaneNodeLabel is the label of the control plane nodes
+	controlPlaneNodeLabel = "node-role.kubernetes.io/master"
+)
+
+// ClusterTopology is the topology of the cluster from the Infrastructure status. The topologies are empty on
+// clusters which don't report them, they are then highly available.
+type ClusterTopology struct {
+	// ControlPlane is the topology of the control plane, External when the control plane is hosted outside the
+	// cluster, e.g. on HyperShift guest clusters
+	ControlPlane configv1.TopologyMode
+	// Infrastructure is the topology of the infrastructure which runs the workloads, including the operand
+	Infrastructure configv1.TopologyMode
+}
+
+// operandConfig is the effective configuration of the deployment of the operand.
+type operandConfig struct {
+	topology ClusterTopology
+	replicas int32
+	// leaderElection is enabled when more than one replica runs
+	leaderElection bool
+	// highAvailability spreads the replicas across the zones and the nodes, rolls them out and evicts them one at a time
+	highAvailability bool
+	// avoidControlPlane prefers the nodes which are not control plane nodes
+	avoidControlPlane bool
+}
+
+// desiredOperandConfig returns the effective configuration of the operand, the values which are not set in the
+// spec are defaulted from the topology of the cluster. On single replica infrastructure topologies, e.g. single node
+// clusters, the replicas can't run on distinct nodes: a single replica runs by default and the high availability
+// mode is off as its PodDisruptionBudget would block the drain of the node. The replicas prefer the nodes which
+// are not control plane nodes unless the control plane is external, in which case the cluster has no control plane
+// nodes.
+func desiredOperandConfig(controller *albo.AWSLoadBalancerController, topology ClusterTopology) operandConfig {
+	singleReplica := topology.Infrastructure == configv1.SingleReplicaTopologyMode
+	config := operandConfig{
+		topology:          topology,
+		replicas:          defaultReplicas,
+		avoidControlPlane: !singleReplica && topology.ControlPlane != configv1.ExternalTopologyMode,
+	}
+	if singleReplica {
+		config.replicas = 1
+	}
+	if controller.Spec.Config != nil && controller.Spec.Config.Replicas != 0 {
+		config.replicas = controller.Spec.Config.Replicas
+	}
+	config.leaderElection = config.replicas > 1
+	config.highAvailability = config.replicas > 1 && !singleReplica
+	return config
+}
+
+// statusDeployment returns the effective configuration reported in the status.
+func (c operandConfig) statusDeployment() *albo.AWSLoadBalancerControllerStatusDeployment {
+	return &albo.AWSLoadBalancerControllerStatusDeployment{
+		Replicas:               c.replicas,
+		LeaderElection:         c.leaderElection,
+		HighAvailability:       c.highAvailability,
+		ControlPlaneTopology:   string(c.topology.ControlPlane),
+		InfrastructureTopology: string(c.topology.Infrastructure),
+	}
+}
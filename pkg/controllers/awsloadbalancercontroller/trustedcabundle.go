@@ -0,0 +1,57 @@
+package awsloadbalancercontroller
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	albo "github.com/openshift/aws-load-balancer-operator/api/v1alpha1"
+)
+
+const (
+	// injectTrustedCABundleLabelKey is the label which makes the Cluster Network Operator inject the cluster's
+	// trusted CA bundle into the ConfigMap
+	injectTrustedCABundleLabelKey   = "config.openshift.io/inject-trusted-cabundle"
+	injectTrustedCABundleLabelValue = "true"
+	// trustedCABundleKey is the key of the ConfigMap where the trusted CA bundle is injected
+	trustedCABundleKey = "ca-bundle.crt"
+	// prefix of the name of the ConfigMap with the trusted CA bundle
+	trustedCABundlePrefix = controllerResourcePrefix + "-trusted-ca-"
+)
+
+// ensureTrustedCABundleConfigMap ensures that the ConfigMap into which the trusted CA bundle is injected exists.
+// Only the injection label is applied, the data is owned by the Cluster Network Operator.
+func (r *AWSLoadBalancerControllerReconciler) ensureTrustedCABundleConfigMap(ctx context.Context, namespace string, controller *albo.AWSLoadBalancerController) (*corev1.ConfigMap, error) {
+	name := types.NamespacedName{Namespace: namespace, Name: trustedCABundlePrefix + controller.Name}
+
+	reqLogger := log.FromContext(ctx).WithValues("configmap", name)
+	reqLogger.Info("ensuring trusted CA bundle configmap for aws-load-balancer-controller instance")
+
+	desired := desiredTrustedCABundleConfigMap(name)
+	if err := controllerutil.SetControllerReference(controller, desired, r.Scheme); err != nil {
+		return nil, fmt.Errorf("failed to set owner reference on configmap %q: %w", name, err)
+	}
+
+	if err := r.apply(ctx, desired); err != nil {
+		return nil, fmt.Errorf("failed to apply configmap %q: %w", name, err)
+	}
+	return desired, nil
+}
+
+func desiredTrustedCABundleConfigMap(name types.NamespacedName) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name.Name,
+			Namespace: name.Namespace,
+			Labels: map[string]string{
+				injectTrustedCABundleLabelKey: injectTrustedCABundleLabelValue,
+			},
+		},
+	}
+}
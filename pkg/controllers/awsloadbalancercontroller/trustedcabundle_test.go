@@ -0,0 +1,114 @@
+package awsloadbalancercontroller
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	albo "github.com/openshift/aws-load-balancer-operator/api/v1alpha1"
+	"github.com/openshift/aws-load-balancer-operator/pkg/controllers/utils/test"
+)
+
+func TestEnsureTrustedCABundleConfigMap(t *testing.T) {
+	for _, tc := range []struct {
+		name            string
+		existingObjects []runtime.Object
+		expectedLabels  map[string]string
+		expectedData    map[string]string
+	}{
+		{
+			name: "configmap created",
+			expectedLabels: map[string]string{
+				injectTrustedCABundleLabelKey: "true",
+				managedByLabelKey:             managedByLabelValue,
+			},
+		},
+		{
+			name: "injected bundle preserved",
+			existingObjects: []runtime.Object{
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "aws-load-balancer-controller-trusted-ca-cluster",
+						Namespace: test.OperatorNamespace,
+						Labels: map[string]string{
+							injectTrustedCABundleLabelKey: "true",
+						},
+					},
+					Data: map[string]string{"ca-bundle.crt": "test-bundle"},
+				},
+			},
+			expectedLabels: map[string]string{
+				injectTrustedCABundleLabelKey: "true",
+				managedByLabelKey:             managedByLabelValue,
+			},
+			expectedData: map[string]string{"ca-bundle.crt": "test-bundle"},
+		},
+		{
+			name: "injection label restored",
+			existingObjects: []runtime.Object{
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "aws-load-balancer-controller-trusted-ca-cluster",
+						Namespace: test.OperatorNamespace,
+						Labels: map[string]string{
+							"test-label": "test-value",
+						},
+					},
+					Data: map[string]string{"ca-bundle.crt": "test-bundle"},
+				},
+			},
+			expectedLabels: map[string]string{
+				injectTrustedCABundleLabelKey: "true",
+				managedByLabelKey:             managedByLabelValue,
+				"test-label":                  "test-value",
+			},
+			expectedData: map[string]string{"ca-bundle.crt": "test-bundle"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			client := test.NewApplyClient(fake.NewClientBuilder().WithScheme(test.Scheme).WithRuntimeObjects(tc.existingObjects...).Build())
+			r := &AWSLoadBalancerControllerReconciler{
+				Client:    client,
+				Scheme:    test.Scheme,
+				Namespace: test.OperatorNamespace,
+			}
+			controller := &albo.AWSLoadBalancerController{ObjectMeta: metav1.ObjectMeta{Name: controllerName}}
+			_, err := r.ensureTrustedCABundleConfigMap(context.Background(), r.Namespace, controller)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var configMap corev1.ConfigMap
+			err = client.Get(context.Background(), types.NamespacedName{Namespace: test.OperatorNamespace, Name: "aws-load-balancer-controller-trusted-ca-cluster"}, &configMap)
+			if err != nil {
+				t.Fatalf("failed to get configmap: %v", err)
+			}
+			if diff := cmp.Diff(tc.expectedLabels, configMap.Labels); diff != "" {
+				t.Errorf("unexpected labels:\n%s", diff)
+			}
+			if diff := cmp.Diff(tc.expectedData, configMap.Data); diff != "" {
+				t.Errorf("unexpected data:\n%s", diff)
+			}
+		})
+	}
+}
+
+// testInjectedTrustedCABundle returns the trusted CA bundle ConfigMap of the operand with the bundle injected by the
+// Cluster Network Operator.
+func testInjectedTrustedCABundle() *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      trustedCABundlePrefix + controllerName,
+			Namespace: test.OperatorNamespace,
+			Labels:    map[string]string{injectTrustedCABundleLabelKey: injectTrustedCABundleLabelValue},
+		},
+		Data: map[string]string{trustedCABundleKey: "test-bundle"},
+	}
+}
@@ -0,0 +1,99 @@
+package awsloadbalancercontroller
+
+import (
+	"context"
+	"fmt"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	albo "github.com/openshift/aws-load-balancer-operator/api/v1alpha1"
+	"github.com/openshift/aws-load-balancer-operator/pkg/aws"
+)
+
+// VPCDiscoveredCondition reports whether the VPC of the load balancers was found
+const VPCDiscoveredCondition = "VPCDiscovered"
+
+// specVPCKey holds the inputs of the lookup of the VPC selected in the spec.
+type specVPCKey struct {
+	platform     *Platform
+	vpcID        string
+	selectorHash string
+}
+
+// cachedSpecVPC is the VPC selected in the spec along with the inputs of its lookup.
+type cachedSpecVPC struct {
+	key   specVPCKey
+	vpcID string
+}
+
+// discoverVPC returns the ID of the VPC where the load balancers are provisioned along with a description of how
+// it was selected. The VPC set in the spec, by ID or by tags, overrides the VPC discovered with the cluster tag.
+// The VPC set in the spec is looked up again only when the spec or the platform change.
+func (r *AWSLoadBalancerControllerReconciler) discoverVPC(ctx context.Context, controller *albo.AWSLoadBalancerController) (string, string, error) {
+	r.platformLock.Lock()
+	defer r.platformLock.Unlock()
+
+	switch {
+	case controller.Spec.VPCID != "":
+		key := specVPCKey{platform: r.platform, vpcID: controller.Spec.VPCID}
+		if r.specVPC != nil && r.specVPC.key == key {
+			return r.specVPC.vpcID, "spec.vpcID", nil
+		}
+		if err := aws.ValidateVPCId(ctx, r.EC2Client, controller.Spec.VPCID); err != nil {
+			return "", "", fmt.Errorf("failed to find VPC of spec.vpcID: %w", err)
+		}
+		r.specVPC = &cachedSpecVPC{key: key, vpcID: controller.Spec.VPCID}
+		return controller.Spec.VPCID, "spec.vpcID", nil
+	case controller.Spec.VPCSelector != nil:
+		key := specVPCKey{platform: r.platform, selectorHash: vpcSelectorHash(controller.Spec.VPCSelector)}
+		if r.specVPC != nil && r.specVPC.key == key {
+			return r.specVPC.vpcID, "spec.vpcSelector", nil
+		}
+		vpcID, err := aws.GetVPCIdByTags(ctx, r.EC2Client, controller.Spec.VPCSelector.MatchTags)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to find VPC of spec.vpcSelector: %w", err)
+		}
+		r.specVPC = &cachedSpecVPC{key: key, vpcID: vpcID}
+		return vpcID, "spec.vpcSelector", nil
+	}
+
+	// the VPC of the cluster is discovered once, either on start up or when it failed on start up
+	if r.VPCID == "" {
+		vpcID, err := aws.GetVPCId(ctx, r.EC2Client, r.ClusterName)
+		if err != nil {
+			return "", "", fmt.Errorf("failed to discover VPC of cluster %s, set spec.vpcID or spec.vpcSelector to select the VPC: %w", r.ClusterName, err)
+		}
+		r.VPCID = vpcID
+	}
+	return r.VPCID, "the cluster tag", nil
+}
+
+// vpcSelectorHash returns a stable hash of the tags of the VPC selector.
+func vpcSelectorHash(selector *albo.VPCSelector) string {
+	data := make(map[string][]byte, len(selector.MatchTags))
+	for k, v := range selector.MatchTags {
+		data[k] = []byte(v)
+	}
+	return dataHash(data)
+}
+
+// vpcDiscoveredCondition returns the VPCDiscovered condition with the result of the VPC discovery.
+func vpcDiscoveredCondition(vpcID, source string, err error, generation int64) metav1.Condition {
+	if err != nil {
+		reason, message := awsErrorReasonAndMessage(err, "VPCDiscoveryFailed", operatorCredentials)
+		return metav1.Condition{
+			Type:               VPCDiscoveredCondition,
+			Status:             metav1.ConditionFalse,
+			ObservedGeneration: generation,
+			Reason:             reason,
+			Message:            message,
+		}
+	}
+	return metav1.Condition{
+		Type:               VPCDiscoveredCondition,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: generation,
+		Reason:             "VPCDiscovered",
+		Message:            fmt.Sprintf("VPC %s was selected with %s", vpcID, source),
+	}
+}
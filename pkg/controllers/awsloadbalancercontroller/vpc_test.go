@@ -0,0 +1,251 @@
+package awsloadbalancercontroller
+
+import (
+	"context"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	awstypes "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ec2"
+	ec2types "github.com/aws/aws-sdk-go-v2/service/ec2/types"
+
+	albo "github.com/openshift/aws-load-balancer-operator/api/v1alpha1"
+	"github.com/openshift/aws-load-balancer-operator/pkg/aws"
+)
+
+// testVPCClient returns the VPCs which have the tag or the ID of the first filter of the query.
+type testVPCClient struct {
+	aws.EC2Client
+	vpcs  []ec2types.Vpc
+	calls int
+}
+
+func (c *testVPCClient) DescribeVpcs(_ context.Context, input *ec2.DescribeVpcsInput, _ ...func(*ec2.Options)) (*ec2.DescribeVpcsOutput, error) {
+	c.calls++
+	filter := input.Filters[0]
+	output := &ec2.DescribeVpcsOutput{}
+	for _, vpc := range c.vpcs {
+		match := false
+		switch name := awstypes.ToString(filter.Name); name {
+		case "vpc-id":
+			match = awstypes.ToString(vpc.VpcId) == filter.Values[0]
+		case "tag-key":
+			for _, tag := range vpc.Tags {
+				match = match || awstypes.ToString(tag.Key) == filter.Values[0]
+			}
+		default:
+			for _, tag := range vpc.Tags {
+				match = match || ("tag:"+awstypes.ToString(tag.Key) == name && awstypes.ToString(tag.Value) == filter.Values[0])
+			}
+		}
+		if match {
+			output.Vpcs = append(output.Vpcs, vpc)
+		}
+	}
+	return output, nil
+}
+
+func testVPC(id string, tags map[string]string) ec2types.Vpc {
+	vpc := ec2types.Vpc{VpcId: awstypes.String(id)}
+	for k, v := range tags {
+		vpc.Tags = append(vpc.Tags, ec2types.Tag{Key: awstypes.String(k), Value: awstypes.String(v)})
+	}
+	return vpc
+}
+
+func TestDiscoverVPC(t *testing.T) {
+	clusterTag := map[string]string{"kubernetes.io/cluster/test-cluster": "owned"}
+	multipleVPCs := []ec2types.Vpc{
+		testVPC("vpc-1", clusterTag),
+		testVPC("vpc-2", map[string]string{"kubernetes.io/cluster/test-cluster": "shared", "network": "ingress"}),
+	}
+	for _, tc := range []struct {
+		name              string
+		spec              albo.AWSLoadBalancerControllerSpec
+		discoveredVPCID   string
+		vpcs              []ec2types.Vpc
+		expectedVPCID     string
+		expectedCondition metav1.Condition
+		expectedCalls     int
+	}{
+		{
+			name:          "discovered with the cluster tag",
+			vpcs:          []ec2types.Vpc{testVPC("vpc-1", clusterTag)},
+			expectedVPCID: "vpc-1",
+			expectedCondition: metav1.Condition{
+				Type:    VPCDiscoveredCondition,
+				Status:  metav1.ConditionTrue,
+				Reason:  "VPCDiscovered",
+				Message: "VPC vpc-1 was selected with the cluster tag",
+			},
+			expectedCalls: 1,
+		},
+		{
+			name:            "discovered on start up",
+			discoveredVPCID: "vpc-1",
+			expectedVPCID:   "vpc-1",
+			expectedCondition: metav1.Condition{
+				Type:    VPCDiscoveredCondition,
+				Status:  metav1.ConditionTrue,
+				Reason:  "VPCDiscovered",
+				Message: "VPC vpc-1 was selected with the cluster tag",
+			},
+		},
+		{
+			name: "multiple VPCs with the cluster tag",
+			vpcs: multipleVPCs,
+			expectedCondition: metav1.Condition{
+				Type:    VPCDiscoveredCondition,
+				Status:  metav1.ConditionFalse,
+				Reason:  "VPCDiscoveryFailed",
+				Message: `failed to discover VPC of cluster test-cluster, set spec.vpcID or spec.vpcSelector to select the VPC: multiple VPCs with tag "kubernetes.io/cluster/test-cluster" found`,
+			},
+			expectedCalls: 1,
+		},
+		{
+			name:          "selected by ID",
+			spec:          albo.AWSLoadBalancerControllerSpec{VPCID: "vpc-2"},
+			vpcs:          multipleVPCs,
+			expectedVPCID: "vpc-2",
+			expectedCondition: metav1.Condition{
+				Type:    VPCDiscoveredCondition,
+				Status:  metav1.ConditionTrue,
+				Reason:  "VPCDiscovered",
+				Message: "VPC vpc-2 was selected with spec.vpcID",
+			},
+			expectedCalls: 1,
+		},
+		{
+			name: "ID not found",
+			spec: albo.AWSLoadBalancerControllerSpec{VPCID: "vpc-3"},
+			vpcs: multipleVPCs,
+			expectedCondition: metav1.Condition{
+				Type:    VPCDiscoveredCondition,
+				Status:  metav1.ConditionFalse,
+				Reason:  "VPCDiscoveryFailed",
+				Message: `failed to find VPC of spec.vpcID: no VPC with ID "vpc-3" found`,
+			},
+			expectedCalls: 1,
+		},
+		{
+			name:          "selected by tags",
+			spec:          albo.AWSLoadBalancerControllerSpec{VPCSelector: &albo.VPCSelector{MatchTags: map[string]string{"network": "ingress"}}},
+			vpcs:          multipleVPCs,
+			expectedVPCID: "vpc-2",
+			expectedCondition: metav1.Condition{
+				Type:    VPCDiscoveredCondition,
+				Status:  metav1.ConditionTrue,
+				Reason:  "VPCDiscovered",
+				Message: "VPC vpc-2 was selected with spec.vpcSelector",
+			},
+			expectedCalls: 1,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ec2Client := &testVPCClient{vpcs: tc.vpcs}
+			r := &AWSLoadBalancerControllerReconciler{
+				EC2Client:   ec2Client,
+				ClusterName: "test-cluster",
+				VPCID:       tc.discoveredVPCID,
+			}
+			controller := &albo.AWSLoadBalancerController{
+				ObjectMeta: metav1.ObjectMeta{Name: "cluster", Generation: 2},
+				Spec:       tc.spec,
+			}
+			vpcID, source, err := r.discoverVPC(context.Background(), controller)
+			if vpcID != tc.expectedVPCID {
+				t.Errorf("expected VPC %q, got %q", tc.expectedVPCID, vpcID)
+			}
+			if ec2Client.calls != tc.expectedCalls {
+				t.Errorf("expected %d DescribeVpcs calls, got %d", tc.expectedCalls, ec2Client.calls)
+			}
+			tc.expectedCondition.ObservedGeneration = 2
+			condition := vpcDiscoveredCondition(vpcID, source, err, controller.Generation)
+			if condition != tc.expectedCondition {
+				t.Errorf("unexpected condition, expected %+v, got %+v", tc.expectedCondition, condition)
+			}
+		})
+	}
+}
+
+func TestDiscoverVPCFromSpecCached(t *testing.T) {
+	vpcs := []ec2types.Vpc{
+		testVPC("vpc-1", map[string]string{"network": "ingress"}),
+		testVPC("vpc-2", map[string]string{"network": "egress"}),
+	}
+	ec2Client := &testVPCClient{vpcs: vpcs}
+	r := &AWSLoadBalancerControllerReconciler{
+		EC2Client:   ec2Client,
+		ClusterName: "test-cluster",
+	}
+	controller := &albo.AWSLoadBalancerController{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+	}
+	for _, step := range []struct {
+		name          string
+		spec          albo.AWSLoadBalancerControllerSpec
+		platform      *Platform
+		expectedVPCID string
+		expectedCalls int
+	}{
+		{
+			name:          "selected by ID",
+			spec:          albo.AWSLoadBalancerControllerSpec{VPCID: "vpc-1"},
+			expectedVPCID: "vpc-1",
+			expectedCalls: 1,
+		},
+		{
+			name:          "same ID",
+			spec:          albo.AWSLoadBalancerControllerSpec{VPCID: "vpc-1"},
+			expectedVPCID: "vpc-1",
+			expectedCalls: 1,
+		},
+		{
+			name:          "changed ID",
+			spec:          albo.AWSLoadBalancerControllerSpec{VPCID: "vpc-2"},
+			expectedVPCID: "vpc-2",
+			expectedCalls: 2,
+		},
+		{
+			name:          "selected by tags",
+			spec:          albo.AWSLoadBalancerControllerSpec{VPCSelector: &albo.VPCSelector{MatchTags: map[string]string{"network": "ingress"}}},
+			expectedVPCID: "vpc-1",
+			expectedCalls: 3,
+		},
+		{
+			name:          "same tags",
+			spec:          albo.AWSLoadBalancerControllerSpec{VPCSelector: &albo.VPCSelector{MatchTags: map[string]string{"network": "ingress"}}},
+			expectedVPCID: "vpc-1",
+			expectedCalls: 3,
+		},
+		{
+			name:          "changed tags",
+			spec:          albo.AWSLoadBalancerControllerSpec{VPCSelector: &albo.VPCSelector{MatchTags: map[string]string{"network": "egress"}}},
+			expectedVPCID: "vpc-2",
+			expectedCalls: 4,
+		},
+		{
+			name:          "changed platform",
+			spec:          albo.AWSLoadBalancerControllerSpec{VPCSelector: &albo.VPCSelector{MatchTags: map[string]string{"network": "egress"}}},
+			platform:      &Platform{ClusterName: "test-cluster"},
+			expectedVPCID: "vpc-2",
+			expectedCalls: 5,
+		},
+	} {
+		if step.platform != nil {
+			r.platform = step.platform
+		}
+		controller.Spec = step.spec
+		vpcID, _, err := r.discoverVPC(context.Background(), controller)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", step.name, err)
+		}
+		if vpcID != step.expectedVPCID {
+			t.Errorf("%s: expected VPC %q, got %q", step.name, step.expectedVPCID, vpcID)
+		}
+		if ec2Client.calls != step.expectedCalls {
+			t.Errorf("%s: expected %d DescribeVpcs calls, got %d", step.name, step.expectedCalls, ec2Client.calls)
+		}
+	}
+}
@@ -0,0 +1,338 @@
+package awsloadbalancercontroller
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	albo "github.com/openshift/aws-load-balancer-operator/api/v1alpha1"
+)
+
+const (
+	// certManagerInjectCAAnnotationKey requests the injection of the CA of a cert-manager Certificate
+	certManagerInjectCAAnnotationKey = "cert-manager.io/inject-ca-from"
+	// certManagerCertificateNameAnnotation is set by cert-manager on the secrets of its Certificates
+	certManagerCertificateNameAnnotation = "cert-manager.io/certificate-name"
+	// serviceCAOriginatingServiceAnnotation is set by the service CA operator on the serving secrets it issues
+	serviceCAOriginatingServiceAnnotation = "service.beta.openshift.io/originating-service-name"
+	// webhookCertificatesProviderAnnotation is set by the operator on the serving secrets it issues
+	webhookCertificatesProviderAnnotation = "networking.olm.openshift.io/webhook-certificates-provider"
+	// caBundleKey is the key of the CA bundle in the serving secrets issued by cert-manager and the operator
+	caBundleKey = "ca.crt"
+	// webhookCertificateValidity is the validity of the CA and the serving certificate issued by the operator
+	webhookCertificateValidity = 365 * 24 * time.Hour
+)
+
+var (
+	certManagerIssuerGVK      = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Issuer"}
+	certManagerCertificateGVK = schema.GroupVersionKind{Group: "cert-manager.io", Version: "v1", Kind: "Certificate"}
+)
+
+// webhookCertificates is the configuration of the webhooks for the provider of their serving certificate.
+type webhookCertificates struct {
+	// annotations request the injection of the CA bundle into the webhook configurations
+	annotations map[string]string
+	// caBundle is set in the webhook configurations when the certificates are issued by the operator
+	caBundle []byte
+	// renewAfter is the time after which the certificates issued by the operator are renewed
+	renewAfter time.Duration
+}
+
+// webhookCertificatesProvider returns the provider of the webhook certificates, the service CA by default.
+func webhookCertificatesProvider(controller *albo.AWSLoadBalancerController) albo.WebhookCertificatesProvider {
+	if controller.Spec.WebhookCertificates == nil || controller.Spec.WebhookCertificates.Provider == "" {
+		return albo.ServiceCAWebhookCertificatesProvider
+	}
+	return controller.Spec.WebhookCertificates.Provider
+}
+
+// ensureWebhookCertificates ensures the serving certificate of the webhooks in the serving secret with the provider of
+// the spec. The service CA issues the certificate from the annotation of the service. The cert-manager resources are
+// applied as unstructured objects as cert-manager is optional, they are not watched. When the provider changes, the
+// serving secret issued by the previous provider and the cert-manager resources are deleted so that the secret is
+// issued by the new provider. The certificates are issued for the name of the service so that they can be ensured
+// before the deployment which mounts the serving secret, the service selects the pods of the deployment.
+func (r *AWSLoadBalancerControllerReconciler) ensureWebhookCertificates(ctx context.Context, namespace string, controller *albo.AWSLoadBalancerController, serviceName types.NamespacedName, servingSecretName string) (*webhookCertificates, error) {
+	provider := webhookCertificatesProvider(controller)
+	name := fmt.Sprintf("%s-%s", controllerResourcePrefix, controller.Name)
+
+	var secret corev1.Secret
+	if err := r.Get(ctx, types.NamespacedName{Namespace: namespace, Name: servingSecretName}, &secret); err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get serving secret %s/%s: %w", namespace, servingSecretName, err)
+		}
+	} else if issuer, ok := servingSecretProvider(&secret); ok && issuer != provider {
+		log.FromContext(ctx).Info("deleting serving secret of previous webhook certificates provider", "secret", servingSecretName, "provider", issuer)
+		if issuer == albo.CertManagerWebhookCertificatesProvider {
+			if err := r.deleteCertManagerResources(ctx, namespace, name); err != nil {
+				return nil, err
+			}
+		}
+		if err := r.Delete(ctx, &secret); err != nil && !errors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to delete serving secret %s/%s: %w", namespace, servingSecretName, err)
+		}
+		secret = corev1.Secret{}
+	}
+
+	switch provider {
+	case albo.CertManagerWebhookCertificatesProvider:
+		for _, desired := range desiredCertManagerResources(name, namespace, serviceName, servingSecretName) {
+			if err := controllerutil.SetControllerReference(controller, desired, r.Scheme); err != nil {
+				return nil, fmt.Errorf("failed to set owner reference on %s %s: %w", desired.GetKind(), desired.GetName(), err)
+			}
+			if err := r.apply(ctx, desired); err != nil {
+				return nil, fmt.Errorf("failed to apply %s %s: %w", desired.GetKind(), desired.GetName(), err)
+			}
+		}
+		return &webhookCertificates{
+			annotations: map[string]string{certManagerInjectCAAnnotationKey: fmt.Sprintf("%s/%s", namespace, name)},
+		}, nil
+	case albo.SelfWebhookCertificatesProvider:
+		return r.ensureSelfSignedCertificates(ctx, namespace, controller, serviceName, servingSecretName, &secret)
+	default:
+		return &webhookCertificates{
+			annotations: map[string]string{injectCABundleAnnotationKey: injectCABundleAnnotationValue},
+		}, nil
+	}
+}
+
+// servingSecretProvider returns the provider which issued the serving secret from its annotations.
+func servingSecretProvider(secret *corev1.Secret) (albo.WebhookCertificatesProvider, bool) {
+	if provider, ok := secret.Annotations[webhookCertificatesProviderAnnotation]; ok {
+		return albo.WebhookCertificatesProvider(provider), true
+	}
+	if _, ok := secret.Annotations[certManagerCertificateNameAnnotation]; ok {
+		return albo.CertManagerWebhookCertificatesProvider, true
+	}
+	if _, ok := secret.Annotations[serviceCAOriginatingServiceAnnotation]; ok {
+		return albo.ServiceCAWebhookCertificatesProvider, true
+	}
+	return "", false
+}
+
+// deleteCertManagerResources deletes the Certificate and the Issuer of the webhooks. The resources are considered
+// deleted when cert-manager is not installed.
+func (r *AWSLoadBalancerControllerReconciler) deleteCertManagerResources(ctx context.Context, namespace, name string) error {
+	for _, gvk := range []schema.GroupVersionKind{certManagerCertificateGVK, certManagerIssuerGVK} {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(gvk)
+		obj.SetNamespace(namespace)
+		obj.SetName(name)
+		if err := r.Delete(ctx, obj); err != nil && !errors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+			return fmt.Errorf("failed to delete %s %s/%s: %w", gvk.Kind, namespace, name, err)
+		}
+	}
+	return nil
+}
+
+// desiredCertManagerResources returns the self-signed Issuer and the Certificate of the webhooks. The CA bundle of
+// the self-signed certificate is injected into the webhook configurations by the cert-manager CA injector.
+func desiredCertManagerResources(name, namespace string, serviceName types.NamespacedName, servingSecretName string) []*unstructured.Unstructured {
+	issuer := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"selfSigned": map[string]interface{}{},
+		},
+	}}
+	issuer.SetGroupVersionKind(certManagerIssuerGVK)
+	issuer.SetNamespace(namespace)
+	issuer.SetName(name)
+
+	var dnsNames []interface{}
+	for _, dnsName := range serviceDNSNames(serviceName) {
+		dnsNames = append(dnsNames, dnsName)
+	}
+	certificate := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{
+			"secretName": servingSecretName,
+			"dnsNames":   dnsNames,
+			"issuerRef": map[string]interface{}{
+				"kind": certManagerIssuerGVK.Kind,
+				"name": name,
+			},
+		},
+	}}
+	certificate.SetGroupVersionKind(certManagerCertificateGVK)
+	certificate.SetNamespace(namespace)
+	certificate.SetName(name)
+	return []*unstructured.Unstructured{issuer, certificate}
+}
+
+// serviceDNSNames returns the DNS names of the service, the API server calls the webhooks with the first one.
+func serviceDNSNames(serviceName types.NamespacedName) []string {
+	return []string{
+		fmt.Sprintf("%s.%s.svc", serviceName.Name, serviceName.Namespace),
+		fmt.Sprintf("%s.%s.svc.cluster.local", serviceName.Name, serviceName.Namespace),
+	}
+}
+
+// ensureSelfSignedCertificates ensures the serving certificate issued by the operator in the serving secret. A new CA
+// and serving certificate are generated when the secret doesn't hold a valid certificate for the service or when two
+// thirds of the validity of the certificate have passed. The previous CA is kept in the CA bundle so that the API
+// server trusts the replicas which still serve the previous certificate.
+func (r *AWSLoadBalancerControllerReconciler) ensureSelfSignedCertificates(ctx context.Context, namespace string, controller *albo.AWSLoadBalancerController, serviceName types.NamespacedName, servingSecretName string, current *corev1.Secret) (*webhookCertificates, error) {
+	now := time.Now()
+	if renewAt, ok := selfSignedCertificateRenewal(current, serviceDNSNames(serviceName)); ok && now.Before(renewAt) {
+		return &webhookCertificates{caBundle: current.Data[caBundleKey], renewAfter: renewAt.Sub(now)}, nil
+	}
+
+	log.FromContext(ctx).Info("issuing webhook serving certificate", "secret", servingSecretName)
+	data, err := generateServingCertificate(serviceDNSNames(serviceName), now, current.Data[caBundleKey])
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate webhook serving certificate: %w", err)
+	}
+	desired := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      servingSecretName,
+			Namespace: namespace,
+			Annotations: map[string]string{
+				webhookCertificatesProviderAnnotation: string(albo.SelfWebhookCertificatesProvider),
+			},
+		},
+		Type: corev1.SecretTypeTLS,
+		Data: data,
+	}
+	if err := controllerutil.SetControllerReference(controller, desired, r.Scheme); err != nil {
+		return nil, fmt.Errorf("failed to set owner reference on serving secret %s: %w", servingSecretName, err)
+	}
+	if err := r.apply(ctx, desired); err != nil {
+		return nil, fmt.Errorf("failed to apply serving secret %s: %w", servingSecretName, err)
+	}
+	return &webhookCertificates{caBundle: data[caBundleKey], renewAfter: webhookCertificateValidity * 2 / 3}, nil
+}
+
+// selfSignedCertificateRenewal returns the time at which the serving certificate of the secret must be renewed, it
+// returns false if the secret doesn't hold a certificate issued by the operator for the given DNS names.
+func selfSignedCertificateRenewal(secret *corev1.Secret, dnsNames []string) (time.Time, bool) {
+	if secret.Annotations[webhookCertificatesProviderAnnotation] != string(albo.SelfWebhookCertificatesProvider) || len(secret.Data[caBundleKey]) == 0 {
+		return time.Time{}, false
+	}
+	cert, err := tlsKeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+	if err != nil {
+		return time.Time{}, false
+	}
+	for _, dnsName := range dnsNames {
+		if err := cert.VerifyHostname(dnsName); err != nil {
+			return time.Time{}, false
+		}
+	}
+	return cert.NotBefore.Add(cert.NotAfter.Sub(cert.NotBefore) * 2 / 3), true
+}
+
+// generateServingCertificate generates a CA and a serving certificate for the given DNS names signed by the CA. The
+// returned secret data has the certificate, its key and the CA bundle with the new CA and the first CA of the
+// previous bundle if it's still valid.
+func generateServingCertificate(dnsNames []string, now time.Time, previousCABundle []byte) (map[string][]byte, error) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	caSerialNumber, err := randomSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+	caTemplate := &x509.Certificate{
+		SerialNumber:          caSerialNumber,
+		Subject:               pkix.Name{CommonName: fmt.Sprintf("%s-webhook-ca@%d", controllerResourcePrefix, now.Unix())},
+		NotBefore:             now.Add(-time.Minute),
+		NotAfter:              now.Add(webhookCertificateValidity),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		return nil, err
+	}
+	ca, err := x509.ParseCertificate(caDER)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	serialNumber, err := randomSerialNumber()
+	if err != nil {
+		return nil, err
+	}
+	template := &x509.Certificate{
+		SerialNumber: serialNumber,
+		Subject:      pkix.Name{CommonName: dnsNames[0]},
+		DNSNames:     dnsNames,
+		NotBefore:    now.Add(-time.Minute),
+		NotAfter:     now.Add(webhookCertificateValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, ca, &key.PublicKey, caKey)
+	if err != nil {
+		return nil, err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	caBundle := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: caDER})
+	if block, _ := pem.Decode(previousCABundle); block != nil {
+		if previous, err := x509.ParseCertificate(block.Bytes); err == nil && now.Before(previous.NotAfter) {
+			caBundle = append(caBundle, pem.EncodeToMemory(block)...)
+		}
+	}
+	return map[string][]byte{
+		corev1.TLSCertKey:       pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}),
+		corev1.TLSPrivateKeyKey: pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+		caBundleKey:             caBundle,
+	}, nil
+}
+
+// tlsKeyPair returns the certificate of the key pair after checking that the key matches the certificate.
+func tlsKeyPair(certPEM, keyPEM []byte) (*x509.Certificate, error) {
+	certBlock, _ := pem.Decode(certPEM)
+	keyBlock, _ := pem.Decode(keyPEM)
+	if certBlock == nil || keyBlock == nil {
+		return nil, fmt.Errorf("failed to decode key pair")
+	}
+	cert, err := x509.ParseCertificate(certBlock.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParseECPrivateKey(keyBlock.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	public, ok := cert.PublicKey.(*ecdsa.PublicKey)
+	if !ok || !public.Equal(&key.PublicKey) {
+		return nil, fmt.Errorf("private key doesn't match the certificate")
+	}
+	return cert, nil
+}
+
+// randomSerialNumber returns a random 128 bits serial number so that the certificates issued by the operators of
+// different clusters or at the same time don't share a serial number.
+func randomSerialNumber() (*big.Int, error) {
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate serial number: %w", err)
+	}
+	return serialNumber, nil
+}
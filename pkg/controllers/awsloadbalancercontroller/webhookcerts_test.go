@@ -0,0 +1,285 @@
+package awsloadbalancercontroller
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	albo "github.com/openshift/aws-load-balancer-operator/api/v1alpha1"
+	"github.com/openshift/aws-load-balancer-operator/pkg/controllers/utils/test"
+)
+
+func TestEnsureWebhookCertificates(t *testing.T) {
+	name := controllerResourcePrefix + "-cluster"
+	secretName := servingSecretPrefix + "cluster"
+	serviceName := types.NamespacedName{Name: name, Namespace: test.OperatorNamespace}
+	dnsNames := serviceDNSNames(serviceName)
+
+	selfSignedSecret := func(issuedAt time.Time) *corev1.Secret {
+		data, err := generateServingCertificate(dnsNames, issuedAt, nil)
+		if err != nil {
+			t.Fatalf("failed to generate serving certificate: %v", err)
+		}
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        secretName,
+				Namespace:   test.OperatorNamespace,
+				Annotations: map[string]string{webhookCertificatesProviderAnnotation: string(albo.SelfWebhookCertificatesProvider)},
+			},
+			Type: corev1.SecretTypeTLS,
+			Data: data,
+		}
+	}
+	certManagerResources := func() []client.Object {
+		var objs []client.Object
+		for _, obj := range desiredCertManagerResources(name, test.OperatorNamespace, serviceName, secretName) {
+			objs = append(objs, obj)
+		}
+		return objs
+	}
+	validSecret := selfSignedSecret(time.Now().Add(-time.Hour))
+	expiringSecret := selfSignedSecret(time.Now().Add(-300 * 24 * time.Hour))
+
+	for _, tc := range []struct {
+		name            string
+		provider        albo.WebhookCertificatesProvider
+		existingObjects []client.Object
+		// appliedObjects are applied by the operator before the certificates are ensured
+		appliedObjects        []client.Object
+		expectedAnnotations   map[string]string
+		expectedCertManager   bool
+		expectedSecretDeleted bool
+		// expectedCABundle is the expected CA bundle, the CA bundle is only checked when it's issued by the operator
+		expectedCABundle []byte
+		// expectedCAs is the expected number of CAs in the CA bundle issued by the operator
+		expectedCAs int
+	}{
+		{
+			name:                "default provider",
+			expectedAnnotations: map[string]string{injectCABundleAnnotationKey: injectCABundleAnnotationValue},
+		},
+		{
+			name:     "service CA",
+			provider: albo.ServiceCAWebhookCertificatesProvider,
+			existingObjects: []client.Object{
+				&corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+					Name:        secretName,
+					Namespace:   test.OperatorNamespace,
+					Annotations: map[string]string{serviceCAOriginatingServiceAnnotation: name},
+				}},
+			},
+			expectedAnnotations: map[string]string{injectCABundleAnnotationKey: injectCABundleAnnotationValue},
+		},
+		{
+			name:                "cert-manager",
+			provider:            albo.CertManagerWebhookCertificatesProvider,
+			expectedAnnotations: map[string]string{certManagerInjectCAAnnotationKey: test.OperatorNamespace + "/" + name},
+			expectedCertManager: true,
+		},
+		{
+			name:     "provider changed from the service CA to cert-manager",
+			provider: albo.CertManagerWebhookCertificatesProvider,
+			existingObjects: []client.Object{
+				&corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+					Name:        secretName,
+					Namespace:   test.OperatorNamespace,
+					Annotations: map[string]string{serviceCAOriginatingServiceAnnotation: name},
+				}},
+			},
+			expectedAnnotations:   map[string]string{certManagerInjectCAAnnotationKey: test.OperatorNamespace + "/" + name},
+			expectedCertManager:   true,
+			expectedSecretDeleted: true,
+		},
+		{
+			name:        "self signed, new certificate",
+			provider:    albo.SelfWebhookCertificatesProvider,
+			expectedCAs: 1,
+		},
+		{
+			name:             "self signed, valid certificate",
+			provider:         albo.SelfWebhookCertificatesProvider,
+			existingObjects:  []client.Object{validSecret},
+			expectedCABundle: validSecret.Data[caBundleKey],
+			expectedCAs:      1,
+		},
+		{
+			name:            "self signed, expiring certificate",
+			provider:        albo.SelfWebhookCertificatesProvider,
+			existingObjects: []client.Object{expiringSecret},
+			expectedCAs:     2,
+		},
+		{
+			name:     "self signed, certificate for another service",
+			provider: albo.SelfWebhookCertificatesProvider,
+			existingObjects: []client.Object{
+				func() *corev1.Secret {
+					secret := selfSignedSecret(time.Now())
+					data, err := generateServingCertificate([]string{"other.test-namespace.svc"}, time.Now(), nil)
+					if err != nil {
+						t.Fatalf("failed to generate serving certificate: %v", err)
+					}
+					secret.Data = data
+					return secret
+				}(),
+			},
+			expectedCAs: 2,
+		},
+		{
+			name:     "provider changed from cert-manager to self signed",
+			provider: albo.SelfWebhookCertificatesProvider,
+			existingObjects: []client.Object{
+				&corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+					Name:        secretName,
+					Namespace:   test.OperatorNamespace,
+					Annotations: map[string]string{certManagerCertificateNameAnnotation: name},
+				}},
+			},
+			appliedObjects: certManagerResources(),
+			expectedCAs:    1,
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			testClient := test.NewApplyClient(fake.NewClientBuilder().WithScheme(test.Scheme).WithObjects(tc.existingObjects...).Build())
+			r := &AWSLoadBalancerControllerReconciler{
+				Client: testClient,
+				Scheme: test.Scheme,
+			}
+			for _, obj := range tc.appliedObjects {
+				if err := r.apply(ctx, obj); err != nil {
+					t.Fatalf("failed to apply %s: %v", obj.GetName(), err)
+				}
+			}
+			controller := &albo.AWSLoadBalancerController{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}}
+			if tc.provider != "" {
+				controller.Spec.WebhookCertificates = &albo.AWSLoadBalancerWebhookCertificatesConfig{Provider: tc.provider}
+			}
+			certs, err := r.ensureWebhookCertificates(ctx, test.OperatorNamespace, controller, serviceName, secretName)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := cmp.Diff(tc.expectedAnnotations, certs.annotations); diff != "" {
+				t.Errorf("unexpected webhook annotations\n%s", diff)
+			}
+
+			for _, gvk := range []string{certManagerIssuerGVK.Kind, certManagerCertificateGVK.Kind} {
+				obj := &unstructured.Unstructured{}
+				obj.SetGroupVersionKind(certManagerIssuerGVK.GroupVersion().WithKind(gvk))
+				err := testClient.Get(ctx, types.NamespacedName{Namespace: test.OperatorNamespace, Name: name}, obj)
+				if err != nil && !errors.IsNotFound(err) {
+					t.Fatalf("failed to get %s: %v", gvk, err)
+				}
+				if exists := err == nil; exists != tc.expectedCertManager {
+					t.Fatalf("expected %s to exist %t, got %t", gvk, tc.expectedCertManager, exists)
+				}
+				if exists := err == nil; exists {
+					if diff := cmp.Diff(testControllerReferences("cluster"), obj.GetOwnerReferences()); diff != "" {
+						t.Errorf("unexpected %s owner references\n%s", gvk, diff)
+					}
+				}
+			}
+
+			var secret corev1.Secret
+			err = testClient.Get(ctx, types.NamespacedName{Namespace: test.OperatorNamespace, Name: secretName}, &secret)
+			if err != nil && !errors.IsNotFound(err) {
+				t.Fatalf("failed to get serving secret: %v", err)
+			}
+			if tc.expectedSecretDeleted && err == nil {
+				t.Errorf("expected serving secret of the previous provider to be deleted")
+			}
+			if tc.provider != albo.SelfWebhookCertificatesProvider {
+				if certs.caBundle != nil || certs.renewAfter != 0 {
+					t.Errorf("unexpected CA bundle or renewal for provider %q", tc.provider)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("failed to get serving secret: %v", err)
+			}
+			if secret.Annotations[webhookCertificatesProviderAnnotation] != string(albo.SelfWebhookCertificatesProvider) {
+				t.Errorf("expected serving secret to be annotated with the provider, got %v", secret.Annotations)
+			}
+			if secret.Type != corev1.SecretTypeTLS {
+				t.Errorf("expected serving secret of type %s, got %s", corev1.SecretTypeTLS, secret.Type)
+			}
+			if diff := cmp.Diff(secret.Data[caBundleKey], certs.caBundle); diff != "" {
+				t.Errorf("expected the CA bundle of the serving secret\n%s", diff)
+			}
+			if tc.expectedCABundle != nil {
+				if diff := cmp.Diff(tc.expectedCABundle, certs.caBundle); diff != "" {
+					t.Errorf("unexpected CA bundle\n%s", diff)
+				}
+			}
+			if certs.renewAfter <= 0 || certs.renewAfter > webhookCertificateValidity*2/3 {
+				t.Errorf("unexpected renewal after %s", certs.renewAfter)
+			}
+
+			roots := x509.NewCertPool()
+			var cas int
+			for rest := certs.caBundle; ; {
+				var block *pem.Block
+				block, rest = pem.Decode(rest)
+				if block == nil {
+					break
+				}
+				ca, err := x509.ParseCertificate(block.Bytes)
+				if err != nil {
+					t.Fatalf("failed to parse CA: %v", err)
+				}
+				roots.AddCert(ca)
+				cas++
+			}
+			if cas != tc.expectedCAs {
+				t.Errorf("expected %d CAs in the CA bundle, got %d", tc.expectedCAs, cas)
+			}
+			cert, err := tlsKeyPair(secret.Data[corev1.TLSCertKey], secret.Data[corev1.TLSPrivateKeyKey])
+			if err != nil {
+				t.Fatalf("invalid serving key pair: %v", err)
+			}
+			for _, dnsName := range dnsNames {
+				if _, err := cert.Verify(x509.VerifyOptions{DNSName: dnsName, Roots: roots}); err != nil {
+					t.Errorf("failed to verify serving certificate for %s: %v", dnsName, err)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateServingCertificateSerialNumbers(t *testing.T) {
+	now := time.Now()
+	serialNumbers := map[string]struct{}{}
+	// the certificates issued at the same time must not share a serial number
+	for i := 0; i < 2; i++ {
+		data, err := generateServingCertificate([]string{"test.test-namespace.svc"}, now, nil)
+		if err != nil {
+			t.Fatalf("failed to generate serving certificate: %v", err)
+		}
+		for _, key := range []string{caBundleKey, corev1.TLSCertKey} {
+			block, _ := pem.Decode(data[key])
+			if block == nil {
+				t.Fatalf("no certificate in %s", key)
+			}
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				t.Fatalf("failed to parse certificate of %s: %v", key, err)
+			}
+			if _, ok := serialNumbers[cert.SerialNumber.String()]; ok {
+				t.Errorf("serial number %s of %s is reused", cert.SerialNumber, key)
+			}
+			serialNumbers[cert.SerialNumber.String()] = struct{}{}
+		}
+	}
+}
@@ -17,368 +17,6 @@ import (
 	"github.com/openshift/aws-load-balancer-operator/pkg/controllers/utils/test"
 )
 
-func TestAreValidatingWebhooksSame(t *testing.T) {
-	for _, tc := range []struct {
-		name           string
-		currentVWs     []arv1.ValidatingWebhook
-		desiredVWs     []arv1.ValidatingWebhook
-		expectedResult bool
-	}{
-		{
-			name:           "second empty",
-			currentVWs:     []arv1.ValidatingWebhook{{}},
-			desiredVWs:     []arv1.ValidatingWebhook{},
-			expectedResult: true,
-		},
-		{
-			name:           "first empty",
-			currentVWs:     []arv1.ValidatingWebhook{},
-			desiredVWs:     []arv1.ValidatingWebhook{{}},
-			expectedResult: true,
-		},
-		{
-			name:       "same webhooks, different order",
-			currentVWs: []arv1.ValidatingWebhook{{Name: "a"}, {Name: "b"}},
-			desiredVWs: []arv1.ValidatingWebhook{{Name: "b"}, {Name: "a"}},
-		},
-		{
-			name: "same service",
-			currentVWs: []arv1.ValidatingWebhook{
-				{
-					Name: "a", ClientConfig: arv1.WebhookClientConfig{
-						Service: &arv1.ServiceReference{
-							Namespace: "test-namespace",
-							Name:      "test-service",
-							Path:      pointer.StringPtr("/test"),
-							Port:      pointer.Int32Ptr(8080),
-						},
-					},
-				},
-			},
-			desiredVWs: []arv1.ValidatingWebhook{
-				{
-					Name: "a", ClientConfig: arv1.WebhookClientConfig{
-						Service: &arv1.ServiceReference{
-							Namespace: "test-namespace",
-							Name:      "test-service",
-							Path:      pointer.StringPtr("/test"),
-							Port:      pointer.Int32Ptr(8080),
-						},
-					},
-				},
-			},
-		},
-		{
-			name: "service path changed",
-			currentVWs: []arv1.ValidatingWebhook{
-				{
-					Name: "a", ClientConfig: arv1.WebhookClientConfig{
-						Service: &arv1.ServiceReference{
-							Namespace: "test-namespace",
-							Name:      "test-service",
-							Path:      pointer.StringPtr("/test-old"),
-							Port:      pointer.Int32Ptr(8080),
-						},
-					},
-				},
-			},
-			desiredVWs: []arv1.ValidatingWebhook{
-				{
-					Name: "a", ClientConfig: arv1.WebhookClientConfig{
-						Service: &arv1.ServiceReference{
-							Namespace: "test-namespace",
-							Name:      "test-service",
-							Path:      pointer.StringPtr("/test-new"),
-							Port:      pointer.Int32Ptr(8080),
-						},
-					},
-				},
-			},
-			expectedResult: true,
-		},
-		{
-			name:       "desired side effect is nil",
-			currentVWs: []arv1.ValidatingWebhook{{Name: "a", SideEffects: sideEffectPtr(arv1.SideEffectClassNone)}},
-			desiredVWs: []arv1.ValidatingWebhook{{Name: "a"}},
-		},
-		{
-			name:           "current side effect is nil",
-			currentVWs:     []arv1.ValidatingWebhook{{Name: "a"}},
-			desiredVWs:     []arv1.ValidatingWebhook{{Name: "a", SideEffects: sideEffectPtr(arv1.SideEffectClassNone)}},
-			expectedResult: true,
-		},
-		{
-			name:           "current and desired side effects differ",
-			currentVWs:     []arv1.ValidatingWebhook{{Name: "a", SideEffects: sideEffectPtr(arv1.SideEffectClassSome)}},
-			desiredVWs:     []arv1.ValidatingWebhook{{Name: "a", SideEffects: sideEffectPtr(arv1.SideEffectClassNone)}},
-			expectedResult: true,
-		},
-		{
-			name:       "desired match policy is nil",
-			currentVWs: []arv1.ValidatingWebhook{{Name: "a", MatchPolicy: matchPolicyPtr(arv1.Equivalent)}},
-			desiredVWs: []arv1.ValidatingWebhook{{Name: "a"}},
-		},
-		{
-			name:           "current match policy is nil",
-			currentVWs:     []arv1.ValidatingWebhook{{Name: "a"}},
-			desiredVWs:     []arv1.ValidatingWebhook{{Name: "a", MatchPolicy: matchPolicyPtr(arv1.Equivalent)}},
-			expectedResult: true,
-		},
-		{
-			name:           "current and desired match policy differ",
-			currentVWs:     []arv1.ValidatingWebhook{{Name: "a", MatchPolicy: matchPolicyPtr(arv1.Exact)}},
-			desiredVWs:     []arv1.ValidatingWebhook{{Name: "a", MatchPolicy: matchPolicyPtr(arv1.Equivalent)}},
-			expectedResult: true,
-		},
-		{
-			name:       "desired failure policy is nil",
-			currentVWs: []arv1.ValidatingWebhook{{Name: "a", FailurePolicy: failurePolicyPtr(arv1.Fail)}},
-			desiredVWs: []arv1.ValidatingWebhook{{Name: "a"}},
-		},
-		{
-			name:           "current failure policy is nil",
-			currentVWs:     []arv1.ValidatingWebhook{{Name: "a"}},
-			desiredVWs:     []arv1.ValidatingWebhook{{Name: "a", FailurePolicy: failurePolicyPtr(arv1.Fail)}},
-			expectedResult: true,
-		},
-		{
-			name:           "current and desired failure policy differ",
-			currentVWs:     []arv1.ValidatingWebhook{{Name: "a", FailurePolicy: failurePolicyPtr(arv1.Ignore)}},
-			desiredVWs:     []arv1.ValidatingWebhook{{Name: "a", FailurePolicy: failurePolicyPtr(arv1.Fail)}},
-			expectedResult: true,
-		},
-		{
-			name:       "rules have changed",
-			currentVWs: []arv1.ValidatingWebhook{{Name: "a", Rules: []arv1.RuleWithOperations{}}},
-			desiredVWs: []arv1.ValidatingWebhook{{Name: "a", Rules: []arv1.RuleWithOperations{
-				{
-					Operations: []arv1.OperationType{arv1.Create},
-					Rule: arv1.Rule{
-						APIGroups:   []string{"apps"},
-						APIVersions: []string{"v1"},
-						Resources:   []string{"deployments"},
-						Scope:       scopeTypePtr(arv1.AllScopes),
-					},
-				},
-			}}},
-			expectedResult: true,
-		},
-		{
-			name: "rules are the same",
-			currentVWs: []arv1.ValidatingWebhook{{Name: "a", Rules: []arv1.RuleWithOperations{
-				{
-					Operations: []arv1.OperationType{arv1.Create},
-					Rule: arv1.Rule{
-						APIGroups:   []string{"apps"},
-						APIVersions: []string{"v1"},
-						Resources:   []string{"deployments"},
-						Scope:       scopeTypePtr(arv1.AllScopes),
-					},
-				},
-			}}},
-			desiredVWs: []arv1.ValidatingWebhook{{Name: "a", Rules: []arv1.RuleWithOperations{
-				{
-					Operations: []arv1.OperationType{arv1.Create},
-					Rule: arv1.Rule{
-						APIGroups:   []string{"apps"},
-						APIVersions: []string{"v1"},
-						Resources:   []string{"deployments"},
-						Scope:       scopeTypePtr(arv1.AllScopes),
-					},
-				},
-			}}},
-		},
-	} {
-		t.Run(tc.name, func(t *testing.T) {
-			result := haveValidatingWebhooksChanged(tc.currentVWs, tc.desiredVWs)
-			if result != tc.expectedResult {
-				t.Errorf("expected result %v, got %v", tc.expectedResult, result)
-			}
-		})
-	}
-}
-
-func TestAreMutatingWebhooksSame(t *testing.T) {
-	for _, tc := range []struct {
-		name           string
-		currentVWs     []arv1.MutatingWebhook
-		desiredVWs     []arv1.MutatingWebhook
-		expectedResult bool
-	}{
-		{
-			name:           "second empty",
-			currentVWs:     []arv1.MutatingWebhook{{}},
-			desiredVWs:     []arv1.MutatingWebhook{},
-			expectedResult: true,
-		},
-		{
-			name:           "first empty",
-			currentVWs:     []arv1.MutatingWebhook{},
-			desiredVWs:     []arv1.MutatingWebhook{{}},
-			expectedResult: true,
-		},
-		{
-			name:       "same webhooks, different order",
-			currentVWs: []arv1.MutatingWebhook{{Name: "a"}, {Name: "b"}},
-			desiredVWs: []arv1.MutatingWebhook{{Name: "b"}, {Name: "a"}},
-		},
-		{
-			name: "same service",
-			currentVWs: []arv1.MutatingWebhook{
-				{
-					Name: "a", ClientConfig: arv1.WebhookClientConfig{
-						Service: &arv1.ServiceReference{
-							Namespace: "test-namespace",
-							Name:      "test-service",
-							Path:      pointer.StringPtr("/test"),
-							Port:      pointer.Int32Ptr(8080),
-						},
-					},
-				},
-			},
-			desiredVWs: []arv1.MutatingWebhook{
-				{
-					Name: "a", ClientConfig: arv1.WebhookClientConfig{
-						Service: &arv1.ServiceReference{
-							Namespace: "test-namespace",
-							Name:      "test-service",
-							Path:      pointer.StringPtr("/test"),
-							Port:      pointer.Int32Ptr(8080),
-						},
-					},
-				},
-			},
-		},
-		{
-			name: "service path changed",
-			currentVWs: []arv1.MutatingWebhook{
-				{
-					Name: "a", ClientConfig: arv1.WebhookClientConfig{
-						Service: &arv1.ServiceReference{
-							Namespace: "test-namespace",
-							Name:      "test-service",
-							Path:      pointer.StringPtr("/test-old"),
-							Port:      pointer.Int32Ptr(8080),
-						},
-					},
-				},
-			},
-			desiredVWs: []arv1.MutatingWebhook{
-				{
-					Name: "a", ClientConfig: arv1.WebhookClientConfig{
-						Service: &arv1.ServiceReference{
-							Namespace: "test-namespace",
-							Name:      "test-service",
-							Path:      pointer.StringPtr("/test-new"),
-							Port:      pointer.Int32Ptr(8080),
-						},
-					},
-				},
-			},
-			expectedResult: true,
-		},
-		{
-			name:       "desired side effect is nil",
-			currentVWs: []arv1.MutatingWebhook{{Name: "a", SideEffects: sideEffectPtr(arv1.SideEffectClassNone)}},
-			desiredVWs: []arv1.MutatingWebhook{{Name: "a"}},
-		},
-		{
-			name:           "current side effect is nil",
-			currentVWs:     []arv1.MutatingWebhook{{Name: "a"}},
-			desiredVWs:     []arv1.MutatingWebhook{{Name: "a", SideEffects: sideEffectPtr(arv1.SideEffectClassNone)}},
-			expectedResult: true,
-		},
-		{
-			name:           "current and desired side effects differ",
-			currentVWs:     []arv1.MutatingWebhook{{Name: "a", SideEffects: sideEffectPtr(arv1.SideEffectClassSome)}},
-			desiredVWs:     []arv1.MutatingWebhook{{Name: "a", SideEffects: sideEffectPtr(arv1.SideEffectClassNone)}},
-			expectedResult: true,
-		},
-		{
-			name:       "desired match policy is nil",
-			currentVWs: []arv1.MutatingWebhook{{Name: "a", MatchPolicy: matchPolicyPtr(arv1.Equivalent)}},
-			desiredVWs: []arv1.MutatingWebhook{{Name: "a"}},
-		},
-		{
-			name:           "current match policy is nil",
-			currentVWs:     []arv1.MutatingWebhook{{Name: "a"}},
-			desiredVWs:     []arv1.MutatingWebhook{{Name: "a", MatchPolicy: matchPolicyPtr(arv1.Equivalent)}},
-			expectedResult: true,
-		},
-		{
-			name:           "current and desired match policy differ",
-			currentVWs:     []arv1.MutatingWebhook{{Name: "a", MatchPolicy: matchPolicyPtr(arv1.Exact)}},
-			desiredVWs:     []arv1.MutatingWebhook{{Name: "a", MatchPolicy: matchPolicyPtr(arv1.Equivalent)}},
-			expectedResult: true,
-		},
-		{
-			name:       "desired failure policy is nil",
-			currentVWs: []arv1.MutatingWebhook{{Name: "a", FailurePolicy: failurePolicyPtr(arv1.Fail)}},
-			desiredVWs: []arv1.MutatingWebhook{{Name: "a"}},
-		},
-		{
-			name:           "current failure policy is nil",
-			currentVWs:     []arv1.MutatingWebhook{{Name: "a"}},
-			desiredVWs:     []arv1.MutatingWebhook{{Name: "a", FailurePolicy: failurePolicyPtr(arv1.Fail)}},
-			expectedResult: true,
-		},
-		{
-			name:           "current and desired failure policy differ",
-			currentVWs:     []arv1.MutatingWebhook{{Name: "a", FailurePolicy: failurePolicyPtr(arv1.Ignore)}},
-			desiredVWs:     []arv1.MutatingWebhook{{Name: "a", FailurePolicy: failurePolicyPtr(arv1.Fail)}},
-			expectedResult: true,
-		},
-		{
-			name:       "rules have changed",
-			currentVWs: []arv1.MutatingWebhook{{Name: "a", Rules: []arv1.RuleWithOperations{}}},
-			desiredVWs: []arv1.MutatingWebhook{{Name: "a", Rules: []arv1.RuleWithOperations{
-				{
-					Operations: []arv1.OperationType{arv1.Create},
-					Rule: arv1.Rule{
-						APIGroups:   []string{"apps"},
-						APIVersions: []string{"v1"},
-						Resources:   []string{"deployments"},
-						Scope:       scopeTypePtr(arv1.AllScopes),
-					},
-				},
-			}}},
-			expectedResult: true,
-		},
-		{
-			name: "rules are the same",
-			currentVWs: []arv1.MutatingWebhook{{Name: "a", Rules: []arv1.RuleWithOperations{
-				{
-					Operations: []arv1.OperationType{arv1.Create},
-					Rule: arv1.Rule{
-						APIGroups:   []string{"apps"},
-						APIVersions: []string{"v1"},
-						Resources:   []string{"deployments"},
-						Scope:       scopeTypePtr(arv1.AllScopes),
-					},
-				},
-			}}},
-			desiredVWs: []arv1.MutatingWebhook{{Name: "a", Rules: []arv1.RuleWithOperations{
-				{
-					Operations: []arv1.OperationType{arv1.Create},
-					Rule: arv1.Rule{
-						APIGroups:   []string{"apps"},
-						APIVersions: []string{"v1"},
-						Resources:   []string{"deployments"},
-						Scope:       scopeTypePtr(arv1.AllScopes),
-					},
-				},
-			}}},
-		},
-	} {
-		t.Run(tc.name, func(t *testing.T) {
-			result := haveMutatingWebhooksChanged(tc.currentVWs, tc.desiredVWs)
-			if result != tc.expectedResult {
-				t.Errorf("expected result %v, got %v", tc.expectedResult, result)
-			}
-		})
-	}
-}
-
 func testValidatingWebhooks(serviceName, serviceNamespace string) []arv1.ValidatingWebhook {
 	return []arv1.ValidatingWebhook{
 		{
@@ -475,18 +113,22 @@ func testMutatingWebhooks(serviceName, serviceNamespace string) []arv1.MutatingW
 }
 
 func TestEnsureWebhooks(t *testing.T) {
+	controller := &albo.AWSLoadBalancerController{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}}
+	webhookService := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "test-service", Namespace: "test-namespace"}}
+	serviceCACerts := &webhookCertificates{annotations: map[string]string{injectCABundleAnnotationKey: injectCABundleAnnotationValue}}
 	for _, tc := range []struct {
-		name            string
-		controller      *albo.AWSLoadBalancerController
-		webhookService  *corev1.Service
-		expectedVWC     *arv1.ValidatingWebhookConfiguration
-		expectedMWC     *arv1.MutatingWebhookConfiguration
-		existingObjects []client.Object
+		name string
+		// certs defaults to the certificates of the service CA
+		certs       *webhookCertificates
+		expectedVWC *arv1.ValidatingWebhookConfiguration
+		expectedMWC *arv1.MutatingWebhookConfiguration
+		// appliedObjects are the objects previously applied by the operator
+		appliedObjects []client.Object
+		// changedObjects are merged into the applied objects by other clients
+		changedObjects []client.Object
 	}{
 		{
-			name:           "no existing webhooks",
-			controller:     &albo.AWSLoadBalancerController{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}},
-			webhookService: &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "test-service", Namespace: "test-namespace"}},
+			name: "no existing webhooks",
 			expectedVWC: &arv1.ValidatingWebhookConfiguration{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:        "aws-load-balancer-controller-cluster",
@@ -503,22 +145,17 @@ func TestEnsureWebhooks(t *testing.T) {
 			},
 		},
 		{
-			name:       "existing validating webhook",
-			controller: &albo.AWSLoadBalancerController{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}},
-			existingObjects: []client.Object{
+			name: "existing validating webhook",
+			appliedObjects: []client.Object{
 				&arv1.ValidatingWebhookConfiguration{
 					ObjectMeta: metav1.ObjectMeta{
 						Name: "aws-load-balancer-controller-cluster",
-						OwnerReferences: []metav1.OwnerReference{
-							{Name: "cluster", Kind: "AWSLoadBalancerController"},
-						},
 					},
 					Webhooks: []arv1.ValidatingWebhook{
 						{Name: "ingress"},
 					},
 				},
 			},
-			webhookService: &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "test-service", Namespace: "test-namespace"}},
 			expectedVWC: &arv1.ValidatingWebhookConfiguration{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:        "aws-load-balancer-controller-cluster",
@@ -535,22 +172,17 @@ func TestEnsureWebhooks(t *testing.T) {
 			},
 		},
 		{
-			name:       "existing mutating webhook",
-			controller: &albo.AWSLoadBalancerController{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}},
-			existingObjects: []client.Object{
+			name: "existing mutating webhook",
+			appliedObjects: []client.Object{
 				&arv1.MutatingWebhookConfiguration{
 					ObjectMeta: metav1.ObjectMeta{
 						Name: "aws-load-balancer-controller-cluster",
-						OwnerReferences: []metav1.OwnerReference{
-							{Name: "cluster", Kind: "AWSLoadBalancerController"},
-						},
 					},
 					Webhooks: []arv1.MutatingWebhook{
 						{Name: "ingress"},
 					},
 				},
 			},
-			webhookService: &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "test-service", Namespace: "test-namespace"}},
 			expectedVWC: &arv1.ValidatingWebhookConfiguration{
 				ObjectMeta: metav1.ObjectMeta{
 					Name:        "aws-load-balancer-controller-cluster",
@@ -567,37 +199,58 @@ func TestEnsureWebhooks(t *testing.T) {
 			},
 		},
 		{
-			name:       "existing webhooks with third-party annotations",
-			controller: &albo.AWSLoadBalancerController{ObjectMeta: metav1.ObjectMeta{Name: "cluster"}},
-			existingObjects: []client.Object{
+			name: "drifted webhooks",
+			appliedObjects: []client.Object{
+				desiredValidatingWebhookConfiguration(controller, webhookService, serviceCACerts),
+				desiredMutatingWebhookConfiguration(controller, webhookService, serviceCACerts),
+			},
+			changedObjects: []client.Object{
+				&arv1.ValidatingWebhookConfiguration{
+					ObjectMeta: metav1.ObjectMeta{Name: "aws-load-balancer-controller-cluster"},
+					Webhooks:   withValidatingFailurePolicy(testValidatingWebhooks("test-service", "test-namespace"), arv1.Ignore),
+				},
 				&arv1.MutatingWebhookConfiguration{
-					ObjectMeta: metav1.ObjectMeta{
-						Name: "aws-load-balancer-controller-cluster",
-						Annotations: map[string]string{
-							"test-key": "test-value",
-						},
-						OwnerReferences: []metav1.OwnerReference{
-							{Name: "cluster", Kind: "AWSLoadBalancerController"},
-						},
-					},
-					Webhooks: []arv1.MutatingWebhook{
-						{Name: "ingress"},
-					},
+					ObjectMeta: metav1.ObjectMeta{Name: "aws-load-balancer-controller-cluster"},
+					Webhooks:   withMutatingFailurePolicy(testMutatingWebhooks("test-service", "test-namespace"), arv1.Ignore),
+				},
+			},
+			expectedVWC: &arv1.ValidatingWebhookConfiguration{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "aws-load-balancer-controller-cluster",
+					Annotations: map[string]string{injectCABundleAnnotationKey: injectCABundleAnnotationValue},
 				},
+				Webhooks: testValidatingWebhooks("test-service", "test-namespace"),
+			},
+			expectedMWC: &arv1.MutatingWebhookConfiguration{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "aws-load-balancer-controller-cluster",
+					Annotations: map[string]string{injectCABundleAnnotationKey: injectCABundleAnnotationValue},
+				},
+				Webhooks: testMutatingWebhooks("test-service", "test-namespace"),
+			},
+		},
+		{
+			name: "existing webhooks with third-party annotations and injected CA bundle",
+			appliedObjects: []client.Object{
+				desiredValidatingWebhookConfiguration(controller, webhookService, serviceCACerts),
+				desiredMutatingWebhookConfiguration(controller, webhookService, serviceCACerts),
+			},
+			changedObjects: []client.Object{
 				&arv1.ValidatingWebhookConfiguration{
 					ObjectMeta: metav1.ObjectMeta{
 						Name:        "aws-load-balancer-controller-cluster",
 						Annotations: map[string]string{"test-key": "test-value"},
-						OwnerReferences: []metav1.OwnerReference{
-							{Name: "cluster", Kind: "AWSLoadBalancerController"},
-						},
 					},
-					Webhooks: []arv1.ValidatingWebhook{
-						{Name: "ingress"},
+					Webhooks: withValidatingCABundle(testValidatingWebhooks("test-service", "test-namespace"), "test-ca"),
+				},
+				&arv1.MutatingWebhookConfiguration{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        "aws-load-balancer-controller-cluster",
+						Annotations: map[string]string{"test-key": "test-value"},
 					},
+					Webhooks: withMutatingCABundle(testMutatingWebhooks("test-service", "test-namespace"), "test-ca"),
 				},
 			},
-			webhookService: &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "test-service", Namespace: "test-namespace"}},
 			expectedVWC: &arv1.ValidatingWebhookConfiguration{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: "aws-load-balancer-controller-cluster",
@@ -606,7 +259,7 @@ func TestEnsureWebhooks(t *testing.T) {
 						"test-key":                  "test-value",
 					},
 				},
-				Webhooks: testValidatingWebhooks("test-service", "test-namespace"),
+				Webhooks: withValidatingCABundle(testValidatingWebhooks("test-service", "test-namespace"), "test-ca"),
 			},
 			expectedMWC: &arv1.MutatingWebhookConfiguration{
 				ObjectMeta: metav1.ObjectMeta{
@@ -616,18 +269,65 @@ func TestEnsureWebhooks(t *testing.T) {
 						"test-key":                  "test-value",
 					},
 				},
+				Webhooks: withMutatingCABundle(testMutatingWebhooks("test-service", "test-namespace"), "test-ca"),
+			},
+		},
+		{
+			name:  "CA bundle issued by the operator",
+			certs: &webhookCertificates{caBundle: []byte("test-ca")},
+			expectedVWC: &arv1.ValidatingWebhookConfiguration{
+				ObjectMeta: metav1.ObjectMeta{Name: "aws-load-balancer-controller-cluster"},
+				Webhooks:   withValidatingCABundle(testValidatingWebhooks("test-service", "test-namespace"), "test-ca"),
+			},
+			expectedMWC: &arv1.MutatingWebhookConfiguration{
+				ObjectMeta: metav1.ObjectMeta{Name: "aws-load-balancer-controller-cluster"},
+				Webhooks:   withMutatingCABundle(testMutatingWebhooks("test-service", "test-namespace"), "test-ca"),
+			},
+		},
+		{
+			name: "provider changed from the operator to the service CA",
+			appliedObjects: []client.Object{
+				desiredValidatingWebhookConfiguration(controller, webhookService, &webhookCertificates{caBundle: []byte("test-ca")}),
+				desiredMutatingWebhookConfiguration(controller, webhookService, &webhookCertificates{caBundle: []byte("test-ca")}),
+			},
+			expectedVWC: &arv1.ValidatingWebhookConfiguration{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "aws-load-balancer-controller-cluster",
+					Annotations: map[string]string{injectCABundleAnnotationKey: injectCABundleAnnotationValue},
+				},
+				Webhooks: testValidatingWebhooks("test-service", "test-namespace"),
+			},
+			expectedMWC: &arv1.MutatingWebhookConfiguration{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "aws-load-balancer-controller-cluster",
+					Annotations: map[string]string{injectCABundleAnnotationKey: injectCABundleAnnotationValue},
+				},
 				Webhooks: testMutatingWebhooks("test-service", "test-namespace"),
 			},
 		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			ctx := context.Background()
-			testClient := fake.NewClientBuilder().WithObjects(tc.existingObjects...).WithScheme(test.Scheme).Build()
+			testClient := test.NewApplyClient(fake.NewClientBuilder().WithScheme(test.Scheme).Build())
 			r := &AWSLoadBalancerControllerReconciler{
 				Client: testClient,
 				Scheme: test.Scheme,
 			}
-			err := r.ensureWebhooks(ctx, tc.controller, tc.webhookService)
+			for _, obj := range tc.appliedObjects {
+				if err := r.apply(ctx, obj); err != nil {
+					t.Fatalf("failed to apply %s: %v", obj.GetName(), err)
+				}
+			}
+			for _, obj := range tc.changedObjects {
+				if err := testClient.Patch(ctx, obj, client.Merge); err != nil {
+					t.Fatalf("failed to change %s: %v", obj.GetName(), err)
+				}
+			}
+			certs := tc.certs
+			if certs == nil {
+				certs = serviceCACerts
+			}
+			err := r.ensureWebhooks(ctx, controller, webhookService, certs)
 			if err != nil {
 				t.Errorf("unexpected error: %v", err)
 				return
@@ -646,7 +346,7 @@ func TestEnsureWebhooks(t *testing.T) {
 				t.Errorf("mismatched annotations:\n%s", diff)
 			}
 
-			if !hasOwner(tc.controller, vwc.OwnerReferences) {
+			if !hasOwner(controller, vwc.OwnerReferences) {
 				t.Errorf("expected owner reference on controller")
 			}
 
@@ -658,7 +358,7 @@ func TestEnsureWebhooks(t *testing.T) {
 			if err != nil {
 				t.Errorf("failed to get expected mutating webhook configuration: %v", err)
 			}
-			if !hasOwner(tc.controller, mwc.OwnerReferences) {
+			if !hasOwner(controller, mwc.OwnerReferences) {
 				t.Errorf("expected owner reference to controller on mutating webhook configuration")
 			}
 
@@ -673,6 +373,34 @@ func TestEnsureWebhooks(t *testing.T) {
 	}
 }
 
+func withValidatingCABundle(webhooks []arv1.ValidatingWebhook, caBundle string) []arv1.ValidatingWebhook {
+	for i := range webhooks {
+		webhooks[i].ClientConfig.CABundle = []byte(caBundle)
+	}
+	return webhooks
+}
+
+func withMutatingCABundle(webhooks []arv1.MutatingWebhook, caBundle string) []arv1.MutatingWebhook {
+	for i := range webhooks {
+		webhooks[i].ClientConfig.CABundle = []byte(caBundle)
+	}
+	return webhooks
+}
+
+func withValidatingFailurePolicy(webhooks []arv1.ValidatingWebhook, policy arv1.FailurePolicyType) []arv1.ValidatingWebhook {
+	for i := range webhooks {
+		webhooks[i].FailurePolicy = failurePolicyPtr(policy)
+	}
+	return webhooks
+}
+
+func withMutatingFailurePolicy(webhooks []arv1.MutatingWebhook, policy arv1.FailurePolicyType) []arv1.MutatingWebhook {
+	for i := range webhooks {
+		webhooks[i].FailurePolicy = failurePolicyPtr(policy)
+	}
+	return webhooks
+}
+
 func hasOwner(controller *albo.AWSLoadBalancerController, references []metav1.OwnerReference) bool {
 	for _, o := range references {
 		if o.Name == controller.Name && o.Kind == "AWSLoadBalancerController" {
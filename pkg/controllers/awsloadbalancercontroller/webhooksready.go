@@ -0,0 +1,156 @@
+package awsloadbalancercontroller
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+
+	arv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	albo "github.com/openshift/aws-load-balancer-operator/api/v1alpha1"
+)
+
+// WebhooksReadyCondition reports whether the API server can call the webhooks of the operand
+const WebhooksReadyCondition = "WebhooksReady"
+
+// webhookClientConfig is the client configuration of a webhook of the operand.
+type webhookClientConfig struct {
+	name     string
+	caBundle []byte
+}
+
+// webhooksReadyCondition returns the WebhooksReady condition. The webhooks are ready when the CA bundle of every
+// webhook verifies the serving certificate of the serving secret and the service has ready endpoints. With the
+// dry-run check of the reconciler, the admission of an Ingress of the IngressClass of the controller is also
+// dry-run through the webhooks. The errors of the API server are returned, the failures of the checks are reported
+// in the condition.
+func (r *AWSLoadBalancerControllerReconciler) webhooksReadyCondition(ctx context.Context, controller *albo.AWSLoadBalancerController, service *corev1.Service, servingSecretName string, readyEndpoints int) (metav1.Condition, error) {
+	condition := metav1.Condition{
+		Type:               WebhooksReadyCondition,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: controller.Generation,
+	}
+
+	webhooks, err := r.currentWebhookClientConfigs(ctx, fmt.Sprintf("%s-%s", controllerResourcePrefix, controller.Name))
+	if err != nil {
+		return metav1.Condition{}, err
+	}
+	if len(webhooks) == 0 {
+		condition.Reason = "WebhooksNotFound"
+		condition.Message = "Webhook configurations of the controller don't exist"
+		return condition, nil
+	}
+	for _, webhook := range webhooks {
+		if len(webhook.caBundle) == 0 {
+			condition.Reason = "CABundleNotInjected"
+			condition.Message = fmt.Sprintf("Webhook %q has no CA bundle", webhook.name)
+			return condition, nil
+		}
+	}
+
+	var secret corev1.Secret
+	if err := r.Get(ctx, types.NamespacedName{Namespace: service.Namespace, Name: servingSecretName}, &secret); err != nil {
+		if !errors.IsNotFound(err) {
+			return metav1.Condition{}, fmt.Errorf("failed to get serving secret %s/%s: %w", service.Namespace, servingSecretName, err)
+		}
+		condition.Reason = "ServingCertificateNotIssued"
+		condition.Message = fmt.Sprintf("Serving secret %q of the webhooks doesn't exist", servingSecretName)
+		return condition, nil
+	}
+	for _, webhook := range webhooks {
+		if err := verifyServingCertificate(webhook.caBundle, secret.Data[corev1.TLSCertKey], serviceDNSNames(types.NamespacedName{Namespace: service.Namespace, Name: service.Name})[0]); err != nil {
+			condition.Reason = "CABundleMismatch"
+			condition.Message = fmt.Sprintf("CA bundle of webhook %q doesn't verify the serving certificate of secret %q: %v", webhook.name, servingSecretName, err)
+			return condition, nil
+		}
+	}
+
+	if readyEndpoints == 0 {
+		condition.Reason = "WebhookEndpointsNotReady"
+		condition.Message = fmt.Sprintf("Service %q of the webhooks has no ready endpoints", service.Name)
+		return condition, nil
+	}
+
+	if r.WebhookDryRun {
+		if err := r.Create(ctx, dryRunIngress(controller, service), client.DryRunAll); err != nil {
+			condition.Reason = "DryRunAdmissionFailed"
+			condition.Message = fmt.Sprintf("Dry-run admission of an Ingress of class %q failed: %v", controller.Spec.IngressClass, err)
+			return condition, nil
+		}
+	}
+
+	condition.Status = metav1.ConditionTrue
+	condition.Reason = "WebhooksReady"
+	condition.Message = fmt.Sprintf("Webhooks are served by service %q with a trusted certificate", service.Name)
+	return condition, nil
+}
+
+// currentWebhookClientConfigs returns the client configurations of the webhooks of the validating and mutating
+// webhook configurations with the given name. A missing configuration has no webhooks.
+func (r *AWSLoadBalancerControllerReconciler) currentWebhookClientConfigs(ctx context.Context, name string) ([]webhookClientConfig, error) {
+	var configs []webhookClientConfig
+
+	var vwc arv1.ValidatingWebhookConfiguration
+	if err := r.Get(ctx, types.NamespacedName{Name: name}, &vwc); err != nil && !errors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get ValidatingWebhookConfiguration %q: %w", name, err)
+	}
+	for _, webhook := range vwc.Webhooks {
+		configs = append(configs, webhookClientConfig{name: webhook.Name, caBundle: webhook.ClientConfig.CABundle})
+	}
+
+	var mwc arv1.MutatingWebhookConfiguration
+	if err := r.Get(ctx, types.NamespacedName{Name: name}, &mwc); err != nil && !errors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to get MutatingWebhookConfiguration %q: %w", name, err)
+	}
+	for _, webhook := range mwc.Webhooks {
+		configs = append(configs, webhookClientConfig{name: webhook.Name, caBundle: webhook.ClientConfig.CABundle})
+	}
+	return configs, nil
+}
+
+// verifyServingCertificate verifies the serving certificate for the given DNS name with the CAs of the CA bundle,
+// as the API server does when it calls the webhooks.
+func verifyServingCertificate(caBundle, certPEM []byte, dnsName string) error {
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(caBundle) {
+		return fmt.Errorf("no valid CA in the CA bundle")
+	}
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return fmt.Errorf("no serving certificate")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return err
+	}
+	_, err = cert.Verify(x509.VerifyOptions{DNSName: dnsName, Roots: roots})
+	return err
+}
+
+// dryRunIngress returns the Ingress whose admission is dry-run to check that the API server can call the webhooks.
+// The Ingress has the IngressClass of the controller so that it's validated by the webhook of the controller.
+func dryRunIngress(controller *albo.AWSLoadBalancerController, service *corev1.Service) *networkingv1.Ingress {
+	return &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: fmt.Sprintf("%s-%s-webhook-check-", controllerResourcePrefix, controller.Name),
+			Namespace:    service.Namespace,
+		},
+		Spec: networkingv1.IngressSpec{
+			IngressClassName: &controller.Spec.IngressClass,
+			DefaultBackend: &networkingv1.IngressBackend{
+				Service: &networkingv1.IngressServiceBackend{
+					Name: service.Name,
+					Port: networkingv1.ServiceBackendPort{Number: controllerWebhookPort},
+				},
+			},
+		},
+	}
+}
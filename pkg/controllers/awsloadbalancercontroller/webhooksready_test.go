@@ -0,0 +1,172 @@
+package awsloadbalancercontroller
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	albo "github.com/openshift/aws-load-balancer-operator/api/v1alpha1"
+	"github.com/openshift/aws-load-balancer-operator/pkg/controllers/utils/test"
+)
+
+// failingDryRunClient fails the dry-run creations as the API server does when it can't call a webhook.
+type failingDryRunClient struct {
+	client.Client
+}
+
+func (c *failingDryRunClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	createOpts := &client.CreateOptions{}
+	createOpts.ApplyOptions(opts)
+	if len(createOpts.DryRun) > 0 {
+		return fmt.Errorf(`Internal error occurred: failed calling webhook "vingress.elbv2.k8s.aws": x509: certificate signed by unknown authority`)
+	}
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func TestWebhooksReadyCondition(t *testing.T) {
+	name := controllerResourcePrefix + "-cluster"
+	secretName := servingSecretPrefix + "cluster"
+	controller := &albo.AWSLoadBalancerController{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster", Generation: 2},
+		Spec:       albo.AWSLoadBalancerControllerSpec{IngressClass: "alb"},
+	}
+	service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: test.OperatorNamespace}}
+
+	servingSecret := func(dnsNames []string) *corev1.Secret {
+		data, err := generateServingCertificate(dnsNames, time.Now(), nil)
+		if err != nil {
+			t.Fatalf("failed to generate serving certificate: %v", err)
+		}
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: test.OperatorNamespace},
+			Type:       corev1.SecretTypeTLS,
+			Data:       data,
+		}
+	}
+	dnsNames := serviceDNSNames(types.NamespacedName{Name: name, Namespace: test.OperatorNamespace})
+	secret := servingSecret(dnsNames)
+	otherSecret := servingSecret(dnsNames)
+	otherServiceSecret := servingSecret([]string{"other.test-namespace.svc"})
+	webhooks := func(caBundle []byte) []client.Object {
+		certs := &webhookCertificates{caBundle: caBundle}
+		return []client.Object{
+			desiredValidatingWebhookConfiguration(controller, service, certs),
+			desiredMutatingWebhookConfiguration(controller, service, certs),
+		}
+	}
+
+	for _, tc := range []struct {
+		name            string
+		existingObjects []client.Object
+		readyEndpoints  int
+		dryRun          bool
+		failingDryRun   bool
+		expectedStatus  metav1.ConditionStatus
+		expectedReason  string
+	}{
+		{
+			name:           "no webhook configurations",
+			readyEndpoints: 1,
+			expectedStatus: metav1.ConditionFalse,
+			expectedReason: "WebhooksNotFound",
+		},
+		{
+			name:            "CA bundle not injected",
+			existingObjects: append(webhooks(nil), secret),
+			readyEndpoints:  1,
+			expectedStatus:  metav1.ConditionFalse,
+			expectedReason:  "CABundleNotInjected",
+		},
+		{
+			name:            "serving certificate not issued",
+			existingObjects: webhooks(secret.Data[caBundleKey]),
+			readyEndpoints:  1,
+			expectedStatus:  metav1.ConditionFalse,
+			expectedReason:  "ServingCertificateNotIssued",
+		},
+		{
+			name:            "CA bundle of another CA",
+			existingObjects: append(webhooks(otherSecret.Data[caBundleKey]), secret),
+			readyEndpoints:  1,
+			expectedStatus:  metav1.ConditionFalse,
+			expectedReason:  "CABundleMismatch",
+		},
+		{
+			name:            "serving certificate of another service",
+			existingObjects: append(webhooks(otherServiceSecret.Data[caBundleKey]), otherServiceSecret),
+			readyEndpoints:  1,
+			expectedStatus:  metav1.ConditionFalse,
+			expectedReason:  "CABundleMismatch",
+		},
+		{
+			name:            "no ready endpoints",
+			existingObjects: append(webhooks(secret.Data[caBundleKey]), secret),
+			expectedStatus:  metav1.ConditionFalse,
+			expectedReason:  "WebhookEndpointsNotReady",
+		},
+		{
+			name:            "webhooks ready",
+			existingObjects: append(webhooks(secret.Data[caBundleKey]), secret),
+			readyEndpoints:  2,
+			expectedStatus:  metav1.ConditionTrue,
+			expectedReason:  "WebhooksReady",
+		},
+		{
+			name:            "dry-run admission succeeded",
+			existingObjects: append(webhooks(secret.Data[caBundleKey]), secret),
+			readyEndpoints:  1,
+			dryRun:          true,
+			expectedStatus:  metav1.ConditionTrue,
+			expectedReason:  "WebhooksReady",
+		},
+		{
+			name:            "dry-run admission failed",
+			existingObjects: append(webhooks(secret.Data[caBundleKey]), secret),
+			readyEndpoints:  1,
+			dryRun:          true,
+			failingDryRun:   true,
+			expectedStatus:  metav1.ConditionFalse,
+			expectedReason:  "DryRunAdmissionFailed",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var testClient client.Client = fake.NewClientBuilder().WithScheme(test.Scheme).WithObjects(tc.existingObjects...).Build()
+			if tc.failingDryRun {
+				testClient = &failingDryRunClient{Client: testClient}
+			}
+			r := &AWSLoadBalancerControllerReconciler{
+				Client:        testClient,
+				Scheme:        test.Scheme,
+				WebhookDryRun: tc.dryRun,
+			}
+			condition, err := r.webhooksReadyCondition(context.Background(), controller, service, secretName, tc.readyEndpoints)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if condition.Type != WebhooksReadyCondition || condition.Status != tc.expectedStatus || condition.Reason != tc.expectedReason {
+				t.Errorf("expected %s condition %s with reason %q, got %s with reason %q: %s", WebhooksReadyCondition, tc.expectedStatus, tc.expectedReason, condition.Status, condition.Reason, condition.Message)
+			}
+			if condition.ObservedGeneration != controller.Generation {
+				t.Errorf("expected observed generation %d, got %d", controller.Generation, condition.ObservedGeneration)
+			}
+
+			// the dry-run admission doesn't create the Ingress
+			var ingresses networkingv1.IngressList
+			if err := testClient.List(context.Background(), &ingresses); err != nil {
+				t.Fatalf("failed to list ingresses: %v", err)
+			}
+			if len(ingresses.Items) != 0 {
+				t.Errorf("unexpected ingresses %v", ingresses.Items)
+			}
+		})
+	}
+}
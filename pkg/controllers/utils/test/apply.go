@@ -0,0 +1,158 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/jsonmergepatch"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ApplyClient emulates the server-side apply patches which the fake client doesn't support. An apply patch is
+// emulated like a client-side apply: the fields of the previous apply of the field manager which are no longer in
+// the applied object are removed, the fields of the applied object are set and the other fields are left unchanged.
+// The built-in types are patched with strategic merge patches, the other types with JSON merge patches.
+type ApplyClient struct {
+	client.WithWatch
+
+	lock sync.Mutex
+	// applied are the last applied objects per field manager and object
+	applied map[string][]byte
+	// Applies are the numbers of apply patches made per field manager
+	Applies map[string]int
+}
+
+// NewApplyClient returns a client which emulates the apply patches made with the given client.
+func NewApplyClient(c client.WithWatch) *ApplyClient {
+	return &ApplyClient{
+		WithWatch: c,
+		applied:   make(map[string][]byte),
+		Applies:   make(map[string]int),
+	}
+}
+
+// Patch patches the object, the apply patches are emulated.
+func (c *ApplyClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if patch.Type() != types.ApplyPatchType {
+		return c.WithWatch.Patch(ctx, obj, patch, opts...)
+	}
+
+	patchOptions := &client.PatchOptions{}
+	patchOptions.ApplyOptions(opts)
+	if patchOptions.FieldManager == "" {
+		return fmt.Errorf("field manager is required for apply patches")
+	}
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	if gvk.Kind == "" {
+		return fmt.Errorf("apiVersion and kind are required for apply patches")
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	modified, err := patch.Data(obj)
+	if err != nil {
+		return err
+	}
+	key := fmt.Sprintf("%s/%s/%s/%s", patchOptions.FieldManager, gvk, obj.GetNamespace(), obj.GetName())
+
+	current := obj.DeepCopyObject().(client.Object)
+	if err := c.Get(ctx, client.ObjectKeyFromObject(obj), current); err != nil {
+		if !errors.IsNotFound(err) {
+			return err
+		}
+		if err := c.Create(ctx, obj); err != nil {
+			return err
+		}
+		c.applied[key] = modified
+		c.Applies[patchOptions.FieldManager]++
+		return nil
+	}
+	currentJSON, err := json.Marshal(current)
+	if err != nil {
+		return err
+	}
+
+	var patchType types.PatchType
+	var data []byte
+	if clientgoscheme.Scheme.Recognizes(gvk) {
+		patchMeta, err := strategicpatch.NewPatchMetaFromStruct(obj)
+		if err != nil {
+			return err
+		}
+		patchType = types.StrategicMergePatchType
+		data, err = strategicpatch.CreateThreeWayMergePatch(c.applied[key], modified, currentJSON, patchMeta, true)
+		if err != nil {
+			return err
+		}
+	} else {
+		patchType = types.MergePatchType
+		data, err = jsonmergepatch.CreateThreeWayJSONMergePatch(c.applied[key], modified, currentJSON)
+		if err != nil {
+			return err
+		}
+	}
+	c.applied[key] = modified
+	c.Applies[patchOptions.FieldManager]++
+
+	// the API server doesn't write the objects which are not changed by an apply
+	changed, err := patchChanges(currentJSON, data, patchType, obj)
+	if err != nil {
+		return err
+	}
+	if !changed {
+		reflect.ValueOf(obj).Elem().Set(reflect.ValueOf(current).Elem())
+		return nil
+	}
+	return c.WithWatch.Patch(ctx, obj, client.RawPatch(patchType, data))
+}
+
+// patchChanges returns true if the patch changes the object.
+func patchChanges(original, patch []byte, patchType types.PatchType, obj client.Object) (bool, error) {
+	var patched []byte
+	var err error
+	if patchType == types.StrategicMergePatchType {
+		patched, err = strategicpatch.StrategicMergePatch(original, patch, obj)
+	} else {
+		patched, err = jsonpatch.MergePatch(original, patch)
+	}
+	if err != nil {
+		return false, err
+	}
+	// the objects are compared once decoded, the null fields removed by the patch are not changes
+	originalObj := reflect.New(reflect.TypeOf(obj).Elem()).Interface()
+	patchedObj := reflect.New(reflect.TypeOf(obj).Elem()).Interface()
+	if err := json.Unmarshal(original, originalObj); err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(patched, patchedObj); err != nil {
+		return false, err
+	}
+	return !equality.Semantic.DeepEqual(originalObj, patchedObj), nil
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2022.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package test
+
+import (
+	"context"
+	"sync/atomic"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// CountingClient counts the requests made with the client, they are the requests which reach the API server
+// when the client reads without a cache.
+type CountingClient struct {
+	client.Client
+
+	requests int64
+}
+
+// NewCountingClient returns a client which counts the requests made with the given client.
+func NewCountingClient(c client.Client) *CountingClient {
+	return &CountingClient{Client: c}
+}
+
+// Requests returns the number of requests made with the client.
+func (c *CountingClient) Requests() int64 {
+	return atomic.LoadInt64(&c.requests)
+}
+
+func (c *CountingClient) count() {
+	atomic.AddInt64(&c.requests, 1)
+}
+
+func (c *CountingClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	c.count()
+	return c.Client.Get(ctx, key, obj, opts...)
+}
+
+func (c *CountingClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	c.count()
+	return c.Client.List(ctx, list, opts...)
+}
+
+func (c *CountingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	c.count()
+	return c.Client.Create(ctx, obj, opts...)
+}
+
+func (c *CountingClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	c.count()
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func (c *CountingClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	c.count()
+	return c.Client.Patch(ctx, obj, patch, opts...)
+}
+
+func (c *CountingClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	c.count()
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+func (c *CountingClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	c.count()
+	return c.Client.DeleteAllOf(ctx, obj, opts...)
+}
+
+func (c *CountingClient) Status() client.StatusWriter {
+	return &countingStatusWriter{StatusWriter: c.Client.Status(), client: c}
+}
+
+// countingStatusWriter counts the status updates in the requests of the client.
+type countingStatusWriter struct {
+	client.StatusWriter
+	client *CountingClient
+}
+
+func (w *countingStatusWriter) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	w.client.count()
+	return w.StatusWriter.Update(ctx, obj, opts...)
+}
+
+func (w *countingStatusWriter) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	w.client.count()
+	return w.StatusWriter.Patch(ctx, obj, patch, opts...)
+}
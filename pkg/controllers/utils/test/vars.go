@@ -23,14 +23,16 @@ import (
 
 	configv1 "github.com/openshift/api/config/v1"
 	cco "github.com/openshift/cloud-credential-operator/pkg/apis/cloudcredential/v1"
+	monitoringv1 "github.com/prometheus-operator/prometheus-operator/pkg/apis/monitoring/v1"
 	rbacv1 "k8s.io/api/rbac/v1"
 
 	albo "github.com/openshift/aws-load-balancer-operator/api/v1alpha1"
 )
 
 const (
-	OperandImage      = "quay.io/test/aws-load-balancer:latest"
-	OperatorNamespace = "aws-load-balancer-operator"
+	OperandImage       = "quay.io/test/aws-load-balancer:latest"
+	KubeRBACProxyImage = "quay.io/test/kube-rbac-proxy:latest"
+	OperatorNamespace  = "aws-load-balancer-operator"
 )
 
 var (
@@ -47,4 +49,5 @@ func init() {
 	utilruntime.Must(configv1.Install(Scheme))
 	utilruntime.Must(cco.Install(Scheme))
 	utilruntime.Must(rbacv1.AddToScheme(Scheme))
+	utilruntime.Must(monitoringv1.AddToScheme(Scheme))
 }